@@ -0,0 +1,299 @@
+package browser
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/moond4rk/hackbrowserdata/log"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+// archiveFormat is the format of a profile archive detected from its magic bytes
+type archiveFormat int
+
+const (
+	archiveNone archiveFormat = iota
+	archiveZip
+	archiveTar
+	archiveTarGz
+)
+
+// maxArchiveBytes caps the bytes extracted from a profile archive, an archive can decompress to
+// far more than its own size
+var maxArchiveBytes int64 = 4 << 30
+
+// archiveSidecars are the suffixes of the files kept next to the item files, eg: Cookies-wal
+var archiveSidecars = []string{"-wal", "-shm", "-journal", types.BackupSuffix}
+
+// detectArchive returns the format of the archive file, archiveNone for a folder or other files
+func detectArchive(p string) archiveFormat {
+	f, err := os.Open(p)
+	if err != nil {
+		return archiveNone
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err != nil || !info.Mode().IsRegular() {
+		return archiveNone
+	}
+	header := make([]byte, 512)
+	n, _ := io.ReadFull(f, header)
+	header = header[:n]
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")):
+		return archiveZip
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return archiveTarGz
+	case len(header) >= 262 && string(header[257:262]) == "ustar":
+		return archiveTar
+	}
+	return archiveNone
+}
+
+// extractArchive extracts the browser files of a zip, tar or tar.gz archive of a profile to
+// a folder of the temp dir, the other files of the archive are skipped. The folder is removed
+// with the temp dir of the run.
+func extractArchive(archive string) (string, error) {
+	format := detectArchive(archive)
+	if format == archiveNone {
+		return "", fmt.Errorf("%s is not a zip or tar archive", archive)
+	}
+	dir, err := os.MkdirTemp(types.TempDir(), "archive-")
+	if err != nil {
+		return "", err
+	}
+	root := filepath.Join(dir, "profile")
+	remaining := maxArchiveBytes
+	if format == archiveZip {
+		err = extractZip(archive, root, &remaining)
+	} else {
+		err = extractTar(archive, root, format == archiveTarGz, &remaining)
+	}
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return "", fmt.Errorf("extract %s error: %w", archive, err)
+	}
+	log.Debugf("extract archive %s to %s", archive, root)
+	return root, nil
+}
+
+func extractZip(archive, root string, remaining *int64) error {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() || !isArchiveItem(f.Name) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = writeArchiveFile(root, f.Name, rc, remaining)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractTar(archive, root string, gzipped bool, remaining *int64) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var r io.Reader = bufio.NewReader(f)
+	if gzipped {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if h.Typeflag != tar.TypeReg || !isArchiveItem(h.Name) {
+			continue
+		}
+		if err := writeArchiveFile(root, h.Name, tr, remaining); err != nil {
+			return err
+		}
+	}
+}
+
+// writeArchiveFile writes the entry of the archive under root, the entries out of root are rejected.
+// remaining is the bytes left to extract from the archive, the entry fails once it's exceeded.
+func writeArchiveFile(root, name string, r io.Reader, remaining *int64) error {
+	name = path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return fmt.Errorf("invalid path %s in archive", name)
+	}
+	p := filepath.Join(root, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, io.LimitReader(r, *remaining+1))
+	*remaining -= n
+	if err == nil && *remaining < 0 {
+		err = fmt.Errorf("archive extracts to more than %d bytes", maxArchiveBytes)
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// archiveItemNames are the names of the item files and folders of every browser, the
+// nested ones are matched as the path in the entry, eg: Local Storage/leveldb
+var archiveItemNames, archiveItemPaths = func() (map[string]bool, []string) {
+	names := map[string]bool{"profiles.ini": true}
+	var paths []string
+	add := func(name string) {
+		if strings.Contains(name, "/") {
+			paths = append(paths, name)
+			return
+		}
+		names[name] = true
+	}
+	for _, list := range [][]types.DataType{types.DefaultChromiumTypes, types.DefaultYandexTypes, types.DefaultBraveTypes, types.DefaultFirefoxTypes} {
+		for _, item := range list {
+			for _, name := range item.ProfileFiles() {
+				if name != types.UnsupportedItem {
+					add(name)
+				}
+			}
+		}
+	}
+	return names, paths
+}()
+
+// isArchiveItem reports whether the entry is an item file, its sidecar or a file in an item folder
+func isArchiveItem(name string) bool {
+	name = strings.ReplaceAll(name, "\\", "/")
+	for _, p := range archiveItemPaths {
+		if strings.Contains("/"+name+"/", "/"+p+"/") {
+			return true
+		}
+	}
+	parts := strings.Split(name, "/")
+	for i, part := range parts {
+		if archiveItemNames[part] {
+			return true
+		}
+		if i != len(parts)-1 {
+			continue
+		}
+		for _, suffix := range archiveSidecars {
+			if strings.HasSuffix(part, suffix) && archiveItemNames[strings.TrimSuffix(part, suffix)] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// archiveChromiumProfile returns the shallowest profile folder of the extracted archive, the
+// profiles next to it are found from its parent like a profile of the user data folder.
+func archiveChromiumProfile(root string) string {
+	var dirs []string
+	_ = filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || d.Name() == types.ChromiumKey.Filename() {
+			return nil
+		}
+		if archiveItemNames[d.Name()] {
+			dir := filepath.Dir(p)
+			// the moved items are in a sub folder of the profile, eg: Network/Cookies
+			if filepath.Base(dir) == "Network" {
+				dir = filepath.Dir(dir)
+			}
+			dirs = append(dirs, dir)
+		}
+		return nil
+	})
+	if len(dirs) == 0 {
+		return root
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if di, dj := pathDepth(dirs[i]), pathDepth(dirs[j]); di != dj {
+			return di < dj
+		}
+		return dirs[i] < dirs[j]
+	})
+	return dirs[0]
+}
+
+// archiveFirefoxProfile returns the folder of the profiles.ini of the extracted archive, else
+// the shallowest folder holding a firefox item, it's read as a portable profile.
+func archiveFirefoxProfile(root string) string {
+	var ini string
+	var dirs []string
+	_ = filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if d.Name() == "profiles.ini" && (ini == "" || pathDepth(p) < pathDepth(ini)) {
+			ini = p
+		}
+		if isFirefoxItem(d.Name()) {
+			dirs = append(dirs, filepath.Dir(p))
+		}
+		return nil
+	})
+	if ini != "" {
+		return filepath.Dir(ini)
+	}
+	if len(dirs) == 0 {
+		return root
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if di, dj := pathDepth(dirs[i]), pathDepth(dirs[j]); di != dj {
+			return di < dj
+		}
+		return dirs[i] < dirs[j]
+	})
+	return dirs[0]
+}
+
+// isFirefoxItem reports whether name is the file of a firefox item
+func isFirefoxItem(name string) bool {
+	for _, item := range types.DefaultFirefoxTypes {
+		for _, file := range item.ProfileFiles() {
+			if file == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pathDepth returns the number of folders of the path
+func pathDepth(p string) int {
+	return strings.Count(p, string(filepath.Separator))
+}
@@ -0,0 +1,161 @@
+package browser
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+var archiveEntries = []string{
+	"User Data/Local State",
+	"User Data/Default/Login Data",
+	"User Data/Default/Network/Cookies",
+	"User Data/Default/Network/Cookies-journal",
+	"User Data/Default/Local Storage/leveldb/000003.log",
+	"User Data/Default/Cache/Cache_Data/data_0",
+	"User Data/Profile 1/History",
+}
+
+func TestExtractArchive_Zip(t *testing.T) {
+	require.NoError(t, types.SetTempDir(t.TempDir()))
+	defer func() { _ = types.SetTempDir("") }()
+	archive := filepath.Join(t.TempDir(), "profile.zip")
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	zw := zip.NewWriter(f)
+	for _, name := range archiveEntries {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(name))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, archiveZip, detectArchive(archive))
+	root, err := extractArchive(archive)
+	require.NoError(t, err)
+	assertArchiveItems(t, root)
+	assert.Equal(t, filepath.Join(root, "User Data", "Default"), archiveChromiumProfile(root))
+}
+
+func TestExtractArchive_TarGz(t *testing.T) {
+	require.NoError(t, types.SetTempDir(t.TempDir()))
+	defer func() { _ = types.SetTempDir("") }()
+	archive := filepath.Join(t.TempDir(), "profile.tar.gz")
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, name := range archiveEntries {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(name)), Typeflag: tar.TypeReg}))
+		_, err = tw.Write([]byte(name))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, archiveTarGz, detectArchive(archive))
+	root, err := extractArchive(archive)
+	require.NoError(t, err)
+	assertArchiveItems(t, root)
+}
+
+func assertArchiveItems(t *testing.T, root string) {
+	t.Helper()
+	for _, name := range archiveEntries[:5] {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		require.NoError(t, err)
+		assert.Equal(t, name, string(data))
+	}
+	assert.NoDirExists(t, filepath.Join(root, "User Data", "Default", "Cache"))
+	assert.FileExists(t, filepath.Join(root, "User Data", "Profile 1", "History"))
+}
+
+func TestExtractArchive_InvalidPath(t *testing.T) {
+	require.NoError(t, types.SetTempDir(t.TempDir()))
+	defer func() { _ = types.SetTempDir("") }()
+	archive := filepath.Join(t.TempDir(), "evil.zip")
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	zw := zip.NewWriter(f)
+	_, err = zw.Create("../../Login Data")
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+
+	_, err = extractArchive(archive)
+	assert.ErrorContains(t, err, "invalid path")
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(types.TempDir()), "Login Data"))
+}
+
+func TestDetectArchive(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, "Login Data")
+	assert.Equal(t, archiveNone, detectArchive(dir))
+	assert.Equal(t, archiveNone, detectArchive(filepath.Join(dir, "Login Data")))
+	assert.Equal(t, archiveNone, detectArchive(filepath.Join(dir, "missing.zip")))
+}
+
+func writeZip(t *testing.T, archive string, names ...string) {
+	t.Helper()
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	zw := zip.NewWriter(f)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(name))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+}
+
+func TestExtractArchive_TooLarge(t *testing.T) {
+	require.NoError(t, types.SetTempDir(t.TempDir()))
+	defer func() { _ = types.SetTempDir("") }()
+	defer func(n int64) { maxArchiveBytes = n }(maxArchiveBytes)
+	maxArchiveBytes = 40
+	archive := filepath.Join(t.TempDir(), "profile.zip")
+	writeZip(t, archive, archiveEntries...)
+
+	_, err := extractArchive(archive)
+	assert.ErrorContains(t, err, "more than 40 bytes")
+}
+
+func TestArchiveFirefoxProfile(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, "Mozilla/Firefox/profiles.ini", "Mozilla/Firefox/Profiles/abcd.default/cookies.sqlite")
+	assert.Equal(t, filepath.Join(root, "Mozilla", "Firefox"), archiveFirefoxProfile(root))
+
+	root = t.TempDir()
+	writeFiles(t, root, "backup/abcd.default/key4.db", "backup/abcd.default/cookies.sqlite")
+	assert.Equal(t, filepath.Join(root, "backup", "abcd.default"), archiveFirefoxProfile(root), "the profile folder is used without profiles.ini")
+}
+
+func TestPickBrowsers_ArchiveAll(t *testing.T) {
+	require.NoError(t, types.SetTempDir(t.TempDir()))
+	defer func() { _ = types.SetTempDir("") }()
+	archive := filepath.Join(t.TempDir(), "profiles.zip")
+	writeZip(t, archive, "User Data/Default/Login Data", "firefox/abcd.default/cookies.sqlite")
+
+	browsers, err := PickBrowsers("all", archive)
+	require.NoError(t, err)
+	var names []string
+	for _, b := range browsers {
+		name, _ := b.Profile()
+		names = append(names, strings.ToLower(name))
+	}
+	assert.ElementsMatch(t, []string{"chrome", "firefox"}, names)
+}
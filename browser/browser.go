@@ -1,6 +1,8 @@
 package browser
 
 import (
+	"errors"
+	"fmt"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -9,6 +11,7 @@ import (
 	"github.com/moond4rk/hackbrowserdata/browser/firefox"
 	"github.com/moond4rk/hackbrowserdata/browserdata"
 	"github.com/moond4rk/hackbrowserdata/log"
+	"github.com/moond4rk/hackbrowserdata/types"
 	"github.com/moond4rk/hackbrowserdata/utils/fileutil"
 	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
 )
@@ -16,99 +19,180 @@ import (
 type Browser interface {
 	// Name is browser's name
 	Name() string
+	// Profile returns the browser and the profile folder name, eg: Chrome, Profile 1
+	Profile() (browser, profile string)
+	// ItemPaths returns the browser files of the items
+	ItemPaths() map[types.DataType]string
+	// ProfilePath returns the profile folder, eg: User Data/Profile 1
+	ProfilePath() string
 	// BrowsingData returns all browsing data in the browser.
 	BrowsingData(isFullExport bool) (*browserdata.BrowserData, error)
 }
 
-// PickBrowsers returns a list of browsers that match the name and profile.
+// archiveChromium and archiveFirefox are the browsers an archive of -b all is read as
+const (
+	archiveChromium = "chrome"
+	archiveFirefox  = "firefox"
+)
+
+// ErrNotFound is returned by PickBrowsers when no browser, no profile of the profile glob or
+// no usable profile archive is found
+var ErrNotFound = errors.New("no browser found")
+
+// PickBrowsers returns a list of browsers that match the name and profile, the profile can be
+// a zip, tar or tar.gz archive of the profile folder, its browser files are extracted first.
+// The profiles are filtered by the profile glob if any, see SetProfileGlob.
 func PickBrowsers(name, profile string) ([]Browser, error) {
 	var browsers []Browser
-	clist := pickChromium(name, profile)
+	chromiumName, firefoxName := name, name
+	chromiumProfile, firefoxProfile := profile, profile
+	if profile != "" && detectArchive(profile) != archiveNone {
+		root, err := extractArchive(profile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrNotFound, err)
+		}
+		chromiumProfile, firefoxProfile = archiveChromiumProfile(root), archiveFirefoxProfile(root)
+		if strings.EqualFold(name, "all") {
+			// the browser of the archive is unknown, its files are read once per engine
+			chromiumName, firefoxName = archiveChromium, archiveFirefox
+		}
+	}
+	clist := pickChromium(chromiumName, chromiumProfile)
 	for _, b := range clist {
 		if b != nil {
 			browsers = append(browsers, b)
 		}
 	}
-	flist := pickFirefox(name, profile)
+	flist := pickFirefox(firefoxName, firefoxProfile)
 	for _, b := range flist {
 		if b != nil {
 			browsers = append(browsers, b)
 		}
 	}
-	return browsers, nil
+	if len(browsers) == 0 {
+		return nil, fmt.Errorf("%w, browser %s", ErrNotFound, name)
+	}
+	return matchProfiles(browsers)
 }
 
 func pickChromium(name, profile string) []Browser {
 	var browsers []Browser
 	name = strings.ToLower(name)
 	if name == "all" {
-		for _, v := range chromiumList {
-			if !fileutil.IsDirExists(filepath.Clean(v.profilePath)) {
+		keys := typeutil.Keys(chromiumList)
+		sort.Strings(keys)
+		for _, key := range keys {
+			v := chromiumList[key]
+			profilePath := resolveProfilePath(v)
+			if !fileutil.IsDirExists(filepath.Clean(profilePath)) {
 				log.Warnf("find browser failed, profile folder does not exist, browser %s", v.name)
 				continue
 			}
-			multiChromium, err := chromium.New(v.name, v.storage, v.profilePath, v.dataTypes)
-			if err != nil {
-				log.Errorf("new chromium error %v", err)
-				continue
-			}
-			for _, b := range multiChromium {
-				log.Warnf("find browser success, browser %s", b.Name())
-				browsers = append(browsers, b)
-			}
+			browsers = append(browsers, newChromium(v, profilePath)...)
 		}
 	}
 	if c, ok := chromiumList[name]; ok {
 		if profile == "" {
-			profile = c.profilePath
+			profile = resolveProfilePath(c)
 		}
 		if !fileutil.IsDirExists(filepath.Clean(profile)) {
 			log.Errorf("find browser failed, profile folder does not exist, browser %s", c.name)
 		}
-		chromes, err := chromium.New(c.name, c.storage, profile, c.dataTypes)
-		if err != nil {
-			log.Errorf("new chromium error %v", err)
-		}
-		for _, chrome := range chromes {
-			log.Warnf("find browser success, browser %s", chrome.Name())
-			browsers = append(browsers, chrome)
-		}
+		browsers = append(browsers, newChromium(c, profile)...)
 	}
 	return browsers
 }
 
-func pickFirefox(name, profile string) []Browser {
-	var browsers []Browser
-	name = strings.ToLower(name)
-	if name == "all" || name == "firefox" {
-		for _, v := range firefoxList {
-			if profile == "" {
-				profile = v.profilePath
+func newChromium(c browserInfo, profile string) []Browser {
+	chromes, err := chromium.New(c.name, c.storage, profile, c.dataTypes)
+	if err != nil {
+		log.Errorf("new chromium error %v", err)
+		return nil
+	}
+	browsers := make([]Browser, 0, len(chromes))
+	for _, chrome := range chromes {
+		if c.localState != "" {
+			if p := localStatePath(profile, c.localState); fileutil.IsFileExists(p) {
+				chrome.Paths[types.ChromiumKey] = p
 			} else {
-				profile = fileutil.ParentDir(profile)
+				log.Warnf("local state %s of browser %s does not exist", p, c.name)
 			}
+		}
+		browsers = append(browsers, chrome)
+	}
+	return foundBrowsers(c, profile, browsers)
+}
 
-			if !fileutil.IsDirExists(filepath.Clean(profile)) {
+// -b firefox picks every firefox fork of the registry as it did before the forks were added,
+// eg: librewolf, a profile path is read as firefox.
+const firefoxFamily = "firefox"
+
+func pickFirefox(name, profile string) []Browser {
+	var browsers []Browser
+	name = strings.ToLower(name)
+	if name == "all" || name == firefoxFamily && profile == "" {
+		keys := typeutil.Keys(firefoxList)
+		sort.Strings(keys)
+		for _, key := range keys {
+			v := firefoxList[key]
+			profilePath := resolveProfilePath(v)
+			if !fileutil.IsDirExists(filepath.Clean(profilePath)) {
 				log.Warnf("find browser failed, profile folder does not exist, browser %s", v.name)
 				continue
 			}
-
-			if multiFirefox, err := firefox.New(profile, v.dataTypes); err == nil {
-				for _, b := range multiFirefox {
-					log.Warnf("find browser success, browser %s", b.Name())
-					browsers = append(browsers, b)
-				}
-			} else {
-				log.Errorf("new firefox error %v", err)
-			}
+			browsers = append(browsers, newFirefox(v, profilePath)...)
 		}
-
 		return browsers
 	}
-
+	if f, ok := firefoxList[name]; ok {
+		if profile == "" {
+			profile = resolveProfilePath(f)
+		}
+		if !fileutil.IsDirExists(filepath.Clean(profile)) {
+			log.Errorf("find browser failed, profile folder does not exist, browser %s", f.name)
+			return nil
+		}
+		return newFirefox(f, profile)
+	}
 	return nil
 }
 
+func newFirefox(f browserInfo, profile string) []Browser {
+	multiFirefox, err := firefox.New(f.name, profile, f.dataTypes)
+	if err != nil {
+		log.Errorf("new firefox error %v", err)
+		return nil
+	}
+	browsers := make([]Browser, 0, len(multiFirefox))
+	for _, b := range multiFirefox {
+		browsers = append(browsers, b)
+	}
+	return foundBrowsers(f, profile, browsers)
+}
+
+// foundBrowsers returns the profiles of the browser, only the one of the profile path if the
+// browser has a single profile.
+func foundBrowsers(info browserInfo, profile string, browsers []Browser) []Browser {
+	found := browsers[:0]
+	for _, b := range browsers {
+		if info.singleProfile && filepath.Clean(b.ProfilePath()) != filepath.Clean(profile) {
+			continue
+		}
+		log.Warnf("find browser success, browser %s", b.Name())
+		found = append(found, b)
+	}
+	return found
+}
+
+// localStatePath returns the Local State of the profile path, localState is relative to it
+// unless it's absolute.
+func localStatePath(profile, localState string) string {
+	if filepath.IsAbs(localState) {
+		return localState
+	}
+	return filepath.Join(profile, localState)
+}
+
 func ListBrowsers() []string {
 	var l []string
 	l = append(l, typeutil.Keys(chromiumList)...)
@@ -117,6 +201,23 @@ func ListBrowsers() []string {
 	return l
 }
 
+// ItemBrowsers returns the browsers of this system which have the item, in ascending order
+func ItemBrowsers(item types.DataType) []string {
+	var l []string
+	for _, list := range []map[string]browserInfo{chromiumList, firefoxList} {
+		for key, b := range list {
+			for _, dt := range b.dataTypes {
+				if dt == item {
+					l = append(l, key)
+					break
+				}
+			}
+		}
+	}
+	sort.Strings(l)
+	return l
+}
+
 func Names() string {
 	return strings.Join(ListBrowsers(), "|")
 }
@@ -7,12 +7,7 @@ import (
 )
 
 var (
-	chromiumList = map[string]struct {
-		name        string
-		storage     string
-		profilePath string
-		dataTypes   []types.DataType
-	}{
+	chromiumList = map[string]browserInfo{
 		"chrome": {
 			name:        chromeName,
 			storage:     chromeStorageName,
@@ -65,7 +60,7 @@ var (
 			name:        braveName,
 			profilePath: braveProfilePath,
 			storage:     braveStorageName,
-			dataTypes:   types.DefaultChromiumTypes,
+			dataTypes:   types.DefaultBraveTypes,
 		},
 		"yandex": {
 			name:        yandexName,
@@ -80,12 +75,7 @@ var (
 			dataTypes:   types.DefaultChromiumTypes,
 		},
 	}
-	firefoxList = map[string]struct {
-		name        string
-		storage     string
-		profilePath string
-		dataTypes   []types.DataType
-	}{
+	firefoxList = map[string]browserInfo{
 		"firefox": {
 			name:        firefoxName,
 			profilePath: firefoxProfilePath,
@@ -122,3 +112,6 @@ const (
 	yandexStorageName     = "Yandex"
 	arcStorageName        = "Arc"
 )
+
+// storeAppsDir is empty, the Microsoft Store apps are only installed on windows
+var storeAppsDir string
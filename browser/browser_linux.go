@@ -7,12 +7,7 @@ import (
 )
 
 var (
-	chromiumList = map[string]struct {
-		name        string
-		storage     string
-		profilePath string
-		dataTypes   []types.DataType
-	}{
+	chromiumList = map[string]browserInfo{
 		"chrome": {
 			name:        chromeName,
 			storage:     chromeStorageName,
@@ -53,15 +48,15 @@ var (
 			name:        braveName,
 			profilePath: braveProfilePath,
 			storage:     braveStorageName,
+			dataTypes:   types.DefaultBraveTypes,
+		},
+		"chromeos": {
+			name:        chromeOSName,
+			profilePath: chromeOSProfilePath,
 			dataTypes:   types.DefaultChromiumTypes,
 		},
 	}
-	firefoxList = map[string]struct {
-		name        string
-		storage     string
-		profilePath string
-		dataTypes   []types.DataType
-	}{
+	firefoxList = map[string]browserInfo{
 		"firefox": {
 			name:        firefoxName,
 			profilePath: firefoxProfilePath,
@@ -79,6 +74,8 @@ var (
 	chromeBetaProfilePath = homeDir + "/.config/google-chrome-beta/Default/"
 	operaProfilePath      = homeDir + "/.config/opera/Default/"
 	vivaldiProfilePath    = homeDir + "/.config/vivaldi/Default/"
+	// chromeOSProfilePath is the cryptohome of the logged in user, ChromeOS keeps the profile at its root
+	chromeOSProfilePath = "/home/chronos/user/"
 )
 
 const (
@@ -90,3 +87,6 @@ const (
 	operaStorageName      = "Chromium Safe Storage"
 	vivaldiStorageName    = "Chrome Safe Storage"
 )
+
+// storeAppsDir is empty, the Microsoft Store apps are only installed on windows
+var storeAppsDir string
@@ -7,12 +7,7 @@ import (
 )
 
 var (
-	chromiumList = map[string]struct {
-		name        string
-		profilePath string
-		storage     string
-		dataTypes   []types.DataType
-	}{
+	chromiumList = map[string]browserInfo{
 		"chrome": {
 			name:        chromeName,
 			profilePath: chromeUserDataPath,
@@ -56,7 +51,7 @@ var (
 		"brave": {
 			name:        braveName,
 			profilePath: braveProfilePath,
-			dataTypes:   types.DefaultChromiumTypes,
+			dataTypes:   types.DefaultBraveTypes,
 		},
 		"yandex": {
 			name:        yandexName,
@@ -84,12 +79,7 @@ var (
 			dataTypes:   types.DefaultChromiumTypes,
 		},
 	}
-	firefoxList = map[string]struct {
-		name        string
-		storage     string
-		profilePath string
-		dataTypes   []types.DataType
-	}{
+	firefoxList = map[string]browserInfo{
 		"firefox": {
 			name:        firefoxName,
 			profilePath: firefoxProfilePath,
@@ -116,3 +106,6 @@ var (
 
 	firefoxProfilePath = homeDir + "/AppData/Roaming/Mozilla/Firefox/Profiles/"
 )
+
+// storeAppsDir is the folder of the Microsoft Store (UWP) apps
+var storeAppsDir = homeDir + "/AppData/Local/Packages/"
@@ -0,0 +1,58 @@
+package chromium
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrKeychainDenied is returned when the master key can't be read from the keychain, eg: the
+// access prompt was denied or the session isn't interactive.
+var ErrKeychainDenied = errors.New("keychain access denied")
+
+// ErrChromeKeyBrowser is returned when -chrome-key isn't given the browser of the key, every
+// chromium browser has its own master key, eg: Chrome, Edge and Brave.
+var ErrChromeKeyBrowser = errors.New("chrome key must be scoped to a single browser")
+
+// chromeKey is the master key supplied with -chrome-key, it's used instead of reading the
+// key from the keychain or Local State of chromeKeyBrowser.
+var (
+	chromeKey        []byte
+	chromeKeyBrowser string
+)
+
+// chromeKeyOf returns the key of -chrome-key if it's given for the browser, nil otherwise
+func chromeKeyOf(browser string) []byte {
+	if !strings.EqualFold(browser, chromeKeyBrowser) {
+		return nil
+	}
+	return chromeKey
+}
+
+// SetChromeKey sets the pre-fetched master key of the chromium browser, hex or base64
+// encoded, eg: the 16 bytes key derived from the keychain secret on macOS. The other
+// browsers read their key from the system as usual, and empty reads every key from the system.
+func SetChromeKey(browser, key string) error {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		chromeKey, chromeKeyBrowser = nil, ""
+		return nil
+	}
+	browser = strings.ToLower(strings.TrimSpace(browser))
+	if browser == "" || browser == "all" {
+		return ErrChromeKeyBrowser
+	}
+	b, err := hex.DecodeString(key)
+	if err != nil {
+		if b, err = base64.StdEncoding.DecodeString(key); err != nil {
+			return fmt.Errorf("chrome key is neither hex nor base64: %w", err)
+		}
+	}
+	if len(b) != 16 && len(b) != 32 {
+		return fmt.Errorf("chrome key is %d bytes, want 16 or 32", len(b))
+	}
+	chromeKey, chromeKeyBrowser = b, browser
+	return nil
+}
@@ -0,0 +1,30 @@
+package chromium
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetChromeKey(t *testing.T) {
+	defer func() { _ = SetChromeKey("", "") }()
+	require.NoError(t, SetChromeKey("Chrome", "000102030405060708090a0b0c0d0e0f"))
+	assert.Equal(t, []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}, chromeKey)
+	assert.Equal(t, chromeKey, chromeKeyOf("chrome"))
+	// the other browsers have their own key
+	assert.Nil(t, chromeKeyOf("edge"))
+	assert.Nil(t, chromeKeyOf("brave"))
+
+	require.NoError(t, SetChromeKey("chrome", "AAECAwQFBgcICQoLDA0ODwABAgMEBQYHCAkKCwwNDg8="))
+	assert.Len(t, chromeKey, 32)
+
+	assert.Error(t, SetChromeKey("chrome", "0001"))
+	assert.Error(t, SetChromeKey("chrome", "not a key!"))
+
+	assert.ErrorIs(t, SetChromeKey("all", "000102030405060708090a0b0c0d0e0f"), ErrChromeKeyBrowser)
+	assert.ErrorIs(t, SetChromeKey("", "000102030405060708090a0b0c0d0e0f"), ErrChromeKeyBrowser)
+
+	require.NoError(t, SetChromeKey("all", ""))
+	assert.Nil(t, chromeKey)
+}
@@ -0,0 +1,45 @@
+package chromium
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrChromeOSCryptohome is returned for a ChromeOS profile which is still encrypted by the cryptohome,
+// the profile is only readable while the user is logged in, or from a copy made in a logged in session.
+var ErrChromeOSCryptohome = errors.New("chromeos profile is encrypted in the cryptohome, " +
+	"log in to the chromebook and read the mounted /home/chronos/user with -p, or copy it from a logged in session")
+
+const (
+	// chronosDir is the user data dir of ChromeOS, the cryptohome of the logged in user is
+	// mounted at /home/chronos/user and /home/chronos/u-<hash>
+	chronosDir = "/home/chronos/"
+	// shadowDir keeps the encrypted vaults of the cryptohomes, and their keys sealed by the TPM
+	shadowDir = "/home/.shadow/"
+)
+
+// isChromeOSProfile reports whether the path is in the user data dir of ChromeOS,
+// it's matched anywhere in the path, so a ChromeOS image mounted elsewhere is detected as well.
+func isChromeOSProfile(path string) bool {
+	return strings.Contains(filepath.ToSlash(path), chronosDir)
+}
+
+// checkChromeOSProfile returns ErrChromeOSCryptohome for an encrypted vault, or a cryptohome
+// which is not mounted, instead of silently finding no browser data in it.
+func checkChromeOSProfile(profilePath string) error {
+	p := filepath.ToSlash(profilePath)
+	if strings.Contains(p, shadowDir) {
+		return ErrChromeOSCryptohome
+	}
+	if !isChromeOSProfile(p) {
+		return nil
+	}
+	// the mount point of a cryptohome is an empty folder while the user is logged out
+	entries, err := os.ReadDir(filepath.Clean(profilePath))
+	if err != nil || len(entries) == 0 {
+		return ErrChromeOSCryptohome
+	}
+	return nil
+}
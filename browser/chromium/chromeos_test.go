@@ -0,0 +1,24 @@
+package chromium
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckChromeOSProfile(t *testing.T) {
+	image := t.TempDir()
+	mounted := filepath.Join(image, "home", "chronos", "user")
+	require.NoError(t, os.MkdirAll(mounted, 0o750))
+
+	assert.ErrorIs(t, checkChromeOSProfile(mounted), ErrChromeOSCryptohome, "a cryptohome which is not mounted is empty")
+	require.NoError(t, os.WriteFile(filepath.Join(mounted, "History"), nil, 0o600))
+	assert.NoError(t, checkChromeOSProfile(mounted))
+
+	assert.ErrorIs(t, checkChromeOSProfile(filepath.Join(image, "home", ".shadow", "0123abcd", "mount")), ErrChromeOSCryptohome)
+	assert.ErrorIs(t, checkChromeOSProfile(filepath.Join(image, "home", "chronos", "u-0123abcd")), ErrChromeOSCryptohome)
+	assert.NoError(t, checkChromeOSProfile(filepath.Join(image, ".config", "google-chrome", "Default")))
+}
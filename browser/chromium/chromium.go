@@ -1,12 +1,14 @@
 package chromium
 
 import (
-	"io/fs"
+	"errors"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/moond4rk/hackbrowserdata/browserdata"
+	"github.com/moond4rk/hackbrowserdata/crypto"
 	"github.com/moond4rk/hackbrowserdata/log"
 	"github.com/moond4rk/hackbrowserdata/types"
 	"github.com/moond4rk/hackbrowserdata/utils/fileutil"
@@ -15,15 +17,22 @@ import (
 
 type Chromium struct {
 	name        string
+	browser     string
+	profile     string
 	storage     string
 	profilePath string
 	masterKey   []byte
-	dataTypes   []types.DataType
-	Paths       map[types.DataType]string
+	// keySource is where the master key was read from, eg: keychain
+	keySource string
+	dataTypes []types.DataType
+	Paths     map[types.DataType]string
 }
 
 // New create instance of Chromium browser, fill item's path if item is existed.
 func New(name, storage, profilePath string, dataTypes []types.DataType) ([]*Chromium, error) {
+	if err := checkChromeOSProfile(profilePath); err != nil {
+		return nil, err
+	}
 	c := &Chromium{
 		name:        name,
 		storage:     storage,
@@ -34,13 +43,20 @@ func New(name, storage, profilePath string, dataTypes []types.DataType) ([]*Chro
 	if err != nil {
 		return nil, err
 	}
+	userDataDir := fileutil.ParentDir(profilePath)
+	users := typeutil.Keys(multiDataTypePaths)
+	sort.Strings(users)
 	chromiumList := make([]*Chromium, 0, len(multiDataTypePaths))
-	for user, itemPaths := range multiDataTypePaths {
+	for _, user := range users {
+		itemPaths := multiDataTypePaths[user]
 		chromiumList = append(chromiumList, &Chromium{
-			name:      fileutil.BrowserName(name, user),
-			dataTypes: typeutil.Keys(itemPaths),
-			Paths:     itemPaths,
-			storage:   storage,
+			name:        fileutil.BrowserName(name, user),
+			browser:     name,
+			profile:     user,
+			profilePath: filepath.Join(userDataDir, user),
+			dataTypes:   types.SortedKeys(itemPaths),
+			Paths:       itemPaths,
+			storage:     storage,
 		})
 	}
 	return chromiumList, nil
@@ -50,6 +66,31 @@ func (c *Chromium) Name() string {
 	return c.name
 }
 
+func (c *Chromium) Profile() (string, string) {
+	return c.browser, c.profile
+}
+
+func (c *Chromium) ItemPaths() map[types.DataType]string {
+	return c.Paths
+}
+
+func (c *Chromium) ProfilePath() string {
+	return c.profilePath
+}
+
+// SelectItems returns a copy of the profile with only the items, the master key is kept
+func (c *Chromium) SelectItems(items []types.DataType) *Chromium {
+	selected := *c
+	selected.Paths = make(map[types.DataType]string, len(items)+1)
+	for _, item := range append([]types.DataType{types.ChromiumKey}, items...) {
+		if p, ok := c.Paths[item]; ok {
+			selected.Paths[item] = p
+		}
+	}
+	selected.dataTypes = types.SortedKeys(selected.Paths)
+	return &selected
+}
+
 func (c *Chromium) BrowsingData(isFullExport bool) (*browserdata.BrowserData, error) {
 	// delete chromiumKey from dataTypes, doesn't need to export key
 	var dataTypes []types.DataType
@@ -65,116 +106,161 @@ func (c *Chromium) BrowsingData(isFullExport bool) (*browserdata.BrowserData, er
 
 	data := browserdata.New(dataTypes)
 
-	if err := c.copyItemToLocal(); err != nil {
+	if _, err := types.CreateProfileDir(c.browser, c.profile); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := types.RemoveProfileDir(); err != nil {
+			log.Warnf("remove temp dir of the profile error: %v", err)
+		}
+	}()
+	if types.InPlace() {
+		types.SetSourcePaths(c.Paths)
+	} else if err := c.copyItemToLocal(); err != nil {
 		return nil, err
 	}
 
-	masterKey, err := c.GetMasterKey()
-	if err != nil {
+	masterKey, err := c.masterKeyOrChromeKey()
+	data.SetKeySource(c.keySource)
+	switch {
+	case errors.Is(err, ErrNoEncryptedKey):
+		// browsers before Chrome 80 encrypt every value with DPAPI directly
+		log.Warnf("%s: %v, passwords and cookies are decrypted with DPAPI directly", c.name, err)
+		data.SetKeyError(err)
+	case errors.Is(err, ErrKeychainDenied):
+		// the metadata of the logins is still exported, supply the key with -chrome-key for the values
+		log.Warnf("%s: %v, passwords are exported without their values", c.name, err)
+		data.SetKeyError(err)
+	case err != nil:
 		return nil, err
 	}
 
 	c.masterKey = masterKey
-	if err := data.Recovery(c.masterKey); err != nil {
+	decryptor := crypto.NewChromiumDecryptor(c.masterKey)
+	if errors.Is(err, ErrKeychainDenied) {
+		decryptor = crypto.DeniedDecryptor{}
+	}
+	if err := data.Recovery(decryptor); err != nil {
 		return nil, err
 	}
 
 	return data, nil
 }
 
+// masterKeyOrChromeKey returns the key supplied with -chrome-key for the browser, or reads the
+// master key of the browser from the system.
+func (c *Chromium) masterKeyOrChromeKey() ([]byte, error) {
+	key := chromeKeyOf(c.browser)
+	if len(key) == 0 {
+		return c.GetMasterKey()
+	}
+	defer types.ChromiumKey.RemoveTemp()
+	log.Debugf("%s: use the master key of -chrome-key", c.name)
+	c.keySource = "chrome-key"
+	return key, nil
+}
+
 func (c *Chromium) copyItemToLocal() error {
 	for i, path := range c.Paths {
 		filename := i.TempFilename()
 		var err error
 		switch {
 		case fileutil.IsDirExists(path):
-			if i == types.ChromiumLocalStorage {
-				err = fileutil.CopyDir(path, filename, "lock")
-			}
-			if i == types.ChromiumSessionStorage {
+			switch i {
+			case types.ChromiumLocalStorage, types.ChromiumSessionStorage, types.ChromiumSessions, types.ChromiumPushSubscription, types.ChromiumSyncData,
+				types.ChromiumReadingList:
 				err = fileutil.CopyDir(path, filename, "lock")
 			}
 		default:
-			err = fileutil.CopyFile(path, filename)
+			err = fileutil.CopyFileVerified(path, filename, i.Format().Verify)
+		}
+		// the cookies set while the browser is running are only in the wal, eg: Network/Cookies-wal
+		if err == nil && i.Format() == types.FormatSQLite {
+			if err := fileutil.CopyWAL(path+types.WALSuffix, i.TempWALFilename()); err != nil {
+				log.Warnf("copy wal of item to local, path %s, err %v", path, err)
+			}
+		}
+		// copy the backup file as well, it's used when the item is corrupted
+		if backup := path + types.BackupSuffix; fileutil.IsFileExists(backup) {
+			if err := fileutil.CopyFile(backup, i.TempBackupFilename()); err != nil {
+				log.Warnf("copy backup item to local, path %s, err %v", backup, err)
+			}
 		}
 		if err != nil {
 			log.Errorf("copy item to local, path %s, filename %s err %v", path, filename, err)
-			continue
 		}
 	}
 	return nil
 }
 
-// userDataTypePaths return a map of user to item path, map[profile 1][item's name & path key pair]
+// userDataTypePaths return a map of user to item path, map[profile 1][item's name & path key pair].
+// The profiles are the folders of the user data folder holding an item, their items are
+// resolved with ProfileItemPaths.
 func (c *Chromium) userDataTypePaths(profilePath string, items []types.DataType) (map[string]map[types.DataType]string, error) {
-	multiItemPaths := make(map[string]map[types.DataType]string)
-	parentDir := fileutil.ParentDir(profilePath)
-	err := filepath.Walk(parentDir, chromiumWalkFunc(items, multiItemPaths))
+	userDataDir := fileutil.ParentDir(profilePath)
+	entries, err := os.ReadDir(userDataDir)
 	if err != nil {
+		if os.IsPermission(err) {
+			log.Warnf("skipping read chromium user data folder permission error, path %s, err %v", userDataDir, err)
+			return nil, nil
+		}
 		return nil, err
 	}
-	var keyPath string
-	var dir string
-	for userDir, profiles := range multiItemPaths {
-		for _, profile := range profiles {
-			if strings.HasSuffix(profile, types.ChromiumKey.Filename()) {
-				keyPath = profile
-				dir = userDir
-				break
-			}
+	profileFolder := filepath.Base(filepath.Clean(profilePath))
+	multiItemPaths := make(map[string]map[types.DataType]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || strings.Contains(name, "Snapshot") {
+			continue
 		}
-	}
-	t := make(map[string]map[types.DataType]string)
-	for userDir, v := range multiItemPaths {
-		if userDir == dir {
+		dir := filepath.Join(userDataDir, name)
+		if systemProfiles[name] && !includeSystem {
+			log.Warnf("skip chromium profile %s, use -include-system to export it", dir)
 			continue
 		}
-		t[userDir] = v
-		t[userDir][types.ChromiumKey] = keyPath
-		fillLocalStoragePath(t[userDir], types.ChromiumLocalStorage)
+		// a folder with its own Local State is another user data folder, unless it's the profile
+		// folder itself, eg: the forks whose only profile holds its Local State
+		if name != profileFolder && fileutil.IsFileExists(filepath.Join(dir, types.ChromiumKey.Filename())) {
+			continue
+		}
+		itemPaths := ProfileItemPaths(dir, items)
+		if len(itemPaths) == 0 || len(itemPaths) == 1 && itemPaths[types.ChromiumKey] != "" {
+			continue
+		}
+		if p, ok := itemPaths[types.ChromiumCookie]; ok {
+			log.Debugf("use cookie file %s of profile %s", p, name)
+		}
+		multiItemPaths[name] = itemPaths
 	}
-	return t, nil
+	return multiItemPaths, nil
 }
 
-// chromiumWalkFunc return a filepath.WalkFunc to find item's path
-func chromiumWalkFunc(items []types.DataType, multiItemPaths map[string]map[types.DataType]string) filepath.WalkFunc {
-	return func(path string, info fs.FileInfo, err error) error {
-		if err != nil {
-			if os.IsPermission(err) {
-				log.Warnf("skipping walk chromium path permission error, path %s, err %v", path, err)
-				return nil
-			}
-			return err
+// ProfileItemPaths returns the absolute path of the items of the profile folder, the master
+// key is the Local State of the profile folder if it holds one, else the one of the user data
+// folder. The items whose file doesn't exist are not returned.
+func ProfileItemPaths(profileDir string, items []types.DataType) map[types.DataType]string {
+	itemPaths := types.ResolveItemPaths(profileDir, items)
+	if _, ok := itemPaths[types.ChromiumKey]; ok {
+		return itemPaths
+	}
+	for _, item := range items {
+		if item != types.ChromiumKey {
+			continue
 		}
-		for _, v := range items {
-			if info.Name() != v.Filename() {
-				continue
-			}
-			if strings.Contains(path, "System Profile") {
-				continue
-			}
-			if strings.Contains(path, "Snapshot") {
-				continue
-			}
-			profileFolder := fileutil.ParentBaseDir(path)
-			if strings.Contains(filepath.ToSlash(path), "/Network/Cookies") {
-				profileFolder = fileutil.BaseDir(strings.ReplaceAll(filepath.ToSlash(path), "/Network/Cookies", ""))
-			}
-			if _, exist := multiItemPaths[profileFolder]; exist {
-				multiItemPaths[profileFolder][v] = path
-			} else {
-				multiItemPaths[profileFolder] = map[types.DataType]string{v: path}
-			}
+		if keyPath := filepath.Join(fileutil.ParentDir(profileDir), types.ChromiumKey.Filename()); fileutil.IsFileExists(keyPath) {
+			itemPaths[types.ChromiumKey] = keyPath
 		}
-		return nil
 	}
+	return itemPaths
 }
 
-func fillLocalStoragePath(itemPaths map[types.DataType]string, storage types.DataType) {
-	if p, ok := itemPaths[types.ChromiumHistory]; ok {
-		lsp := filepath.Join(filepath.Dir(p), storage.Filename())
-		if fileutil.IsDirExists(lsp) {
-			itemPaths[types.ChromiumLocalStorage] = lsp
-		}
-	}
+// systemProfiles are the profile folders chromium creates for itself, they rarely hold user data
+var systemProfiles = map[string]bool{"Guest Profile": true, "System Profile": true}
+
+// includeSystem exports the system profiles too
+var includeSystem bool
+
+// SetIncludeSystem includes the Guest Profile and System Profile folders, they're skipped by default
+func SetIncludeSystem(b bool) {
+	includeSystem = b
 }
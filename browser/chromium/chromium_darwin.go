@@ -7,7 +7,6 @@ import (
 	"crypto/sha1"
 	"errors"
 	"fmt"
-	"os"
 	"os/exec"
 	"strings"
 
@@ -23,17 +22,28 @@ var (
 
 func (c *Chromium) GetMasterKey() ([]byte, error) {
 	// don't need chromium key file for macOS
-	defer os.Remove(types.ChromiumKey.TempFilename())
+	defer types.ChromiumKey.RemoveTemp()
 	// Get the master key from the keychain
 	// $ security find-generic-password -wa 'Chrome'
-	var (
-		stdout, stderr bytes.Buffer
-	)
-	cmd := exec.Command("security", "find-generic-password", "-wa", strings.TrimSpace(c.storage)) //nolint:gosec
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("run security command failed: %w, message %s", err, stderr.String())
+	var stdout, stderr bytes.Buffer
+	err := crypto.Retry("security find-generic-password", func() error {
+		stdout.Reset()
+		stderr.Reset()
+		cmd := exec.Command("security", "find-generic-password", "-wa", strings.TrimSpace(c.storage)) //nolint:gosec
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		return cmd.Run()
+	}, func(error) bool {
+		return isTransientKeychain(stderr.String())
+	})
+	if err != nil {
+		if strings.Contains(stderr.String(), "could not be found") {
+			return nil, errCouldNotFindInKeychain
+		}
+		if isKeychainDenied(stderr.String()) {
+			return nil, fmt.Errorf("%w: run security command failed: %v, message %s", ErrKeychainDenied, err, strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf("run security command failed: %v, message %s", err, strings.TrimSpace(stderr.String()))
 	}
 
 	if stderr.Len() > 0 {
@@ -54,6 +64,41 @@ func (c *Chromium) GetMasterKey() ([]byte, error) {
 		return nil, errWrongSecurityCommand
 	}
 	c.masterKey = key
+	c.keySource = "keychain"
 	log.Debugf("get master key success, browser %s", c.name)
 	return key, nil
 }
+
+// keychainTransientErrors are the messages of the security command when securityd is busy
+// or restarting, a denied prompt or a missing item fails the same way every time.
+var keychainTransientErrors = []string{
+	"internal component", // errSecInternalComponent -2070
+	"not available",      // errSecNotAvailable -25291
+	"timed out",
+}
+
+func isTransientKeychain(stderr string) bool {
+	for _, msg := range keychainTransientErrors {
+		if strings.Contains(stderr, msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// keychainDeniedErrors are the messages of the security command when the access prompt was
+// denied, or it can't be shown in a non interactive session.
+var keychainDeniedErrors = []string{
+	"User canceled the operation",           // errSecUserCanceled -128
+	"User interaction is not allowed",       // errSecInteractionNotAllowed -25308
+	"passphrase you entered is not correct", // errSecAuthFailed -25293
+}
+
+func isKeychainDenied(stderr string) bool {
+	for _, msg := range keychainDeniedErrors {
+		if strings.Contains(stderr, msg) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+//go:build darwin
+
+package chromium
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsKeychainDenied(t *testing.T) {
+	assert.True(t, isKeychainDenied("security: SecKeychainSearchCopyNext: User canceled the operation."))
+	assert.True(t, isKeychainDenied("security: SecKeychainItemCopyContent: User interaction is not allowed."))
+	assert.False(t, isKeychainDenied("security: SecKeychainSearchCopyNext: The specified item could not be found in the keychain."))
+	assert.False(t, isKeychainDenied("security: SecKeychainCopyDefault: A default keychain could not be found."))
+	assert.False(t, isKeychainDenied(""))
+}
@@ -5,7 +5,6 @@ package chromium
 import (
 	"crypto/sha1"
 	"fmt"
-	"os"
 
 	"github.com/godbus/dbus/v5"
 	keyring "github.com/ppacher/go-dbus-keyring"
@@ -18,15 +17,24 @@ import (
 func (c *Chromium) GetMasterKey() ([]byte, error) {
 	// what is d-bus @https://dbus.freedesktop.org/
 	// don't need chromium key file for Linux
-	defer os.Remove(types.ChromiumKey.TempFilename())
+	defer types.ChromiumKey.RemoveTemp()
+
+	if c.isChromeOS() {
+		// ChromeOS has no keyring, the cryptohome encrypts the profile and os_crypt uses the default secret
+		// @https://source.chromium.org/chromium/chromium/src/+/main:components/os_crypt/sync/os_crypt_posix.cc
+		return c.deriveMasterKey(nil)
+	}
 
 	conn, err := dbus.SessionBus()
 	if err != nil {
-		return nil, err
+		// chromium uses the basic store of the default secret without a keyring, eg: headless servers
+		log.Warnf("%s: connect dbus session error: %v, use the default secret", c.name, err)
+		return c.deriveMasterKey(nil)
 	}
 	svc, err := keyring.GetSecretService(conn)
 	if err != nil {
-		return nil, err
+		log.Warnf("%s: get secret service error: %v, use the default secret", c.name, err)
+		return c.deriveMasterKey(nil)
 	}
 	session, err := svc.OpenSession()
 	if err != nil {
@@ -62,8 +70,14 @@ func (c *Chromium) GetMasterKey() ([]byte, error) {
 			}
 		}
 	}
+	return c.deriveMasterKey(secret)
+}
 
+// deriveMasterKey derives the v10 key of the profile from the secret of the keyring
+func (c *Chromium) deriveMasterKey(secret []byte) ([]byte, error) {
+	c.keySource = "keyring"
 	if len(secret) == 0 {
+		c.keySource = "default secret"
 		// set default secret @https://source.chromium.org/chromium/chromium/src/+/main:components/os_crypt/os_crypt_linux.cc;l=100
 		secret = []byte("peanuts")
 	}
@@ -74,3 +88,13 @@ func (c *Chromium) GetMasterKey() ([]byte, error) {
 	log.Debugf("get master key success, browser %s", c.name)
 	return key, nil
 }
+
+// isChromeOS reports whether the items of the browser are read from a ChromeOS profile
+func (c *Chromium) isChromeOS() bool {
+	for item, p := range c.Paths {
+		if item != types.ChromiumKey {
+			return isChromeOSProfile(p)
+		}
+	}
+	return false
+}
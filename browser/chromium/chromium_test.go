@@ -0,0 +1,130 @@
+package chromium
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/browserdata/cookie"
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/fileutil"
+)
+
+func writeFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o750))
+		require.NoError(t, os.WriteFile(p, nil, 0o600))
+	}
+}
+
+func TestNew_CookiePaths(t *testing.T) {
+	userData := t.TempDir()
+	writeFiles(t, userData,
+		"Local State",
+		"Default/Cookies", "Default/Network/Cookies",
+		"Profile 1/Cookies",
+		"Profile 2/Network/Cookies",
+	)
+
+	browsers, err := New("Chrome", "", filepath.Join(userData, "Default")+"/", []types.DataType{types.ChromiumKey, types.ChromiumCookie})
+	require.NoError(t, err)
+	paths := make(map[string]string)
+	for _, b := range browsers {
+		_, profile := b.Profile()
+		paths[profile] = b.Paths[types.ChromiumCookie]
+	}
+	assert.Equal(t, map[string]string{
+		"Default":   filepath.Join(userData, "Default", "Network", "Cookies"),
+		"Profile 1": filepath.Join(userData, "Profile 1", "Cookies"),
+		"Profile 2": filepath.Join(userData, "Profile 2", "Network", "Cookies"),
+	}, paths)
+}
+
+func TestNew_SystemProfiles(t *testing.T) {
+	userData := t.TempDir()
+	writeFiles(t, userData, "Local State", "Default/History", "Guest Profile/History", "System Profile/History")
+	profiles := func() []string {
+		browsers, err := New("Chrome", "", filepath.Join(userData, "Default")+"/", []types.DataType{types.ChromiumKey, types.ChromiumHistory})
+		require.NoError(t, err)
+		var names []string
+		for _, b := range browsers {
+			_, profile := b.Profile()
+			names = append(names, profile)
+		}
+		return names
+	}
+	assert.Equal(t, []string{"Default"}, profiles())
+
+	SetIncludeSystem(true)
+	defer SetIncludeSystem(false)
+	assert.Equal(t, []string{"Default", "Guest Profile", "System Profile"}, profiles())
+}
+
+// writeWALCookies writes Network/Cookies in wal mode as a running browser does, the cookie
+// "checkpointed" is in the database and "wal" is only in Network/Cookies-wal.
+func writeWALCookies(t *testing.T, profileDir string) {
+	t.Helper()
+	live := filepath.Join(t.TempDir(), "Cookies")
+	db, err := sql.Open("sqlite", live)
+	require.NoError(t, err)
+	defer db.Close()
+	// a single connection keeps the wal open, it's checkpointed and removed on close
+	db.SetMaxOpenConns(1)
+	for _, query := range []string{
+		`PRAGMA journal_mode=WAL`,
+		`CREATE TABLE cookies (creation_utc INTEGER NOT NULL, host_key TEXT NOT NULL, top_frame_site_key TEXT NOT NULL, name TEXT NOT NULL, value TEXT NOT NULL, encrypted_value BLOB NOT NULL, path TEXT NOT NULL, expires_utc INTEGER NOT NULL, is_secure INTEGER NOT NULL, is_httponly INTEGER NOT NULL, last_access_utc INTEGER NOT NULL, has_expires INTEGER NOT NULL, is_persistent INTEGER NOT NULL)`,
+		`INSERT INTO cookies VALUES (1, '.example.com', '', 'checkpointed', '', x'', '/', 0, 0, 0, 0, 0, 0)`,
+		`PRAGMA wal_checkpoint(TRUNCATE)`,
+		`PRAGMA wal_autocheckpoint=0`,
+		`INSERT INTO cookies VALUES (2, '.example.com', '', 'wal', '', x'', '/', 0, 0, 0, 0, 0, 0)`,
+	} {
+		_, err = db.Exec(query)
+		require.NoError(t, err)
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(profileDir, "Network"), 0o750))
+	require.NoError(t, fileutil.CopyFile(live, filepath.Join(profileDir, "Network", "Cookies")))
+	require.NoError(t, fileutil.CopyFile(live+types.WALSuffix, filepath.Join(profileDir, "Network", "Cookies"+types.WALSuffix)))
+}
+
+func TestBrowsingData_NetworkCookiesWAL(t *testing.T) {
+	defer func(dir string) { require.NoError(t, types.SetTempDir(dir)) }(types.TempDir())
+	require.NoError(t, types.SetTempDir(t.TempDir()))
+
+	userData := t.TempDir()
+	writeFiles(t, userData, "Local State", "Default/Cookies")
+	writeWALCookies(t, filepath.Join(userData, "Default"))
+
+	browsers, err := New("Chrome", "", filepath.Join(userData, "Default")+"/", []types.DataType{types.ChromiumKey, types.ChromiumCookie})
+	require.NoError(t, err)
+	require.Len(t, browsers, 1)
+	c := browsers[0]
+	require.NoError(t, c.copyItemToLocal())
+	require.FileExists(t, types.ChromiumCookie.TempWALFilename())
+
+	var cookies cookie.ChromiumCookie
+	require.NoError(t, cookies.Extract(nil))
+	names := make([]string, 0, len(cookies))
+	for _, v := range cookies {
+		names = append(names, v.KeyName)
+	}
+	assert.ElementsMatch(t, []string{"checkpointed", "wal"}, names)
+	assert.NoFileExists(t, types.ChromiumCookie.TempWALFilename())
+}
+
+func TestChromium_SelectItems(t *testing.T) {
+	userData := t.TempDir()
+	writeFiles(t, userData, "Local State", "Default/Cookies", "Default/History")
+
+	browsers, err := New("Chrome", "", filepath.Join(userData, "Default")+"/", []types.DataType{types.ChromiumKey, types.ChromiumCookie, types.ChromiumHistory})
+	require.NoError(t, err)
+	require.Len(t, browsers, 1)
+	selected := browsers[0].SelectItems([]types.DataType{types.ChromiumHistory})
+	assert.Equal(t, []types.DataType{types.ChromiumKey, types.ChromiumHistory}, types.SortedKeys(selected.ItemPaths()))
+	assert.Equal(t, []types.DataType{types.ChromiumKey, types.ChromiumCookie, types.ChromiumHistory}, types.SortedKeys(browsers[0].ItemPaths()))
+}
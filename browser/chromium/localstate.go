@@ -0,0 +1,51 @@
+package chromium
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/moond4rk/hackbrowserdata/utils/fileutil"
+)
+
+var (
+	// ErrNoEncryptedKey is returned when Local State has no os_crypt.encrypted_key,
+	// the browser is freshly installed or the file is corrupted.
+	ErrNoEncryptedKey = errors.New("os_crypt.encrypted_key not found in Local State")
+	// ErrDPAPIUnwrapFailed is returned when the encrypted_key can't be decrypted with DPAPI,
+	// the Local State is copied from another user or machine.
+	ErrDPAPIUnwrapFailed = errors.New("decrypt encrypted_key with DPAPI failed")
+
+	errDecodeMasterKeyFailed = errors.New("decode master key failed")
+)
+
+// dpapiKeyPrefix is the prefix of the encrypted_key wrapped by DPAPI
+const dpapiKeyPrefix = "DPAPI"
+
+// LoadChromeKey reads os_crypt.encrypted_key of the Local State file,
+// and returns the master key unwrapped by unwrap, which is DPAPI on Windows.
+func LoadChromeKey(localStatePath string, unwrap func([]byte) ([]byte, error)) ([]byte, error) {
+	s, err := fileutil.ReadFile(localStatePath)
+	if err != nil {
+		return nil, err
+	}
+	encryptedKey := gjson.Get(s, "os_crypt.encrypted_key")
+	if !encryptedKey.Exists() || encryptedKey.String() == "" {
+		return nil, ErrNoEncryptedKey
+	}
+	key, err := base64.StdEncoding.DecodeString(encryptedKey.String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errDecodeMasterKeyFailed, err)
+	}
+	if !bytes.HasPrefix(key, []byte(dpapiKeyPrefix)) {
+		return nil, fmt.Errorf("%w: missing %s prefix", errDecodeMasterKeyFailed, dpapiKeyPrefix)
+	}
+	masterKey, err := unwrap(key[len(dpapiKeyPrefix):])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDPAPIUnwrapFailed, err)
+	}
+	return masterKey, nil
+}
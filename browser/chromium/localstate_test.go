@@ -0,0 +1,66 @@
+package chromium
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeLocalState(t *testing.T, content string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "Local State")
+	require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+	return p
+}
+
+func localStateWithKey(key []byte) string {
+	return `{"os_crypt":{"encrypted_key":"` + base64.StdEncoding.EncodeToString(key) + `"}}`
+}
+
+func TestLoadChromeKey(t *testing.T) {
+	unwrap := func(b []byte) ([]byte, error) { return append([]byte("unwrapped:"), b...), nil }
+	errUnwrap := errors.New("the data is invalid")
+
+	testCases := []struct {
+		name    string
+		content string
+		unwrap  func([]byte) ([]byte, error)
+		want    string
+		wantErr error
+	}{
+		{name: "ok", content: localStateWithKey([]byte("DPAPIsecret")), unwrap: unwrap, want: "unwrapped:secret"},
+		{name: "missing key", content: `{"os_crypt":{}}`, unwrap: unwrap, wantErr: ErrNoEncryptedKey},
+		{name: "empty key", content: `{"os_crypt":{"encrypted_key":""}}`, unwrap: unwrap, wantErr: ErrNoEncryptedKey},
+		{name: "corrupted", content: `{"os_crypt":`, unwrap: unwrap, wantErr: ErrNoEncryptedKey},
+		{name: "invalid base64", content: `{"os_crypt":{"encrypted_key":"%%%"}}`, unwrap: unwrap, wantErr: errDecodeMasterKeyFailed},
+		{name: "missing prefix", content: localStateWithKey([]byte("secret")), unwrap: unwrap, wantErr: errDecodeMasterKeyFailed},
+		{
+			name:    "dpapi failed",
+			content: localStateWithKey([]byte("DPAPIsecret")),
+			unwrap:  func([]byte) ([]byte, error) { return nil, errUnwrap },
+			wantErr: ErrDPAPIUnwrapFailed,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := LoadChromeKey(writeLocalState(t, tc.content), tc.unwrap)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Nil(t, key)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, string(key))
+		})
+	}
+}
+
+func TestLoadChromeKey_MissingFile(t *testing.T) {
+	_, err := LoadChromeKey(filepath.Join(t.TempDir(), "Local State"), nil)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
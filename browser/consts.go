@@ -2,11 +2,26 @@ package browser
 
 import (
 	"os"
+
+	"github.com/moond4rk/hackbrowserdata/types"
 )
 
 // home dir path for all platforms
 var homeDir, _ = os.UserHomeDir()
 
+// browserInfo is a browser which can be picked by its key with -b
+type browserInfo struct {
+	name        string
+	storage     string
+	profilePath string
+	dataTypes   []types.DataType
+	// localState is the Local State of the master key relative to the profile path, empty is
+	// the Local State found in the user data folder
+	localState string
+	// singleProfile keeps only the profile of the profile path, not the profiles next to it
+	singleProfile bool
+}
+
 const (
 	chromeName     = "Chrome"
 	chromeBetaName = "Chrome Beta"
@@ -24,4 +39,5 @@ const (
 	dcBrowserName  = "DC"
 	sogouName      = "Sogou"
 	arcName        = "Arc"
+	chromeOSName   = "ChromeOS"
 )
@@ -0,0 +1,52 @@
+package browser
+
+import (
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/fileutil"
+)
+
+// Discovery is a found browser profile and its item files, it's written as json by
+// --browsers-json for the scripts which decide what to extract.
+type Discovery struct {
+	Browser string          `json:"browser"`
+	Profile string          `json:"profile"`
+	Path    string          `json:"path"`
+	Items   []DiscoveryItem `json:"items"`
+}
+
+// DiscoveryItem is an item file of the profile, locked is whether a running browser
+// holds a lock on it, the locked files are copied but can't be read in place.
+type DiscoveryItem struct {
+	Item   string `json:"item"`
+	Path   string `json:"path"`
+	Exists bool   `json:"exists"`
+	Locked bool   `json:"locked"`
+}
+
+// Discover lists the profiles and item files of the browsers, the files are only
+// checked, nothing is copied or opened for writing.
+func Discover(browsers []Browser) []Discovery {
+	discoveries := make([]Discovery, 0, len(browsers))
+	for _, b := range browsers {
+		name, profile := b.Profile()
+		d := Discovery{
+			Browser: name,
+			Profile: profile,
+			Path:    b.ProfilePath(),
+			Items:   []DiscoveryItem{},
+		}
+		paths := b.ItemPaths()
+		for _, item := range types.SortedKeys(paths) {
+			p := paths[item]
+			exists := fileutil.IsFileExists(p) || fileutil.IsDirExists(p)
+			d.Items = append(d.Items, DiscoveryItem{
+				Item:   item.String(),
+				Path:   p,
+				Exists: exists,
+				Locked: exists && isLocked(p),
+			})
+		}
+		discoveries = append(discoveries, d)
+	}
+	return discoveries
+}
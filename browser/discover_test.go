@@ -0,0 +1,40 @@
+package browser
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	history := filepath.Join(dir, "History")
+	require.NoError(t, os.WriteFile(history, []byte("rows"), 0o600))
+	b := fakeBrowser{paths: map[types.DataType]string{
+		types.ChromiumHistory:      history,
+		types.ChromiumCookie:       filepath.Join(dir, "Network", "Cookies"),
+		types.ChromiumLocalStorage: dir,
+	}}
+
+	discoveries := Discover([]Browser{b})
+	require.Len(t, discoveries, 1)
+	d := discoveries[0]
+	assert.Equal(t, "Chrome", d.Browser)
+	assert.Equal(t, "Default", d.Profile)
+	assert.Equal(t, []DiscoveryItem{
+		{Item: "ChromiumCookie", Path: filepath.Join(dir, "Network", "Cookies")},
+		{Item: "ChromiumHistory", Path: history, Exists: true},
+		{Item: "ChromiumLocalStorage", Path: dir, Exists: true},
+	}, d.Items)
+	assert.NoFileExists(t, filepath.Join(dir, "Network", "Cookies"), "discover must not create files")
+
+	raw, err := json.Marshal(Discover(nil))
+	require.NoError(t, err)
+	assert.JSONEq(t, `[]`, string(raw))
+}
@@ -5,9 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"io/fs"
-	"os"
-	"path/filepath"
+	"strings"
 
 	_ "modernc.org/sqlite" // sqlite3 driver TODO: replace with chooseable driver
 
@@ -16,11 +14,12 @@ import (
 	"github.com/moond4rk/hackbrowserdata/log"
 	"github.com/moond4rk/hackbrowserdata/types"
 	"github.com/moond4rk/hackbrowserdata/utils/fileutil"
-	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
 )
 
 type Firefox struct {
 	name        string
+	browser     string
+	profile     string
 	storage     string
 	profilePath string
 	masterKey   []byte
@@ -30,68 +29,82 @@ type Firefox struct {
 
 var ErrProfilePathNotFound = errors.New("profile path not found")
 
-// New returns new Firefox instances.
-func New(profilePath string, items []types.DataType) ([]*Firefox, error) {
-	multiItemPaths := make(map[string]map[types.DataType]string)
-	// ignore walk dir error since it can be produced by a single entry
-	_ = filepath.WalkDir(profilePath, firefoxWalkFunc(items, multiItemPaths))
+// New returns new Firefox instances, name is the browser name used as prefix of the profile names.
+// The profiles are resolved from profiles.ini, a folder holding the profile files
+// directly (e.g. portable Firefox) is used as a single profile.
+func New(name, profilePath string, items []types.DataType) ([]*Firefox, error) {
+	prefix := strings.ToLower(name)
+	if isProfileDir(profilePath, items) {
+		log.Debugf("find firefox profile folder %s", profilePath)
+		return newFromProfileDirs(prefix, []string{profilePath}, items), nil
+	}
 
-	firefoxList := make([]*Firefox, 0, len(multiItemPaths))
-	for name, itemPaths := range multiItemPaths {
+	iniPath, err := findProfilesINI(profilePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w and it's not a profile folder, set the profile path to the folder of profiles.ini or to a profile folder", err)
+	}
+	profiles, err := ReadProfiles(iniPath)
+	if err != nil {
+		return nil, fmt.Errorf("read %s error: %w", iniPath, err)
+	}
+	selected, err := selectProfiles(profiles, profileName)
+	if err != nil {
+		return nil, err
+	}
+	if len(selected) < len(profiles) {
+		log.Warnf("export firefox profile %s only, use --firefox-profile all to export all %d profiles", selected[0].Name, len(profiles))
+	}
+	dirs := make([]string, 0, len(selected))
+	for _, p := range selected {
+		dirs = append(dirs, p.Path)
+	}
+	return newFromProfileDirs(prefix, dirs, items), nil
+}
+
+func newFromProfileDirs(prefix string, dirs []string, items []types.DataType) []*Firefox {
+	firefoxList := make([]*Firefox, 0, len(dirs))
+	for _, dir := range dirs {
+		itemPaths := types.ResolveItemPaths(dir, items)
+		if len(itemPaths) == 0 {
+			log.Warnf("find firefox profile failed, no item found in %s", dir)
+			continue
+		}
 		firefoxList = append(firefoxList, &Firefox{
-			name:      fmt.Sprintf("firefox-%s", name),
-			items:     typeutil.Keys(itemPaths),
-			itemPaths: itemPaths,
+			name:        fmt.Sprintf("%s-%s", prefix, fileutil.BaseDir(dir)),
+			browser:     prefix,
+			profile:     fileutil.BaseDir(dir),
+			profilePath: dir,
+			items:       types.SortedKeys(itemPaths),
+			itemPaths:   itemPaths,
 		})
 	}
-
-	return firefoxList, nil
+	return firefoxList
 }
 
 func (f *Firefox) copyItemToLocal() error {
 	for i, path := range f.itemPaths {
 		filename := i.TempFilename()
-		if err := fileutil.CopyFile(path, filename); err != nil {
-			return err
+		if err := fileutil.CopyFileVerified(path, filename, i.Format().Verify); err != nil {
+			log.Errorf("copy item to local, path %s, filename %s err %v", path, filename, err)
+			continue
 		}
-	}
-	return nil
-}
-
-func firefoxWalkFunc(items []types.DataType, multiItemPaths map[string]map[types.DataType]string) fs.WalkDirFunc {
-	return func(path string, info fs.DirEntry, err error) error {
-		if err != nil {
-			if os.IsPermission(err) {
-				log.Warnf("skipping walk firefox path %s permission error: %v", path, err)
-				return nil
+		if i.Format() == types.FormatSQLite {
+			if err := fileutil.CopyWAL(path+types.WALSuffix, i.TempWALFilename()); err != nil {
+				log.Warnf("copy wal of item to local, path %s, err %v", path, err)
 			}
-			return err
 		}
-		for _, v := range items {
-			if info.Name() == v.Filename() {
-				parentBaseDir := fileutil.ParentBaseDir(path)
-				if _, exist := multiItemPaths[parentBaseDir]; exist {
-					multiItemPaths[parentBaseDir][v] = path
-				} else {
-					multiItemPaths[parentBaseDir] = map[types.DataType]string{v: path}
-				}
-			}
-		}
-
-		return nil
 	}
+	return nil
 }
 
 // GetMasterKey returns master key of Firefox. from key4.db
 func (f *Firefox) GetMasterKey() ([]byte, error) {
-	tempFilename := types.FirefoxKey4.TempFilename()
-
 	// Open and defer close of the database.
-	keyDB, err := sql.Open("sqlite", tempFilename)
+	keyDB, err := sql.Open("sqlite", types.FirefoxKey4.DSN())
 	if err != nil {
 		return nil, fmt.Errorf("open key4.db error: %w", err)
 	}
-	defer os.Remove(tempFilename)
+	defer types.FirefoxKey4.RemoveTemp()
 	defer keyDB.Close()
 
 	metaItem1, metaItem2, err := queryMetaData(keyDB)
@@ -116,42 +129,68 @@ func queryMetaData(db *sql.DB) ([]byte, []byte, error) {
 	return metaItem1, metaItem2, nil
 }
 
+// nssKeyID is the CKA_ID (a102) of the key of the logins in nssPrivate
+var nssKeyID = []byte{248, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}
+
+// queryNssPrivate returns the key whose id is nssKeyID, or the first key if there is no such id
 func queryNssPrivate(db *sql.DB) ([]byte, []byte, error) {
 	const query = `SELECT a11, a102 from nssPrivate`
-	var nssA11, nssA102 []byte
-	if err := db.QueryRow(query).Scan(&nssA11, &nssA102); err != nil {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	var firstA11, firstA102 []byte
+	for rows.Next() {
+		var nssA11, nssA102 []byte
+		if err := rows.Scan(&nssA11, &nssA102); err != nil {
+			return nil, nil, err
+		}
+		if bytes.Equal(nssA102, nssKeyID) {
+			return nssA11, nssA102, nil
+		}
+		if firstA11 == nil {
+			firstA11, firstA102 = nssA11, nssA102
+		}
+	}
+	if err := rows.Err(); err != nil {
 		return nil, nil, err
 	}
-	return nssA11, nssA102, nil
+	if firstA11 == nil {
+		return nil, nil, sql.ErrNoRows
+	}
+	return firstA11, firstA102, nil
+}
+
+// skipDecryptCheck decrypts the master key even if the password-check of key4.db doesn't match
+var skipDecryptCheck bool
+
+// SetSkipDecryptCheck disables the password-check gate of the master key, the logins are
+// decrypted anyway, it recovers the profiles whose check is stored differently.
+func SetSkipDecryptCheck(b bool) {
+	skipDecryptCheck = b
 }
 
 // processMasterKey process master key of Firefox.
 // Process the metaBytes and nssA11 with the corresponding cryptographic operations.
 func processMasterKey(metaItem1, metaItem2, nssA11, nssA102 []byte) ([]byte, error) {
-	metaPBE, err := crypto.NewASN1PBE(metaItem2)
-	if err != nil {
-		return nil, fmt.Errorf("error creating ASN1PBE from metaItem2: %w", err)
-	}
-
-	flag, err := metaPBE.Decrypt(metaItem1)
-	if err != nil {
-		return nil, fmt.Errorf("error decrypting master key: %w", err)
-	}
-	const passwordCheck = "password-check"
-
-	if !bytes.Contains(flag, []byte(passwordCheck)) {
-		return nil, errors.New("flag verification failed: password-check not found")
+	if err := checkPassword(metaItem1, metaItem2); err != nil {
+		if !skipDecryptCheck {
+			return nil, err
+		}
+		log.Warnf("%v, decrypt the master key anyway", err)
 	}
 
-	keyLin := []byte{248, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}
-	if !bytes.Equal(nssA102, keyLin) {
-		return nil, errors.New("master key verification failed: nssA102 not equal to expected value")
+	if !bytes.Equal(nssA102, nssKeyID) {
+		log.Debugf("nssPrivate key id %x is not %x, use it anyway", nssA102, nssKeyID)
 	}
 
 	nssA11PBE, err := crypto.NewASN1PBE(nssA11)
 	if err != nil {
 		return nil, fmt.Errorf("error creating ASN1PBE from nssA11: %w", err)
 	}
+	algorithm := crypto.PBEAlgorithm(nssA11PBE)
+	log.Debugf("firefox master key is encrypted with %s", algorithm)
 
 	finallyKey, err := nssA11PBE.Decrypt(metaItem1)
 	if err != nil {
@@ -160,13 +199,62 @@ func processMasterKey(metaItem1, metaItem2, nssA11, nssA102 []byte) ([]byte, err
 	if len(finallyKey) < 24 {
 		return nil, errors.New("length of final key is less than 24 bytes")
 	}
-	return finallyKey[:24], nil
+	// the legacy pbe wraps a 3des key, the aes pbe of Firefox 75 and later wraps
+	// a 3des or an aes-256 key, the logins use the key of their own cipher
+	if algorithm == crypto.PBE3DES || len(finallyKey) < 32 {
+		return finallyKey[:24], nil
+	}
+	return finallyKey[:32], nil
+}
+
+// checkPassword decrypts the password-check of key4.db, it fails with a primary password
+func checkPassword(metaItem1, metaItem2 []byte) error {
+	metaPBE, err := crypto.NewASN1PBE(metaItem2)
+	if err != nil {
+		return fmt.Errorf("error creating ASN1PBE from metaItem2: %w", err)
+	}
+
+	flag, err := metaPBE.Decrypt(metaItem1)
+	if err != nil {
+		return fmt.Errorf("error decrypting master key: %w", err)
+	}
+	const passwordCheck = "password-check"
+
+	if !bytes.Contains(flag, []byte(passwordCheck)) {
+		return errors.New("flag verification failed: password-check not found")
+	}
+	return nil
 }
 
 func (f *Firefox) Name() string {
 	return f.name
 }
 
+func (f *Firefox) Profile() (string, string) {
+	return f.browser, f.profile
+}
+
+func (f *Firefox) ItemPaths() map[types.DataType]string {
+	return f.itemPaths
+}
+
+func (f *Firefox) ProfilePath() string {
+	return f.profilePath
+}
+
+// SelectItems returns a copy of the profile with only the items, key4.db is kept
+func (f *Firefox) SelectItems(items []types.DataType) *Firefox {
+	selected := *f
+	selected.itemPaths = make(map[types.DataType]string, len(items)+1)
+	for _, item := range append([]types.DataType{types.FirefoxKey4}, items...) {
+		if p, ok := f.itemPaths[item]; ok {
+			selected.itemPaths[item] = p
+		}
+	}
+	selected.items = types.SortedKeys(selected.itemPaths)
+	return &selected
+}
+
 func (f *Firefox) BrowsingData(isFullExport bool) (*browserdata.BrowserData, error) {
 	dataTypes := f.items
 	if !isFullExport {
@@ -174,8 +262,19 @@ func (f *Firefox) BrowsingData(isFullExport bool) (*browserdata.BrowserData, err
 	}
 
 	data := browserdata.New(dataTypes)
+	data.SetKeySource(types.FirefoxKey4.Filename())
 
-	if err := f.copyItemToLocal(); err != nil {
+	if _, err := types.CreateProfileDir(f.browser, f.profile); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := types.RemoveProfileDir(); err != nil {
+			log.Warnf("remove temp dir of the profile error: %v", err)
+		}
+	}()
+	if types.InPlace() {
+		types.SetSourcePaths(f.itemPaths)
+	} else if err := f.copyItemToLocal(); err != nil {
 		return nil, err
 	}
 
@@ -185,7 +284,7 @@ func (f *Firefox) BrowsingData(isFullExport bool) (*browserdata.BrowserData, err
 	}
 
 	f.masterKey = masterKey
-	if err := data.Recovery(f.masterKey); err != nil {
+	if err := data.Recovery(crypto.NSSDecryptor{Key: f.masterKey}); err != nil {
 		return nil, err
 	}
 	return data, nil
@@ -1,10 +1,19 @@
 package firefox
 
 import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/crypto"
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/fileutil"
 )
 
 func TestQueryMetaData(t *testing.T) {
@@ -36,3 +45,89 @@ func TestQueryNssPrivate(t *testing.T) {
 	assert.Equal(t, []byte("nssA11"), nssA11)
 	assert.Equal(t, []byte("nssA102"), nssA102)
 }
+
+func TestProcessMasterKey_SkipDecryptCheck(t *testing.T) {
+	keyLin := []byte{248, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}
+	_, err := processMasterKey([]byte("globalSalt"), []byte("bad check"), []byte("bad key"), keyLin)
+	assert.ErrorContains(t, err, "metaItem2")
+
+	SetSkipDecryptCheck(true)
+	defer SetSkipDecryptCheck(false)
+	// the check is skipped, the master key itself is decrypted
+	_, err = processMasterKey([]byte("globalSalt"), []byte("bad check"), []byte("bad key"), keyLin)
+	assert.ErrorContains(t, err, "nssA11")
+}
+
+func TestQueryNssPrivate_KeyID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"a11", "a102"}).
+		AddRow([]byte("other"), []byte("otherID")).
+		AddRow([]byte("nssA11"), nssKeyID)
+	mock.ExpectQuery("SELECT a11, a102 from nssPrivate").WillReturnRows(rows)
+
+	nssA11, nssA102, err := queryNssPrivate(db)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("nssA11"), nssA11)
+	assert.Equal(t, nssKeyID, nssA102)
+}
+
+// testdata/profile is created by NSS, the library of Firefox, with an empty primary password:
+// key4.db is encrypted with PBES2 AES-256-CBC like the profiles of Firefox 100+.
+const fixtureProfile = "testdata/profile"
+
+func TestFirefox_BrowsingDataFixture(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(fixtureProfile, types.FirefoxKey4.Filename())+"?mode=ro")
+	require.NoError(t, err)
+	nssA11, _, err := queryNssPrivate(db)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+	pbe, err := crypto.NewASN1PBE(nssA11)
+	require.NoError(t, err)
+	assert.Equal(t, crypto.PBEAES256, crypto.PBEAlgorithm(pbe))
+
+	require.NoError(t, types.SetTempDir(t.TempDir()))
+	t.Cleanup(func() { _ = types.SetTempDir(os.TempDir()) })
+
+	browsers := newFromProfileDirs("firefox", []string{fixtureProfile}, types.DefaultFirefoxTypes)
+	require.Len(t, browsers, 1)
+	data, err := browsers[0].BrowsingData(true)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, data.WriteItem(&buf, "password", "json"))
+	assert.Contains(t, buf.String(), `"UserName": "moond4rk"`)
+	assert.Contains(t, buf.String(), `"Password": "hackbrowserdata"`)
+	assert.Contains(t, buf.String(), `"Password": "correct horse battery staple"`)
+}
+
+func TestFirefox_BrowsingDataLoginsBackup(t *testing.T) {
+	require.NoError(t, types.SetTempDir(t.TempDir()))
+	t.Cleanup(func() { _ = types.SetTempDir(os.TempDir()) })
+
+	// the profile has logins-backup.json only, eg: logins.json was removed
+	root := t.TempDir()
+	dir := filepath.Join(root, "abcd1234.default-release")
+	require.NoError(t, os.MkdirAll(dir, 0o700))
+	require.NoError(t, fileutil.CopyFile(filepath.Join(fixtureProfile, types.FirefoxKey4.Filename()), filepath.Join(dir, types.FirefoxKey4.Filename())))
+	require.NoError(t, fileutil.CopyFile(filepath.Join(fixtureProfile, types.FirefoxPassword.Filename()), filepath.Join(dir, types.FirefoxPasswordBackup.Filename())))
+
+	portable, err := New("firefox", dir, types.DefaultFirefoxTypes)
+	require.NoError(t, err)
+	for name, browsers := range map[string][]*Firefox{
+		"profile":  newFromProfileDirs("firefox", []string{dir}, types.DefaultFirefoxTypes),
+		"portable": portable,
+	} {
+		require.Len(t, browsers, 1, name)
+		assert.Contains(t, browsers[0].items, types.FirefoxPassword, name)
+		assert.Equal(t, filepath.Join(dir, types.FirefoxPasswordBackup.Filename()), browsers[0].itemPaths[types.FirefoxPassword], name)
+
+		data, err := browsers[0].BrowsingData(true)
+		require.NoError(t, err, name)
+		var buf bytes.Buffer
+		require.NoError(t, data.WriteItem(&buf, "password", "json"), name)
+		assert.Contains(t, buf.String(), `"Password": "hackbrowserdata"`, name)
+	}
+}
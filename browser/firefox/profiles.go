@@ -0,0 +1,152 @@
+package firefox
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/fileutil"
+)
+
+// Profile is a Firefox profile listed in profiles.ini
+type Profile struct {
+	Name       string
+	Path       string
+	IsRelative bool
+	Default    bool
+}
+
+var ErrProfilesININotFound = errors.New("profiles.ini not found")
+
+const (
+	profilesINI = "profiles.ini"
+
+	// AllProfiles selects every profile listed in profiles.ini
+	AllProfiles = "all"
+)
+
+// profileName is the profile selected by the user, empty means the default profile
+var profileName string
+
+// SetProfileName selects the Firefox profile to export by its name in profiles.ini,
+// empty selects the profile marked as default, AllProfiles selects all of them.
+func SetProfileName(name string) {
+	profileName = name
+}
+
+// findProfilesINI looks up profiles.ini in dir and its parent, the Profiles folder
+// on Windows and macOS is a child of the folder which holds profiles.ini
+func findProfilesINI(dir string) (string, error) {
+	for _, d := range []string{dir, fileutil.ParentDir(dir)} {
+		p := filepath.Join(d, profilesINI)
+		if fileutil.IsFileExists(p) {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("%w in %s", ErrProfilesININotFound, dir)
+}
+
+// ReadProfiles parses profiles.ini and returns all profiles with absolute paths.
+// The default profile is taken from the [Install*] section used since Firefox 67,
+// and from the Default=1 key of the [Profile*] sections for older versions.
+func ReadProfiles(iniPath string) ([]Profile, error) {
+	f, err := os.Open(filepath.Clean(iniPath))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		profiles       []Profile
+		installDefault string
+		current        *Profile
+		section        string
+	)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.Trim(line, "[]")
+			current = nil
+			if strings.HasPrefix(section, "Profile") {
+				profiles = append(profiles, Profile{})
+				current = &profiles[len(profiles)-1]
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch {
+		case current != nil:
+			switch key {
+			case "Name":
+				current.Name = value
+			case "Path":
+				current.Path = value
+			case "IsRelative":
+				current.IsRelative = value == "1"
+			case "Default":
+				current.Default = value == "1"
+			}
+		case strings.HasPrefix(section, "Install") && key == "Default" && installDefault == "":
+			installDefault = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	baseDir := filepath.Dir(iniPath)
+	for i := range profiles {
+		p := &profiles[i]
+		if installDefault != "" {
+			p.Default = p.Path == installDefault
+		}
+		if p.IsRelative {
+			p.Path = filepath.Join(baseDir, filepath.FromSlash(p.Path))
+		}
+	}
+	return profiles, nil
+}
+
+// selectProfiles returns the profiles matching name, see SetProfileName
+func selectProfiles(profiles []Profile, name string) ([]Profile, error) {
+	if len(profiles) == 0 {
+		return nil, errors.New("no profile found in profiles.ini")
+	}
+	switch name {
+	case AllProfiles:
+		return profiles, nil
+	case "":
+		for _, p := range profiles {
+			if p.Default {
+				return []Profile{p}, nil
+			}
+		}
+		return profiles[:1], nil
+	}
+	names := make([]string, 0, len(profiles))
+	for _, p := range profiles {
+		if p.Name == name || filepath.Base(p.Path) == name {
+			return []Profile{p}, nil
+		}
+		names = append(names, p.Name)
+	}
+	return nil, fmt.Errorf("firefox profile %s not found, available profiles: %s", name, strings.Join(names, ", "))
+}
+
+// isProfileDir reports whether dir is a profile folder itself, it holds a file of the items,
+// e.g. the Data/profile folder of a portable Firefox.
+func isProfileDir(dir string, items []types.DataType) bool {
+	return len(types.ResolveItemPaths(dir, items)) > 0
+}
@@ -0,0 +1,140 @@
+package firefox
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+const testProfilesINI = `[Install4F96D1932A9F858E]
+Default=Profiles/abcd.default-release
+Locked=1
+
+[Profile1]
+Name=default
+IsRelative=1
+Path=Profiles/efgh.default
+Default=1
+
+[Profile0]
+Name=default-release
+IsRelative=1
+Path=Profiles/abcd.default-release
+
+[Profile2]
+Name=portable
+IsRelative=0
+Path=/mnt/usb/firefox/profile
+
+[General]
+StartWithLastProfile=1
+Version=2
+`
+
+func writeProfilesINI(t *testing.T, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "Profiles"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, profilesINI), []byte(content), 0o600))
+	return dir
+}
+
+func TestReadProfiles(t *testing.T) {
+	dir := writeProfilesINI(t, testProfilesINI)
+
+	profiles, err := ReadProfiles(filepath.Join(dir, profilesINI))
+	require.NoError(t, err)
+	require.Len(t, profiles, 3)
+
+	assert.Equal(t, "default", profiles[0].Name)
+	assert.Equal(t, filepath.Join(dir, "Profiles", "efgh.default"), profiles[0].Path)
+	assert.False(t, profiles[0].Default, "install section overrides the legacy default")
+	assert.True(t, profiles[1].Default)
+	assert.Equal(t, "/mnt/usb/firefox/profile", profiles[2].Path)
+	assert.False(t, profiles[2].IsRelative)
+}
+
+func TestSelectProfiles(t *testing.T) {
+	dir := writeProfilesINI(t, testProfilesINI)
+	profiles, err := ReadProfiles(filepath.Join(dir, profilesINI))
+	require.NoError(t, err)
+
+	selected, err := selectProfiles(profiles, "")
+	require.NoError(t, err)
+	require.Len(t, selected, 1)
+	assert.Equal(t, "default-release", selected[0].Name)
+
+	selected, err = selectProfiles(profiles, AllProfiles)
+	require.NoError(t, err)
+	assert.Len(t, selected, 3)
+
+	selected, err = selectProfiles(profiles, "portable")
+	require.NoError(t, err)
+	assert.Equal(t, "/mnt/usb/firefox/profile", selected[0].Path)
+
+	_, err = selectProfiles(profiles, "missing")
+	assert.ErrorContains(t, err, "available profiles: default, default-release, portable")
+}
+
+func TestFindProfilesINI(t *testing.T) {
+	dir := writeProfilesINI(t, testProfilesINI)
+
+	p, err := findProfilesINI(filepath.Join(dir, "Profiles"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, profilesINI), p)
+
+	_, err = findProfilesINI(t.TempDir())
+	assert.ErrorIs(t, err, ErrProfilesININotFound)
+}
+
+func TestNewPortableProfile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "profile")
+	require.NoError(t, os.MkdirAll(dir, 0o750))
+	for _, item := range []types.DataType{types.FirefoxKey4, types.FirefoxPassword} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, item.Filename()), nil, 0o600))
+	}
+
+	browsers, err := New("Firefox", dir, types.DefaultFirefoxTypes)
+	require.NoError(t, err)
+	require.Len(t, browsers, 1)
+	assert.Equal(t, "firefox-profile", browsers[0].Name())
+	assert.ElementsMatch(t, []types.DataType{types.FirefoxKey4, types.FirefoxPassword}, browsers[0].items)
+}
+
+func TestNewWithoutProfilesINI(t *testing.T) {
+	dir := t.TempDir()
+	for _, profile := range []string{"abcd.default-release", "efgh.default"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, profile), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, profile, types.FirefoxCookie.Filename()), nil, 0o600))
+	}
+
+	_, err := New("Firefox", dir, types.DefaultFirefoxTypes)
+	assert.ErrorIs(t, err, ErrProfilesININotFound)
+	assert.ErrorContains(t, err, "not a profile folder")
+
+	browsers, err := New("Firefox", filepath.Join(dir, "efgh.default"), types.DefaultFirefoxTypes)
+	require.NoError(t, err)
+	require.Len(t, browsers, 1, "a profile folder is used without profiles.ini")
+	assert.Equal(t, []types.DataType{types.FirefoxCookie}, browsers[0].items)
+}
+
+func TestProfileItemPaths_Filenames(t *testing.T) {
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, "SiteSecurityServiceState.txt")
+	require.NoError(t, os.WriteFile(legacy, nil, 0o600))
+	assert.Equal(t, legacy, types.ResolveItemPaths(dir, types.DefaultFirefoxTypes)[types.FirefoxNetworkState])
+
+	bin := filepath.Join(dir, types.FirefoxNetworkState.Filename())
+	require.NoError(t, os.WriteFile(bin, nil, 0o600))
+	assert.Equal(t, bin, types.ResolveItemPaths(dir, types.DefaultFirefoxTypes)[types.FirefoxNetworkState])
+
+	browsers, err := New("firefox", dir, types.DefaultFirefoxTypes)
+	require.NoError(t, err)
+	require.Len(t, browsers, 1)
+	assert.Equal(t, bin, browsers[0].itemPaths[types.FirefoxNetworkState])
+}
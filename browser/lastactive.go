@@ -0,0 +1,100 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/fileutil"
+	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
+)
+
+// Profile is a found browser profile with the time it was last used, it's written as json
+// by --list-profiles to pick the profile which is really used among several.
+type Profile struct {
+	Browser    string     `json:"browser"`
+	Name       string     `json:"name"`
+	Path       string     `json:"path"`
+	LastActive *time.Time `json:"lastActive,omitempty"`
+	// LastActiveFrom is where the last active time is read from: Preferences, Local State or History
+	LastActiveFrom string `json:"lastActiveFrom,omitempty"`
+}
+
+// ListProfiles returns the profiles of the browsers, the most recently used first, the
+// browser files are only read, no database is copied or opened.
+func ListProfiles(browsers []Browser) []Profile {
+	profiles := make([]Profile, 0, len(browsers))
+	for _, b := range browsers {
+		name, profile := b.Profile()
+		p := Profile{Browser: name, Name: profile, Path: b.ProfilePath()}
+		if t, from := lastActive(b); !t.IsZero() {
+			p.LastActive, p.LastActiveFrom = &t, from
+		}
+		profiles = append(profiles, p)
+	}
+	sort.SliceStable(profiles, func(i, j int) bool {
+		a, b := profiles[i].LastActive, profiles[j].LastActive
+		return a != nil && (b == nil || a.After(*b))
+	})
+	return profiles
+}
+
+// lastActive returns when the profile was last used, profile.last_active_time of Preferences,
+// the active_time of the profile in Local State or the modification time of the history.
+func lastActive(b Browser) (time.Time, string) {
+	dir := b.ProfilePath()
+	if dir != "" {
+		if t := preferencesLastActive(filepath.Join(dir, "Preferences")); !t.IsZero() {
+			return t, "Preferences"
+		}
+		localState := filepath.Join(fileutil.ParentDir(dir), types.ChromiumKey.Filename())
+		if t := localStateLastActive(localState, filepath.Base(dir)); !t.IsZero() {
+			return t, "Local State"
+		}
+	}
+	paths := b.ItemPaths()
+	for _, item := range []types.DataType{types.ChromiumHistory, types.FirefoxHistory} {
+		if p, ok := paths[item]; ok {
+			if info, err := os.Stat(p); err == nil {
+				return info.ModTime(), "History"
+			}
+		}
+	}
+	return time.Time{}, ""
+}
+
+// preferencesLastActive returns profile.last_active_time, microseconds since 1601 as chromium
+// keeps the times of Preferences, zero if it's not set.
+func preferencesLastActive(path string) time.Time {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}
+	}
+	if v := gjson.GetBytes(data, "profile.last_active_time").Int(); v > 0 {
+		return typeutil.TimeEpoch(v)
+	}
+	return time.Time{}
+}
+
+// localStateLastActive returns profile.info_cache.<profile>.active_time of Local State,
+// seconds since the unix epoch, zero if it's not set.
+func localStateLastActive(path, profile string) time.Time {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}
+	}
+	key := "profile.info_cache." + gjsonEscaper.Replace(profile) + ".active_time"
+	if v := gjson.GetBytes(data, key).Float(); v > 0 {
+		sec := int64(v)
+		return time.Unix(sec, int64((v-float64(sec))*1e9))
+	}
+	return time.Time{}
+}
+
+// gjsonEscaper escapes the special characters of a gjson path
+var gjsonEscaper = strings.NewReplacer(".", `\.`, "*", `\*`, "?", `\?`)
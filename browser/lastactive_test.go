@@ -0,0 +1,53 @@
+package browser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
+)
+
+func TestListProfiles(t *testing.T) {
+	userData := t.TempDir()
+	newProfile := func(name string) string {
+		dir := filepath.Join(userData, name)
+		require.NoError(t, os.MkdirAll(dir, 0o700))
+		return dir
+	}
+	def, work, old := newProfile("Default"), newProfile("Profile 1"), newProfile("Profile 2")
+
+	active := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	prefs := fmt.Sprintf(`{"profile": {"last_active_time": "%d"}}`, typeutil.EpochFromTime(active))
+	require.NoError(t, os.WriteFile(filepath.Join(work, "Preferences"), []byte(prefs), 0o600))
+	localState := `{"profile": {"info_cache": {"Default": {"active_time": 1700000000.5}}}}`
+	require.NoError(t, os.WriteFile(filepath.Join(userData, "Local State"), []byte(localState), 0o600))
+	history := filepath.Join(old, "History")
+	require.NoError(t, os.WriteFile(history, nil, 0o600))
+	modified := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(history, modified, modified))
+
+	profiles := ListProfiles([]Browser{
+		fakeBrowser{paths: map[types.DataType]string{types.ChromiumHistory: history}, dir: old},
+		fakeBrowser{dir: def},
+		fakeBrowser{dir: work},
+		fakeBrowser{dir: newProfile("Profile 3")},
+	})
+	require.Len(t, profiles, 4)
+	assert.Equal(t, work, profiles[0].Path)
+	assert.True(t, active.Equal(*profiles[0].LastActive))
+	assert.Equal(t, "Preferences", profiles[0].LastActiveFrom)
+	assert.Equal(t, def, profiles[1].Path)
+	assert.Equal(t, int64(1700000000500), profiles[1].LastActive.UnixMilli())
+	assert.Equal(t, "Local State", profiles[1].LastActiveFrom)
+	assert.Equal(t, old, profiles[2].Path)
+	assert.True(t, modified.Equal(*profiles[2].LastActive))
+	assert.Equal(t, "History", profiles[2].LastActiveFrom)
+	assert.Nil(t, profiles[3].LastActive)
+}
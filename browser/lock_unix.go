@@ -0,0 +1,27 @@
+//go:build !windows
+
+package browser
+
+import (
+	"os"
+	"syscall"
+)
+
+// isLocked reports whether another process holds a posix lock on the file, sqlite locks
+// the database of a running browser. The folders are never locked.
+func isLocked(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err != nil || info.IsDir() {
+		return false
+	}
+	// F_GETLK only reports the lock, it doesn't take it
+	lk := syscall.Flock_t{Type: syscall.F_WRLCK, Whence: 0, Start: 0, Len: 0}
+	if err := syscall.FcntlFlock(f.Fd(), syscall.F_GETLK, &lk); err != nil {
+		return false
+	}
+	return lk.Type != syscall.F_UNLCK
+}
@@ -0,0 +1,26 @@
+//go:build windows
+
+package browser
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// the file is opened by a running browser without share access or with a locked range
+const (
+	errSharingViolation syscall.Errno = 32
+	errLockViolation    syscall.Errno = 33
+)
+
+// isLocked reports whether the file can't be opened because a running browser locks it,
+// the folders are never locked.
+func isLocked(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Is(err, errSharingViolation) || errors.Is(err, errLockViolation)
+	}
+	_ = f.Close()
+	return false
+}
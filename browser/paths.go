@@ -0,0 +1,67 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/moond4rk/hackbrowserdata/browser/chromium"
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/fileutil"
+)
+
+var ErrBrowserNotFound = errors.New("browser not found")
+
+// itemCompanions are the files read by an item besides its own, eg: the HSTS of networkState
+var itemCompanions = map[types.DataType][]types.DataType{
+	types.ChromiumNetworkState: {types.ChromiumTransportSecurity},
+	types.ChromiumHistory:      {types.ChromiumArchivedHistory},
+	types.ChromiumSettings:     {types.ChromiumSecurePreferences},
+	types.FirefoxCookie:        {types.FirefoxCookieContainer},
+}
+
+// ItemFiles returns the files of the item relative to the profile folder, the moved ones
+// and the files of its companions included.
+func ItemFiles(item types.DataType) []string {
+	files := item.ProfileFiles()
+	for _, companion := range itemCompanions[item] {
+		files = append(files, companion.ProfileFiles()...)
+	}
+	return files
+}
+
+// ResolveBrowserPaths returns the absolute path of every item file of the browser profile,
+// profile is the profile folder name, eg: Default, Profile 1 for chromium, or the folder in
+// Profiles for firefox, empty is the default profile. The items whose file doesn't exist
+// are not returned. The profile folders found by PickBrowsers are resolved the same way.
+func ResolveBrowserPaths(name, profile string) (map[types.DataType]string, error) {
+	name = strings.ToLower(name)
+	if c, ok := chromiumList[name]; ok {
+		return resolveChromiumPaths(c, profile), nil
+	}
+	if f, ok := firefoxList[name]; ok {
+		if profile == "" {
+			return nil, fmt.Errorf("%s: firefox profile folder is required", name)
+		}
+		return types.ResolveItemPaths(filepath.Join(filepath.Clean(resolveProfilePath(f)), profile), f.dataTypes), nil
+	}
+	return nil, fmt.Errorf("%w: %s, available browsers: %s", ErrBrowserNotFound, name, Names())
+}
+
+// resolveChromiumPaths resolves the items of the profile, the master key is the Local State
+// of the registry if any, else the one of the profile folder or of the user data folder.
+func resolveChromiumPaths(c browserInfo, profile string) map[types.DataType]string {
+	profileDir := filepath.Clean(resolveProfilePath(c))
+	if profile != "" {
+		profileDir = filepath.Join(fileutil.ParentDir(profileDir), profile)
+	}
+	paths := chromium.ProfileItemPaths(profileDir, c.dataTypes)
+	if c.localState != "" {
+		delete(paths, types.ChromiumKey)
+		if p := localStatePath(profileDir, c.localState); fileutil.IsFileExists(p) {
+			paths[types.ChromiumKey] = p
+		}
+	}
+	return paths
+}
@@ -0,0 +1,98 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func writeFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o750))
+		require.NoError(t, os.WriteFile(p, nil, 0o600))
+	}
+}
+
+func TestResolveBrowserPaths(t *testing.T) {
+	userData := t.TempDir()
+	writeFiles(t, userData, "Local State", "Default/History", "Default/Network/Cookies", "Profile 1/Cookies", "Profile 1/Login Data")
+	require.NoError(t, os.MkdirAll(filepath.Join(userData, "Default", "Local Storage", "leveldb"), 0o750))
+	firefoxDir := t.TempDir()
+	writeFiles(t, firefoxDir, "abcd.default-release/cookies.sqlite", "abcd.default-release/key4.db")
+
+	chromiumList["testium"] = browserInfo{name: "Testium", profilePath: filepath.Join(userData, "Default") + "/", dataTypes: types.DefaultChromiumTypes}
+	firefoxList["testfox"] = browserInfo{name: "Testfox", profilePath: firefoxDir, dataTypes: types.DefaultFirefoxTypes}
+	t.Cleanup(func() {
+		delete(chromiumList, "testium")
+		delete(firefoxList, "testfox")
+	})
+
+	paths, err := ResolveBrowserPaths("Testium", "")
+	require.NoError(t, err)
+	assert.Equal(t, map[types.DataType]string{
+		types.ChromiumKey:          filepath.Join(userData, "Local State"),
+		types.ChromiumHistory:      filepath.Join(userData, "Default", "History"),
+		types.ChromiumDownload:     filepath.Join(userData, "Default", "History"),
+		types.ChromiumMostVisited:  filepath.Join(userData, "Default", "History"),
+		types.ChromiumSafeBrowsing: filepath.Join(userData, "Default", "History"),
+		types.ChromiumCookie:       filepath.Join(userData, "Default", "Network", "Cookies"),
+		types.ChromiumLocalStorage: filepath.Join(userData, "Default", "Local Storage", "leveldb"),
+	}, paths)
+
+	paths, err = ResolveBrowserPaths("testium", "Profile 1")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(userData, "Profile 1", "Cookies"), paths[types.ChromiumCookie], "the legacy cookie path is used")
+	assert.Equal(t, filepath.Join(userData, "Profile 1", "Login Data"), paths[types.ChromiumPassword])
+	assert.Equal(t, filepath.Join(userData, "Local State"), paths[types.ChromiumKey])
+
+	paths, err = ResolveBrowserPaths("testfox", "abcd.default-release")
+	require.NoError(t, err)
+	assert.Equal(t, map[types.DataType]string{
+		types.FirefoxCookie: filepath.Join(firefoxDir, "abcd.default-release", "cookies.sqlite"),
+		types.FirefoxKey4:   filepath.Join(firefoxDir, "abcd.default-release", "key4.db"),
+	}, paths)
+
+	_, err = ResolveBrowserPaths("testfox", "")
+	assert.Error(t, err)
+	_, err = ResolveBrowserPaths("netscape", "")
+	assert.ErrorIs(t, err, ErrBrowserNotFound)
+}
+
+func TestItemFiles(t *testing.T) {
+	assert.Equal(t, []string{"Network/Cookies", "Cookies"}, ItemFiles(types.ChromiumCookie))
+	assert.Equal(t, []string{"History", "Archived History"}, ItemFiles(types.ChromiumHistory))
+	assert.Equal(t, []string{"Bookmarks"}, ItemFiles(types.ChromiumBookmark))
+	assert.Equal(t, []string{"Network/Network Persistent State", "Network Persistent State", "Network/TransportSecurity", "TransportSecurity"}, ItemFiles(types.ChromiumNetworkState))
+	assert.Equal(t, []string{"logins.json", "logins-backup.json"}, ItemFiles(types.FirefoxPassword))
+	assert.Equal(t, []string{"SiteSecurityServiceState.bin", "SiteSecurityServiceState.txt"}, ItemFiles(types.FirefoxNetworkState))
+}
+
+func TestItemFilesRegistered(t *testing.T) {
+	for _, dt := range extractor.RegisteredTypes() {
+		info, ok := extractor.ExtractorInfo(dt)
+		require.True(t, ok)
+		if dt.Filename() == types.UnsupportedItem {
+			assert.Empty(t, info.Sources, dt.String())
+			continue
+		}
+		assert.Equal(t, ItemFiles(dt), info.Sources, dt.String())
+	}
+}
+
+func TestItemBrowsers(t *testing.T) {
+	chromes := ItemBrowsers(types.ChromiumPassword)
+	assert.Contains(t, chromes, "chrome")
+	assert.NotContains(t, chromes, "firefox")
+	assert.NotContains(t, chromes, "yandex")
+	assert.NotContains(t, ItemBrowsers(types.YandexPassword), "chrome")
+	assert.Equal(t, []string{"brave"}, ItemBrowsers(types.BraveRewards))
+	assert.Contains(t, ItemBrowsers(types.FirefoxPassword), "firefox")
+}
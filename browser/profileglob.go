@@ -0,0 +1,46 @@
+package browser
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// profileGlob selects the profiles whose folder name matches it, eg: "Profile *", empty selects all
+var profileGlob string
+
+// SetProfileGlob selects the profiles by the filepath.Match pattern of their folder names
+func SetProfileGlob(pattern string) error {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid profile glob %s: %w", pattern, err)
+	}
+	profileGlob = pattern
+	return nil
+}
+
+// matchProfiles returns the browsers whose profile folder name matches the profile glob,
+// it's an error if none of them matches.
+func matchProfiles(browsers []Browser) ([]Browser, error) {
+	if profileGlob == "" {
+		return browsers, nil
+	}
+	var (
+		matched []Browser
+		names   []string
+	)
+	for _, b := range browsers {
+		_, profile := b.Profile()
+		// the pattern is checked by SetProfileGlob, Match can't fail here
+		if ok, _ := filepath.Match(profileGlob, profile); ok {
+			matched = append(matched, b)
+			continue
+		}
+		names = append(names, profile)
+	}
+	if len(matched) == 0 {
+		sort.Strings(names)
+		return nil, fmt.Errorf("%w, no profile matches %s, found profiles: %s", ErrNotFound, profileGlob, strings.Join(names, ", "))
+	}
+	return matched, nil
+}
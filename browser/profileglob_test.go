@@ -0,0 +1,39 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// namedProfile is a fake browser of the profile folder
+type namedProfile struct {
+	fakeBrowser
+	profile string
+}
+
+func (n namedProfile) Profile() (string, string) { return "Chrome", n.profile }
+
+func TestMatchProfiles(t *testing.T) {
+	browsers := []Browser{namedProfile{profile: "Default"}, namedProfile{profile: "Profile 1"}, namedProfile{profile: "Profile 12"}}
+	defer func() { require.NoError(t, SetProfileGlob("")) }()
+
+	matched, err := matchProfiles(browsers)
+	require.NoError(t, err)
+	assert.Len(t, matched, 3)
+
+	require.NoError(t, SetProfileGlob("Profile *"))
+	matched, err = matchProfiles(browsers)
+	require.NoError(t, err)
+	require.Len(t, matched, 2)
+	_, profile := matched[0].Profile()
+	assert.Equal(t, "Profile 1", profile)
+
+	require.NoError(t, SetProfileGlob("Work*"))
+	_, err = matchProfiles(browsers)
+	require.ErrorIs(t, err, ErrNotFound)
+	assert.Contains(t, err.Error(), "Default, Profile 1, Profile 12")
+
+	assert.Error(t, SetProfileGlob("Profile ["))
+}
@@ -0,0 +1,150 @@
+package browser
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/moond4rk/hackbrowserdata/log"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+// BrowserConfig is an entry of the browser registry, adding a Chromium or Firefox
+// fork is an entry with the profile path of each OS, the parsers are shared.
+type BrowserConfig struct {
+	// Key is the name of the browser used with -b
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	// Engine is chromium or firefox
+	Engine string `json:"engine"`
+	// Storage is the keychain or keyring name of the master key, only for chromium
+	Storage string `json:"storage"`
+	// Paths maps GOOS to the profile path, ~ and ${ENV} are expanded
+	Paths map[string]string `json:"paths"`
+	// LocalState is the Local State file of the master key relative to the profile path, only
+	// for chromium, empty is the Local State of the user data folder, eg: ../Local State
+	LocalState string `json:"localState"`
+	// ProfileLayout is multi for the browsers with many profiles next to the profile path, the
+	// default, or single for the browsers whose profile path is their only profile
+	ProfileLayout string `json:"profileLayout"`
+}
+
+const (
+	engineChromium = "chromium"
+	engineFirefox  = "firefox"
+)
+
+const (
+	layoutMulti  = "multi"
+	layoutSingle = "single"
+)
+
+//go:embed registry.json
+var embeddedRegistry []byte
+
+func init() {
+	configs, err := parseBrowserConfigs(embeddedRegistry)
+	if err != nil {
+		panic(fmt.Sprintf("parse embedded browser registry: %v", err))
+	}
+	for _, c := range configs {
+		// the hard-coded browsers come first, the embedded registry only adds new ones
+		if _, ok := chromiumList[c.Key]; ok {
+			continue
+		}
+		if _, ok := firefoxList[c.Key]; ok {
+			continue
+		}
+		registerBrowser(c)
+	}
+}
+
+// LoadBrowserConfig loads the browser registry file, its entries are added to
+// the supported browsers, and replace the built-in browsers with the same key.
+func LoadBrowserConfig(filename string) error {
+	data, err := os.ReadFile(filepath.Clean(filename))
+	if err != nil {
+		return err
+	}
+	configs, err := parseBrowserConfigs(data)
+	if err != nil {
+		return fmt.Errorf("parse browser config %s: %w", filename, err)
+	}
+	for _, c := range configs {
+		delete(chromiumList, c.Key)
+		delete(firefoxList, c.Key)
+		if !registerBrowser(c) {
+			log.Warnf("browser %s has no profile path for %s, skipped", c.Key, runtime.GOOS)
+		}
+	}
+	return nil
+}
+
+func parseBrowserConfigs(data []byte) ([]BrowserConfig, error) {
+	var configs []BrowserConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, err
+	}
+	for i, c := range configs {
+		c.Key = strings.ToLower(c.Key)
+		if c.Key == "" || c.Key == "all" {
+			return nil, fmt.Errorf("invalid browser key %q", c.Key)
+		}
+		if c.Name == "" {
+			c.Name = c.Key
+		}
+		if c.Engine != engineChromium && c.Engine != engineFirefox {
+			return nil, fmt.Errorf("browser %s: unknown engine %q, available engines: %s|%s", c.Key, c.Engine, engineChromium, engineFirefox)
+		}
+		if c.ProfileLayout == "" {
+			c.ProfileLayout = layoutMulti
+		}
+		if c.ProfileLayout != layoutMulti && c.ProfileLayout != layoutSingle {
+			return nil, fmt.Errorf("browser %s: unknown profile layout %q, available layouts: %s|%s", c.Key, c.ProfileLayout, layoutMulti, layoutSingle)
+		}
+		if c.LocalState != "" && c.Engine != engineChromium {
+			return nil, fmt.Errorf("browser %s: localState is only for chromium", c.Key)
+		}
+		configs[i] = c
+	}
+	return configs, nil
+}
+
+// registerBrowser adds the browser to the list of its engine,
+// it returns false if the browser has no profile path for the current OS.
+func registerBrowser(c BrowserConfig) bool {
+	p, ok := c.Paths[runtime.GOOS]
+	if !ok || p == "" {
+		return false
+	}
+	info := browserInfo{
+		name:          c.Name,
+		storage:       c.Storage,
+		profilePath:   expandPath(p),
+		singleProfile: c.ProfileLayout == layoutSingle,
+	}
+	if c.LocalState != "" {
+		info.localState = expandPath(c.LocalState)
+	}
+	switch c.Engine {
+	case engineFirefox:
+		info.dataTypes = types.DefaultFirefoxTypes
+		firefoxList[c.Key] = info
+	default:
+		info.dataTypes = types.DefaultChromiumTypes
+		chromiumList[c.Key] = info
+	}
+	return true
+}
+
+// expandPath expands the leading ~ to the home dir and the ${ENV} variables
+func expandPath(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		p = homeDir + p[1:]
+	}
+	return filepath.FromSlash(os.ExpandEnv(p))
+}
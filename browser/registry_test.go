@@ -0,0 +1,139 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func TestParseBrowserConfigs(t *testing.T) {
+	configs, err := parseBrowserConfigs([]byte(`[{"key": "Arc2", "engine": "chromium", "paths": {"linux": "/tmp"}}]`))
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Equal(t, "arc2", configs[0].Key)
+	assert.Equal(t, "arc2", configs[0].Name)
+
+	_, err = parseBrowserConfigs([]byte(`[{"key": "webkit", "engine": "safari"}]`))
+	assert.ErrorContains(t, err, "unknown engine")
+	_, err = parseBrowserConfigs([]byte(`[{"engine": "chromium"}]`))
+	assert.ErrorContains(t, err, "invalid browser key")
+	_, err = parseBrowserConfigs([]byte(`{}`))
+	assert.Error(t, err)
+}
+
+func TestEmbeddedRegistry(t *testing.T) {
+	configs, err := parseBrowserConfigs(embeddedRegistry)
+	require.NoError(t, err)
+	assert.NotEmpty(t, configs)
+}
+
+func TestLoadBrowserConfig(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "browsers.json")
+	config := `[
+		{"key": "forkium", "name": "Forkium", "engine": "chromium", "storage": "Forkium Safe Storage", "paths": {"` + runtime.GOOS + `": "/forkium/Default/"}},
+		{"key": "firefox", "name": "Firefox", "engine": "firefox", "paths": {"` + runtime.GOOS + `": "/custom/firefox/"}}
+	]`
+	require.NoError(t, os.WriteFile(filename, []byte(config), 0o600))
+	builtin := firefoxList["firefox"]
+	t.Cleanup(func() {
+		delete(chromiumList, "forkium")
+		firefoxList["firefox"] = builtin
+	})
+
+	require.NoError(t, LoadBrowserConfig(filename))
+	c, ok := chromiumList["forkium"]
+	require.True(t, ok)
+	assert.Equal(t, "Forkium", c.name)
+	assert.Equal(t, "Forkium Safe Storage", c.storage)
+	assert.Equal(t, filepath.FromSlash("/forkium/Default/"), c.profilePath)
+	assert.Equal(t, types.DefaultChromiumTypes, c.dataTypes)
+	assert.Equal(t, filepath.FromSlash("/custom/firefox/"), firefoxList["firefox"].profilePath)
+	assert.Contains(t, ListBrowsers(), "forkium")
+
+	assert.Error(t, LoadBrowserConfig(filepath.Join(dir, "missing.json")))
+}
+
+func TestExpandPath(t *testing.T) {
+	t.Setenv("HBD_TEST_DIR", "/data")
+	assert.Equal(t, filepath.FromSlash(homeDir+"/.config/fork/"), expandPath("~/.config/fork/"))
+	assert.Equal(t, filepath.FromSlash("/data/fork"), expandPath("${HBD_TEST_DIR}/fork"))
+	assert.Equal(t, filepath.FromSlash("/opt/~fork"), expandPath("/opt/~fork"))
+}
+
+func TestParseBrowserConfigsLayout(t *testing.T) {
+	configs, err := parseBrowserConfigs([]byte(`[
+		{"key": "forkium", "engine": "chromium", "localState": "Local State", "profileLayout": "single"},
+		{"key": "forkfox", "engine": "firefox"}
+	]`))
+	require.NoError(t, err)
+	assert.Equal(t, layoutSingle, configs[0].ProfileLayout)
+	assert.Equal(t, "Local State", configs[0].LocalState)
+	assert.Equal(t, layoutMulti, configs[1].ProfileLayout)
+
+	_, err = parseBrowserConfigs([]byte(`[{"key": "forkium", "engine": "chromium", "profileLayout": "flat"}]`))
+	assert.ErrorContains(t, err, "unknown profile layout")
+	_, err = parseBrowserConfigs([]byte(`[{"key": "forkfox", "engine": "firefox", "localState": "Local State"}]`))
+	assert.ErrorContains(t, err, "only for chromium")
+}
+
+func TestEmbeddedRegistryArc(t *testing.T) {
+	configs, err := parseBrowserConfigs(embeddedRegistry)
+	require.NoError(t, err)
+	for _, c := range configs {
+		if c.Key == "arc" {
+			assert.Equal(t, engineChromium, c.Engine)
+			assert.NotEmpty(t, c.Paths["windows"])
+			assert.NotEmpty(t, c.Paths["darwin"])
+			return
+		}
+	}
+	t.Fatal("arc is not in the embedded registry")
+}
+
+func TestRegisterBrowserLayout(t *testing.T) {
+	// a fork whose profile folder holds its Local State, the other folders aren't its profiles
+	userData := t.TempDir()
+	writeFiles(t, userData, "Main/Local State", "Main/Cookies", "Other/Cookies")
+	config := BrowserConfig{
+		Key: "forkium", Name: "Forkium", Engine: engineChromium, LocalState: "Local State", ProfileLayout: layoutSingle,
+		Paths: map[string]string{runtime.GOOS: filepath.Join(userData, "Main") + "/"},
+	}
+	require.True(t, registerBrowser(config))
+	t.Cleanup(func() { delete(chromiumList, "forkium") })
+
+	browsers := pickChromium("forkium", "")
+	require.Len(t, browsers, 1)
+	assert.Equal(t, filepath.Join(userData, "Main"), browsers[0].ProfilePath())
+	assert.Equal(t, filepath.Join(userData, "Main", "Local State"), browsers[0].ItemPaths()[types.ChromiumKey])
+}
+
+func TestPickFirefoxFamily(t *testing.T) {
+	firefoxDir, forkDir := t.TempDir(), t.TempDir()
+	writeFiles(t, firefoxDir, "abcd.default-release/cookies.sqlite")
+	writeFiles(t, forkDir, "efgh.default/cookies.sqlite")
+	for dir, profile := range map[string]string{firefoxDir: "abcd.default-release", forkDir: "efgh.default"} {
+		ini := "[Profile0]\nName=default\nIsRelative=1\nPath=" + profile + "\nDefault=1\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "profiles.ini"), []byte(ini), 0o600))
+	}
+	builtin := firefoxList
+	firefoxList = map[string]browserInfo{
+		"firefox": {name: "Firefox", profilePath: firefoxDir, dataTypes: types.DefaultFirefoxTypes},
+		"forkfox": {name: "Forkfox", profilePath: forkDir, dataTypes: types.DefaultFirefoxTypes},
+	}
+	t.Cleanup(func() { firefoxList = builtin })
+
+	// -b firefox picks the forks too, the profile path is read as firefox
+	assert.Len(t, pickFirefox("firefox", ""), 2)
+	browsers := pickFirefox("firefox", forkDir)
+	require.Len(t, browsers, 1)
+	name, _ := browsers[0].Profile()
+	assert.Equal(t, "firefox", name)
+	assert.Len(t, pickFirefox("forkfox", ""), 1)
+}
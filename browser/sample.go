@@ -0,0 +1,32 @@
+package browser
+
+import (
+	"github.com/moond4rk/hackbrowserdata/browserdata"
+)
+
+// SampleReport is the --sample-decrypt result of a browser profile, Error is set if the
+// profile can't be read, eg: the master key of firefox can't be decrypted.
+type SampleReport struct {
+	Browser string               `json:"browser"`
+	Profile string               `json:"profile"`
+	Error   string               `json:"error,omitempty"`
+	Items   []browserdata.Sample `json:"items"`
+}
+
+// SampleDecrypt decrypts the first password and cookie of every profile and reports the
+// decryption method and where the key was read from, the values aren't returned.
+func SampleDecrypt(browsers []Browser) []SampleReport {
+	reports := make([]SampleReport, 0, len(browsers))
+	for _, b := range browsers {
+		name, profile := b.Profile()
+		r := SampleReport{Browser: name, Profile: profile, Items: []browserdata.Sample{}}
+		data, err := b.BrowsingData(true)
+		if err != nil {
+			r.Error = err.Error()
+		} else {
+			r.Items = data.Samples()
+		}
+		reports = append(reports, r)
+	}
+	return reports
+}
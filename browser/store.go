@@ -0,0 +1,52 @@
+package browser
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/moond4rk/hackbrowserdata/log"
+	"github.com/moond4rk/hackbrowserdata/utils/fileutil"
+)
+
+// resolveProfilePath returns the first existing folder of the profile candidates of the browser,
+// the default profile path is returned if none exists.
+func resolveProfilePath(b browserInfo) string {
+	for _, p := range profileCandidates(b.profilePath) {
+		if !fileutil.IsDirExists(filepath.Clean(p)) {
+			continue
+		}
+		if p != b.profilePath {
+			log.Warnf("use profile folder %s of browser %s", p, b.name)
+		}
+		return p
+	}
+	return b.profilePath
+}
+
+// profileCandidates returns the profile path and the same path in every Microsoft Store app,
+// the Store apps keep %APPDATA% under %LOCALAPPDATA%\Packages\<package>\LocalCache\, eg:
+// Packages\Mozilla.Firefox_n80bbvh6b1yt2\LocalCache\Roaming\Mozilla\Firefox\Profiles\
+func profileCandidates(profilePath string) []string {
+	candidates := []string{profilePath}
+	if storeAppsDir == "" {
+		return candidates
+	}
+	rel, ok := strings.CutPrefix(filepath.ToSlash(profilePath), filepath.ToSlash(homeDir)+"/AppData/")
+	if !ok {
+		return candidates
+	}
+	matches, err := filepath.Glob(filepath.Join(storeAppsDir, "*", "LocalCache", filepath.FromSlash(rel)))
+	if err != nil {
+		return candidates
+	}
+	sort.Strings(matches)
+	for _, m := range matches {
+		// keep the trailing slash of the profile folders
+		if strings.HasSuffix(profilePath, "/") {
+			m += string(filepath.Separator)
+		}
+		candidates = append(candidates, m)
+	}
+	return candidates
+}
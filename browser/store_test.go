@@ -0,0 +1,32 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveProfilePath_StoreApp(t *testing.T) {
+	home := t.TempDir()
+	oldHome, oldStore := homeDir, storeAppsDir
+	homeDir, storeAppsDir = home, filepath.Join(home, "AppData", "Local", "Packages")
+	t.Cleanup(func() { homeDir, storeAppsDir = oldHome, oldStore })
+
+	profilePath := home + "/AppData/Roaming/Mozilla/Firefox/Profiles/"
+	storePath := filepath.Join(storeAppsDir, "Mozilla.Firefox_n80bbvh6b1yt2", "LocalCache", "Roaming", "Mozilla", "Firefox", "Profiles")
+	require.NoError(t, os.MkdirAll(storePath, 0o750))
+
+	b := browserInfo{name: firefoxName, profilePath: profilePath}
+	assert.Equal(t, []string{profilePath, storePath + string(filepath.Separator)}, profileCandidates(profilePath))
+	assert.Equal(t, storePath+string(filepath.Separator), resolveProfilePath(b))
+
+	// the desktop install is preferred over the store app
+	require.NoError(t, os.MkdirAll(filepath.FromSlash(profilePath), 0o750))
+	assert.Equal(t, profilePath, resolveProfilePath(b))
+
+	storeAppsDir = ""
+	assert.Equal(t, []string{profilePath}, profileCandidates(profilePath))
+}
@@ -0,0 +1,174 @@
+package browser
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/moond4rk/hackbrowserdata/browser/chromium"
+	"github.com/moond4rk/hackbrowserdata/browser/firefox"
+	"github.com/moond4rk/hackbrowserdata/log"
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/fileutil"
+)
+
+// sqliteSidecars are the files sqlite writes next to the database, a new history row
+// is usually written to the wal file only.
+var sqliteSidecars = []string{"-wal", "-journal"}
+
+// watchedItem is an item file of a browser, a folder item is watched with its files
+type watchedItem struct {
+	browser int
+	item    types.DataType
+	path    string
+	isDir   bool
+}
+
+// match reports whether the changed file is the item file, its sqlite sidecar or a file of
+// the item folder.
+func (w watchedItem) match(name string) bool {
+	if name == w.path || w.isDir && filepath.Dir(name) == w.path {
+		return true
+	}
+	for _, suffix := range sqliteSidecars {
+		if name == w.path+suffix {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch watches the item files of the browsers until ctx is done, and calls onChange with the
+// browser and its items whose files changed. The browsers write frequently, so onChange is
+// called once the files of the browser stay unchanged for quiet.
+func Watch(ctx context.Context, browsers []Browser, quiet time.Duration, onChange func(Browser, []types.DataType)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	items := watchedItems(browsers)
+	dirs := make(map[string]bool)
+	for _, w := range items {
+		dir := filepath.Dir(w.path)
+		if w.isDir {
+			dir = w.path
+		}
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+		if err := watcher.Add(dir); err != nil {
+			log.Warnf("watch %s error: %v", dir, err)
+		}
+	}
+
+	pending := make(map[int]map[types.DataType]bool)
+	timers := make(map[int]*time.Timer)
+	defer func() {
+		for _, t := range timers {
+			t.Stop()
+		}
+	}()
+	ready := make(chan int)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Warnf("watch error: %v", err)
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			for _, w := range items {
+				if !w.match(event.Name) {
+					continue
+				}
+				if pending[w.browser] == nil {
+					pending[w.browser] = make(map[types.DataType]bool)
+				}
+				pending[w.browser][w.item] = true
+				if t, ok := timers[w.browser]; ok {
+					t.Reset(quiet)
+					continue
+				}
+				i := w.browser
+				timers[i] = time.AfterFunc(quiet, func() {
+					select {
+					case ready <- i:
+					case <-ctx.Done():
+					}
+				})
+			}
+		case i := <-ready:
+			// a timer reset while it fired reports the same browser again, it has nothing pending
+			if len(pending[i]) == 0 {
+				continue
+			}
+			changed := changedItems(pending[i])
+			delete(pending, i)
+			onChange(browsers[i], changed)
+		}
+	}
+}
+
+// watchedItems returns the item files of the browsers, the keys are rewritten by the browser
+// without new data, eg: Local State, they aren't watched.
+func watchedItems(browsers []Browser) []watchedItem {
+	var items []watchedItem
+	for i, b := range browsers {
+		paths := b.ItemPaths()
+		for _, item := range types.SortedKeys(paths) {
+			if item == types.ChromiumKey || item == types.FirefoxKey4 || paths[item] == "" {
+				continue
+			}
+			p := filepath.Clean(paths[item])
+			items = append(items, watchedItem{browser: i, item: item, path: p, isDir: fileutil.IsDirExists(p)})
+		}
+	}
+	return items
+}
+
+// changedItems returns the changed items with the items reading them as companion, and the
+// companions of those, so an item is exported again with every file it reads.
+func changedItems(changed map[types.DataType]bool) []types.DataType {
+	items := make(map[types.DataType]bool, len(changed))
+	for item := range changed {
+		items[item] = true
+		for owner, companions := range itemCompanions {
+			for _, companion := range companions {
+				if companion == item {
+					items[owner] = true
+				}
+			}
+		}
+	}
+	for _, item := range types.SortedKeys(items) {
+		for _, companion := range itemCompanions[item] {
+			items[companion] = true
+		}
+	}
+	return types.SortedKeys(items)
+}
+
+// SelectItems returns the browser limited to the items, eg: to export the changed items again,
+// the browsers which can't be limited are returned as is.
+func SelectItems(b Browser, items []types.DataType) Browser {
+	switch v := b.(type) {
+	case *chromium.Chromium:
+		return v.SelectItems(items)
+	case *firefox.Firefox:
+		return v.SelectItems(items)
+	}
+	return b
+}
@@ -0,0 +1,85 @@
+package browser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/browserdata"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+type fakeBrowser struct {
+	paths map[types.DataType]string
+	dir   string
+}
+
+func (f fakeBrowser) Name() string { return "chrome_default" }
+
+func (f fakeBrowser) Profile() (string, string) { return "Chrome", "Default" }
+
+func (f fakeBrowser) ItemPaths() map[types.DataType]string { return f.paths }
+
+func (f fakeBrowser) ProfilePath() string { return f.dir }
+
+func (f fakeBrowser) BrowsingData(_ bool) (*browserdata.BrowserData, error) {
+	return browserdata.New(nil), nil
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	history := filepath.Join(dir, "History")
+	bookmarks := filepath.Join(dir, "Bookmarks")
+	localState := filepath.Join(dir, "Local State")
+	require.NoError(t, os.WriteFile(history, []byte("rows"), 0o600))
+	require.NoError(t, os.WriteFile(bookmarks, []byte("{}"), 0o600))
+	require.NoError(t, os.WriteFile(localState, []byte("{}"), 0o600))
+	b := fakeBrowser{paths: map[types.DataType]string{
+		types.ChromiumHistory:  history,
+		types.ChromiumDownload: history,
+		types.ChromiumBookmark: bookmarks,
+		types.ChromiumKey:      localState,
+	}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	changed := make(chan []types.DataType, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- Watch(ctx, []Browser{b}, 50*time.Millisecond, func(_ Browser, items []types.DataType) { changed <- items })
+	}()
+
+	// the key file is ignored
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, os.WriteFile(localState, []byte(`{"os_crypt":{}}`), 0o600))
+	time.Sleep(200 * time.Millisecond)
+	assert.Empty(t, changed)
+
+	// rapid writes to the wal file are reported once with the items of the file
+	for i := 0; i < 3; i++ {
+		require.NoError(t, os.WriteFile(history+"-wal", make([]byte, i+1), 0o600))
+	}
+	select {
+	case items := <-changed:
+		assert.Equal(t, []types.DataType{types.ChromiumHistory, types.ChromiumDownload, types.ChromiumArchivedHistory}, items)
+	case <-ctx.Done():
+		t.Fatal("change not reported")
+	}
+	time.Sleep(200 * time.Millisecond)
+	assert.Empty(t, changed)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestChangedItems(t *testing.T) {
+	assert.Equal(t, []types.DataType{types.ChromiumBookmark}, changedItems(map[types.DataType]bool{types.ChromiumBookmark: true}))
+	// the companion is exported again with the item reading it
+	assert.Equal(t, []types.DataType{types.ChromiumNetworkState, types.ChromiumTransportSecurity},
+		changedItems(map[types.DataType]bool{types.ChromiumTransportSecurity: true}))
+}
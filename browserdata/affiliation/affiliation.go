@@ -0,0 +1,110 @@
+package affiliation
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	// import sqlite3 driver
+	_ "modernc.org/sqlite"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/log"
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/sqliteutil"
+	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
+)
+
+func init() {
+	extractor.RegisterExtractor(types.ChromiumAffiliation, extractor.Info{
+		Description: "the apps and sites which share their passwords",
+		Sources:     []string{"Affiliation Database"},
+	}, func() extractor.Extractor {
+		return new(ChromiumAffiliation)
+	})
+}
+
+// ChromiumAffiliation is the android apps and websites which share their passwords, the
+// password manager fills the password of a facet in every facet of the same group. The
+// groups are fetched from the affiliation service for the saved passwords.
+type ChromiumAffiliation []facet
+
+type facet struct {
+	// GroupID is the id of the equivalence class, the facets of a group share the passwords
+	GroupID  int64
+	FacetURI string
+	// IsAndroid reports whether the facet is an android app, eg: android://hash@com.example.app
+	IsAndroid   bool
+	DisplayName string
+	MainDomain  string
+	// LastUpdateTime is when the group was fetched from the affiliation service
+	LastUpdateTime time.Time
+}
+
+// @https://source.chromium.org/chromium/chromium/src/+/main:components/affiliations/core/browser/affiliation_database.cc
+const (
+	queryChromiumAffiliation = `SELECT m.set_id, m.facet_uri, COALESCE(m.facet_display_name, ''), %s, COALESCE(c.last_update_time, 0)
+		FROM eq_class_members m LEFT JOIN eq_classes c ON c.id = m.set_id`
+	// chromiumMainDomainColumn is the main domain of the facet, it's added by newer versions
+	chromiumMainDomainColumn = "main_domain"
+	androidFacetPrefix       = "android://"
+)
+
+func (c *ChromiumAffiliation) Extract(_ *extractor.Session) error {
+	db, err := sql.Open("sqlite", types.ChromiumAffiliation.DSN())
+	if err != nil {
+		return err
+	}
+	defer types.ChromiumAffiliation.RemoveTemp()
+	defer db.Close()
+
+	if ok, err := sqliteutil.TableExists(db, "eq_class_members"); err != nil || !ok {
+		log.Debugf("chromium affiliation database has no members, skip affiliation")
+		return nil
+	}
+	mainDomain := "''"
+	if ok, err := sqliteutil.ColumnExists(db, "eq_class_members", chromiumMainDomainColumn); err == nil && ok {
+		mainDomain = "COALESCE(m." + chromiumMainDomainColumn + ", '')"
+	}
+	rows, err := db.Query(extractor.LimitQuery(fmt.Sprintf(queryChromiumAffiliation, mainDomain)))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			groupID, lastUpdate               int64
+			facetURI, displayName, mainDomain string
+		)
+		if err := rows.Scan(&groupID, &facetURI, &displayName, &mainDomain, &lastUpdate); err != nil {
+			log.Warnf("scan chromium affiliation error: %v", err)
+			continue
+		}
+		*c = append(*c, facet{
+			GroupID:        groupID,
+			FacetURI:       facetURI,
+			IsAndroid:      strings.HasPrefix(facetURI, androidFacetPrefix),
+			DisplayName:    displayName,
+			MainDomain:     mainDomain,
+			LastUpdateTime: typeutil.TimeEpoch(lastUpdate),
+		})
+	}
+	// the facets of a group are written together
+	sort.SliceStable(*c, func(i, j int) bool {
+		if (*c)[i].GroupID != (*c)[j].GroupID {
+			return (*c)[i].GroupID < (*c)[j].GroupID
+		}
+		return (*c)[i].FacetURI < (*c)[j].FacetURI
+	})
+	return rows.Err()
+}
+
+func (c *ChromiumAffiliation) Name() string {
+	return "affiliation"
+}
+
+func (c *ChromiumAffiliation) Len() int {
+	return len(*c)
+}
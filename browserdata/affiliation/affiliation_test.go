@@ -0,0 +1,60 @@
+package affiliation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/testutil"
+)
+
+func TestChromiumAffiliation_Extract(t *testing.T) {
+	testutil.CreateSQLite(t, types.ChromiumAffiliation,
+		`CREATE TABLE eq_classes (id INTEGER PRIMARY KEY, last_update_time INTEGER)`,
+		`CREATE TABLE eq_class_members (id INTEGER PRIMARY KEY, facet_uri LONGVARCHAR UNIQUE NOT NULL, facet_display_name VARCHAR, facet_icon_url VARCHAR, set_id INTEGER NOT NULL, main_domain VARCHAR)`,
+		`INSERT INTO eq_classes VALUES (1, 13345000000000000), (2, 0)`,
+		`INSERT INTO eq_class_members VALUES
+			(1, 'https://example.com', 'Example', NULL, 2, 'example.com'),
+			(2, 'https://www.example.org', NULL, NULL, 1, 'example.org'),
+			(3, 'android://hash@org.example.app', 'Example App', NULL, 1, NULL)`,
+	)
+
+	var c ChromiumAffiliation
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 3)
+	assert.Equal(t, int64(1), c[0].GroupID)
+	assert.Equal(t, "android://hash@org.example.app", c[0].FacetURI)
+	assert.True(t, c[0].IsAndroid)
+	assert.Equal(t, "Example App", c[0].DisplayName)
+	assert.Equal(t, "", c[0].MainDomain)
+	assert.Equal(t, c[0].LastUpdateTime, c[1].LastUpdateTime)
+	assert.Equal(t, "https://www.example.org", c[1].FacetURI)
+	assert.False(t, c[1].IsAndroid)
+	assert.Equal(t, "example.org", c[1].MainDomain)
+	assert.Equal(t, int64(2), c[2].GroupID)
+	assert.Equal(t, "Example", c[2].DisplayName)
+}
+
+func TestChromiumAffiliation_ExtractOldSchema(t *testing.T) {
+	testutil.CreateSQLite(t, types.ChromiumAffiliation,
+		`CREATE TABLE eq_classes (id INTEGER PRIMARY KEY, last_update_time INTEGER)`,
+		`CREATE TABLE eq_class_members (id INTEGER PRIMARY KEY, facet_uri LONGVARCHAR UNIQUE NOT NULL, facet_display_name VARCHAR, facet_icon_url VARCHAR, set_id INTEGER NOT NULL)`,
+		`INSERT INTO eq_class_members VALUES (1, 'https://example.com', NULL, NULL, 1)`,
+	)
+
+	var c ChromiumAffiliation
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 1)
+	assert.Equal(t, "", c[0].MainDomain)
+	assert.Equal(t, "https://example.com", c[0].FacetURI)
+}
+
+func TestChromiumAffiliation_ExtractNoMembers(t *testing.T) {
+	testutil.CreateSQLite(t, types.ChromiumAffiliation, `CREATE TABLE meta (key LONGVARCHAR NOT NULL UNIQUE PRIMARY KEY, value LONGVARCHAR)`)
+
+	var c ChromiumAffiliation
+	require.NoError(t, c.Extract(nil))
+	assert.Equal(t, 0, c.Len())
+}
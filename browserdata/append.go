@@ -0,0 +1,78 @@
+package browserdata
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// jsonlFormat writes the records as json lines, it's the json format of the appended output
+const jsonlFormat = "jsonl"
+
+// appendOutput appends the records to the existing output files instead of replacing them
+var appendOutput bool
+
+// appendableFormats are the formats which stay valid when a run is appended to another,
+// a json array or the EditThisCookie json would be broken.
+var appendableFormats = map[string]bool{csvFormat: true, jsonlFormat: true, headerFormat: true}
+
+// SetAppend appends the output of the run to the existing files, eg: for the collectors which
+// accumulate the data of periodic runs. Every format of flag must be appendable, jsonl
+// instead of json.
+func SetAppend(enabled bool, flag string) error {
+	if enabled {
+		for _, format := range ParseFormats(flag) {
+			if !appendableFormats[format] {
+				return fmt.Errorf("format %s can't be appended to, use csv or %s", format, jsonlFormat)
+			}
+		}
+	}
+	appendOutput = enabled
+	return nil
+}
+
+// appendedSize returns the size of the output file before the records are appended, 0 for a new file
+func appendedSize(f *os.File) int64 {
+	if !appendOutput {
+		return 0
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// discardOutput removes the output file after a failed write, the file appended to is
+// truncated to its previous size instead, so the records of the former runs are kept.
+func discardOutput(f *os.File, size int64) {
+	if size > 0 {
+		_ = f.Truncate(size)
+		_ = f.Close()
+		return
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+}
+
+// headerSkipper drops the first line written to w, the header of the csv and its BOM
+type headerSkipper struct {
+	w       io.Writer
+	skipped bool
+}
+
+func (h *headerSkipper) Write(p []byte) (int, error) {
+	if h.skipped {
+		return h.w.Write(p)
+	}
+	i := bytes.IndexByte(p, '\n')
+	if i < 0 {
+		return len(p), nil
+	}
+	h.skipped = true
+	if _, err := h.w.Write(p[i+1:]); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
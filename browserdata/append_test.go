@@ -0,0 +1,52 @@
+package browserdata
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func TestSetAppend(t *testing.T) {
+	defer func() { _ = SetAppend(false, "") }()
+	require.NoError(t, SetAppend(true, "csv,jsonl"))
+	assert.True(t, appendOutput)
+	assert.Error(t, SetAppend(true, "json"))
+	assert.Error(t, SetAppend(true, "all"))
+	require.NoError(t, SetAppend(false, "json"))
+	assert.False(t, appendOutput)
+}
+
+func TestBrowserData_OutputAppend(t *testing.T) {
+	require.NoError(t, SetAppend(true, "csv,jsonl"))
+	defer func() { _ = SetAppend(false, "") }()
+	dir := t.TempDir()
+	for _, value := range []string{"first", "second"} {
+		bd := &BrowserData{
+			extractors: map[types.DataType]extractor.Extractor{
+				types.ChromiumCookie: newTestCookies(t, value),
+			},
+		}
+		require.NoError(t, bd.Output(dir, "chrome_default", "csv,jsonl"))
+	}
+
+	csv, err := os.ReadFile(filepath.Join(dir, "chrome_default_cookie.csv"))
+	require.NoError(t, err)
+	header := "\ufeffHost,Path,KeyName,Value"
+	assert.Equal(t, 1, strings.Count(string(csv), header), "the header is written by the first run only")
+	assert.Contains(t, string(csv), "first")
+	assert.Contains(t, string(csv), "second")
+
+	jsonl, err := os.ReadFile(filepath.Join(dir, "chrome_default_cookie.jsonl"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(jsonl)), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"Value":"first"`)
+	assert.Contains(t, lines[1], `"Value":"second"`)
+}
@@ -0,0 +1,55 @@
+package browserdata
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+)
+
+// base64Fields are the fields base64 encoded in the csv output, the binary values,
+// eg: a cookie value which failed to decrypt, break the quoting and lines of csv.
+var base64Fields []string
+
+// base64Suffix is added to the csv header of an encoded field, eg: Value_b64
+const base64Suffix = "_b64"
+
+// SetBase64Fields sets the fields base64 encoded in the csv output by a comma separated list,
+// eg: value,password, the names are case-insensitive and the items without the field are unchanged.
+func SetBase64Fields(list string) {
+	base64Fields = splitFields(list)
+}
+
+// isBase64Field reports whether the field is base64 encoded in the csv output
+func isBase64Field(name string) bool {
+	for _, f := range base64Fields {
+		if strings.EqualFold(f, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeBase64Fields returns the records with the base64 fields encoded and renamed with base64Suffix
+func encodeBase64Fields(rows reflect.Value) reflect.Value {
+	elemType, ok := recordType(rows)
+	if !ok {
+		return rows
+	}
+	fields := make([]viewField, 0, elemType.NumField())
+	for i := 0; i < elemType.NumField(); i++ {
+		f := elemType.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		field := viewField{StructField: reflect.StructField{Name: f.Name, Type: f.Type, Tag: f.Tag}, value: copyField(i)}
+		if f.Type.Kind() == reflect.String && isBase64Field(f.Name) {
+			index, fieldType := i, f.Type
+			field.Tag = reflect.StructTag(`csv:"` + f.Name + base64Suffix + `"`)
+			field.value = func(record reflect.Value) reflect.Value {
+				return reflect.ValueOf(base64.StdEncoding.EncodeToString([]byte(record.Field(index).String()))).Convert(fieldType)
+			}
+		}
+		fields = append(fields, field)
+	}
+	return makeView(rows, fields)
+}
@@ -0,0 +1,43 @@
+package browserdata
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutPutter_WriteBase64(t *testing.T) {
+	defer SetBase64Fields("")
+	defer SetFields("")
+	raw := "v10\x8f\"\n,\xff"
+	SetBase64Fields("VALUE,password")
+
+	var buf bytes.Buffer
+	require.NoError(t, newOutPutter("csv").Write(newTestCookies(t, raw), &buf))
+	rows, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), "\ufeff"))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Contains(t, rows[0], "Value_b64")
+	assert.NotContains(t, rows[0], "Value")
+	assert.Contains(t, rows[1], base64.StdEncoding.EncodeToString([]byte(raw)))
+	assert.Contains(t, rows[1], "example.com")
+
+	// the fields are encoded in the projected records as well
+	SetFields("host,value")
+	buf.Reset()
+	require.NoError(t, newOutPutter("csv").Write(newTestCookies(t, raw), &buf))
+	assert.Equal(t, "\ufeffHost,Value_b64\nexample.com,"+base64.StdEncoding.EncodeToString([]byte(raw))+"\n", buf.String())
+
+	// json is not encoded
+	buf.Reset()
+	require.NoError(t, newOutPutter("json").Write(newTestCookies(t, "plain"), &buf))
+	var got []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, "plain", got[0]["Value"])
+}
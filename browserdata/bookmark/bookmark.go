@@ -2,7 +2,8 @@ package bookmark
 
 import (
 	"database/sql"
-	"os"
+	"errors"
+	"fmt"
 	"sort"
 	"time"
 
@@ -17,10 +18,16 @@ import (
 )
 
 func init() {
-	extractor.RegisterExtractor(types.ChromiumBookmark, func() extractor.Extractor {
+	extractor.RegisterExtractor(types.ChromiumBookmark, extractor.Info{
+		Description: "the bookmarks",
+		Sources:     []string{"Bookmarks"},
+	}, func() extractor.Extractor {
 		return new(ChromiumBookmark)
 	})
-	extractor.RegisterExtractor(types.FirefoxBookmark, func() extractor.Extractor {
+	extractor.RegisterExtractor(types.FirefoxBookmark, extractor.Info{
+		Description: "the bookmarks",
+		Sources:     []string{"places.sqlite"},
+	}, func() extractor.Extractor {
 		return new(FirefoxBookmark)
 	})
 }
@@ -33,20 +40,28 @@ type bookmark struct {
 	Type      string
 	URL       string
 	DateAdded time.Time
+	// LastVisited is the last visit of the bookmark from the meta_info of Chromium, zero if it's unknown
+	LastVisited time.Time
+	// MetaInfo is the meta_info of the Chromium node, eg: the sync metadata, it's only in the json
+	MetaInfo map[string]string `csv:"-" json:",omitempty"`
 }
 
-func (c *ChromiumBookmark) Extract(_ []byte) error {
-	bookmarks, err := fileutil.ReadFile(types.ChromiumBookmark.TempFilename())
+func (c *ChromiumBookmark) Extract(_ *extractor.Session) error {
+	defer types.ChromiumBookmark.RemoveTemp()
+	r, err := readChromiumBookmarks()
 	if err != nil {
 		return err
 	}
-	defer os.Remove(types.ChromiumBookmark.TempFilename())
-	r := gjson.Parse(bookmarks)
+	if verifyChecksum {
+		if result := VerifyChromiumChecksum(r); !result.Valid() {
+			log.Warnf("chromium bookmarks checksum mismatch, file may be tampered, stored %s, computed %s", result.Stored, result.Computed)
+		}
+	}
 	if r.Exists() {
 		roots := r.Get("roots")
 		roots.ForEach(func(key, value gjson.Result) bool {
 			getBookmarkChildren(value, c)
-			return true
+			return !extractor.ReachedMaxRows(len(*c))
 		})
 	}
 
@@ -56,6 +71,62 @@ func (c *ChromiumBookmark) Extract(_ []byte) error {
 	return nil
 }
 
+var (
+	errInvalidBookmarks = errors.New("bookmarks file is not valid json")
+	errMissingRoots     = errors.New("bookmarks file is missing roots")
+)
+
+// chromiumBookmarkRoots are the roots Chrome always writes, a file without one of them was
+// cut while being written, eg: a valid json object with an empty roots.
+var chromiumBookmarkRoots = []string{"bookmark_bar", "other"}
+
+// optionalBookmarkRoots are the roots missing from the files of some forks and old versions,
+// the file is still read without them.
+var optionalBookmarkRoots = []string{"synced"}
+
+// readChromiumBookmarks reads the Bookmarks file, and falls back to Bookmarks.bak
+// which Chrome keeps when the main file is corrupted or being written.
+func readChromiumBookmarks() (gjson.Result, error) {
+	filename := types.ChromiumBookmark.TempFilename()
+	r, err := parseChromiumBookmarks(filename)
+	if err == nil {
+		log.Debugf("read chromium bookmarks from %s", filename)
+		return r, nil
+	}
+
+	backupFilename := types.ChromiumBookmark.TempBackupFilename()
+	backup, bakErr := parseChromiumBookmarks(backupFilename)
+	if bakErr != nil {
+		return gjson.Result{}, err
+	}
+	log.Warnf("read chromium bookmarks error: %v, use backup file %s", err, backupFilename)
+	return backup, nil
+}
+
+// parseChromiumBookmarks parses the bookmarks file and checks it has every root
+func parseChromiumBookmarks(filename string) (gjson.Result, error) {
+	bookmarks, err := fileutil.ReadFile(filename)
+	if err != nil {
+		return gjson.Result{}, err
+	}
+	if !gjson.Valid(bookmarks) {
+		return gjson.Result{}, errInvalidBookmarks
+	}
+	r := gjson.Parse(bookmarks)
+	roots := r.Get("roots")
+	for _, root := range chromiumBookmarkRoots {
+		if !roots.Get(root).IsObject() {
+			return gjson.Result{}, fmt.Errorf("%w: %s", errMissingRoots, root)
+		}
+	}
+	for _, root := range optionalBookmarkRoots {
+		if !roots.Get(root).IsObject() {
+			log.Warnf("chromium bookmarks %s has no %s root", filename, root)
+		}
+	}
+	return r, nil
+}
+
 const (
 	bookmarkID       = "id"
 	bookmarkAdded    = "date_added"
@@ -63,6 +134,7 @@ const (
 	bookmarkName     = "name"
 	bookmarkType     = "type"
 	bookmarkChildren = "children"
+	bookmarkMetaInfo = "meta_info"
 )
 
 func getBookmarkChildren(value gjson.Result, w *ChromiumBookmark) (children gjson.Result) {
@@ -75,7 +147,8 @@ func getBookmarkChildren(value gjson.Result, w *ChromiumBookmark) (children gjso
 		URL:       value.Get(bookmarkURL).String(),
 		DateAdded: typeutil.TimeEpoch(value.Get(bookmarkAdded).Int()),
 	}
-	if nodeType.Exists() {
+	bm.MetaInfo, bm.LastVisited = parseMetaInfo(value.Get(bookmarkMetaInfo))
+	if nodeType.Exists() && !extractor.ReachedMaxRows(len(*w)) {
 		bm.Type = nodeType.String()
 		*w = append(*w, bm)
 		if children.Exists() && children.IsArray() {
@@ -98,22 +171,23 @@ func (c *ChromiumBookmark) Len() int {
 type FirefoxBookmark []bookmark
 
 const (
-	queryFirefoxBookMark = `SELECT id, url, type, dateAdded, title FROM (SELECT * FROM moz_bookmarks INNER JOIN moz_places ON moz_bookmarks.fk=moz_places.id)`
+	// the folders and separators have no place, they are kept by the left join, the root folder is skipped
+	queryFirefoxBookMark = `SELECT b.id, COALESCE(p.url, ''), b.type, b.dateAdded, COALESCE(b.title, '') FROM moz_bookmarks b LEFT JOIN moz_places p ON b.fk = p.id WHERE b.parent > 0`
 	closeJournalMode     = `PRAGMA journal_mode=off`
 )
 
-func (f *FirefoxBookmark) Extract(_ []byte) error {
-	db, err := sql.Open("sqlite", types.FirefoxBookmark.TempFilename())
+func (f *FirefoxBookmark) Extract(_ *extractor.Session) error {
+	db, err := sql.Open("sqlite", types.FirefoxBookmark.DSN())
 	if err != nil {
 		return err
 	}
-	defer os.Remove(types.FirefoxBookmark.TempFilename())
+	defer types.FirefoxBookmark.RemoveTemp()
 	defer db.Close()
 	_, err = db.Exec(closeJournalMode)
 	if err != nil {
 		log.Errorf("close journal mode error: %v", err)
 	}
-	rows, err := db.Query(queryFirefoxBookMark)
+	rows, err := db.Query(extractor.LimitQuery(queryFirefoxBookMark))
 	if err != nil {
 		return err
 	}
@@ -126,6 +200,9 @@ func (f *FirefoxBookmark) Extract(_ []byte) error {
 		if err = rows.Scan(&id, &url, &bt, &dateAdded, &title); err != nil {
 			log.Errorf("scan bookmark error: %v", err)
 		}
+		if skipFirefoxPlace(bt, url) {
+			continue
+		}
 		*f = append(*f, bookmark{
 			ID:        id,
 			Name:      title,
@@ -147,12 +224,3 @@ func (f *FirefoxBookmark) Name() string {
 func (f *FirefoxBookmark) Len() int {
 	return len(*f)
 }
-
-func linkType(a int64) string {
-	switch a {
-	case 1:
-		return "url"
-	default:
-		return "folder"
-	}
-}
@@ -0,0 +1,171 @@
+package bookmark
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
+)
+
+const testChromiumBookmarks = `{
+   "checksum": "",
+   "roots": {
+      "bookmark_bar": {
+         "children": [ {
+            "date_added": "13312345678901234",
+            "id": "5",
+            "name": "GitHub",
+            "type": "url",
+            "url": "https://github.com/"
+         } ],
+         "date_added": "13312345678900000",
+         "id": "1",
+         "name": "Bookmarks bar",
+         "type": "folder"
+      },
+      "other": {
+         "children": [  ],
+         "date_added": "13312345678900000",
+         "id": "2",
+         "name": "Other bookmarks",
+         "type": "folder"
+      },
+      "synced": {
+         "children": [  ],
+         "date_added": "13312345678900000",
+         "id": "3",
+         "name": "Mobile bookmarks",
+         "type": "folder"
+      }
+   },
+   "version": 1
+}`
+
+func writeChromiumBookmarks(t *testing.T, content, backup string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(types.ChromiumBookmark.TempFilename(), []byte(content), 0o600))
+	if backup != "" {
+		require.NoError(t, os.WriteFile(types.ChromiumBookmark.TempBackupFilename(), []byte(backup), 0o600))
+	}
+}
+
+func TestChromiumBookmark_Extract(t *testing.T) {
+	writeChromiumBookmarks(t, testChromiumBookmarks, "")
+
+	var c ChromiumBookmark
+	require.NoError(t, c.Extract(nil))
+	assert.Len(t, c, 4)
+	assert.NoFileExists(t, types.ChromiumBookmark.TempFilename())
+}
+
+func TestChromiumBookmark_ExtractMaxRows(t *testing.T) {
+	extractor.SetMaxRows(2)
+	defer extractor.SetMaxRows(0)
+	writeChromiumBookmarks(t, testChromiumBookmarks, "")
+
+	var c ChromiumBookmark
+	require.NoError(t, c.Extract(nil))
+	assert.Len(t, c, 2)
+}
+
+func TestChromiumBookmark_ExtractBackup(t *testing.T) {
+	truncated := testChromiumBookmarks[:len(testChromiumBookmarks)/2]
+	writeChromiumBookmarks(t, truncated, testChromiumBookmarks)
+
+	var c ChromiumBookmark
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 4)
+	assert.Equal(t, "https://github.com/", c[0].URL)
+	assert.NoFileExists(t, types.ChromiumBookmark.TempBackupFilename())
+}
+
+func TestChromiumBookmark_ExtractCorrupted(t *testing.T) {
+	writeChromiumBookmarks(t, `{"roots": {`, "")
+
+	var c ChromiumBookmark
+	assert.ErrorIs(t, c.Extract(nil), errInvalidBookmarks)
+}
+
+func TestChromiumBookmark_ExtractMissingRoots(t *testing.T) {
+	incomplete := `{"checksum": "", "roots": {}, "version": 1}`
+	writeChromiumBookmarks(t, incomplete, testChromiumBookmarks)
+
+	var c ChromiumBookmark
+	require.NoError(t, c.Extract(nil))
+	assert.Len(t, c, 4)
+
+	writeChromiumBookmarks(t, incomplete, "")
+	c = nil
+	assert.ErrorIs(t, c.Extract(nil), errMissingRoots)
+}
+
+func TestChromiumBookmark_ExtractWithoutSynced(t *testing.T) {
+	start, end := strings.Index(testChromiumBookmarks, `,
+      "synced"`), strings.Index(testChromiumBookmarks, `
+   },
+   "version"`)
+	withoutSynced := testChromiumBookmarks[:start] + testChromiumBookmarks[end:]
+	require.True(t, gjson.Valid(withoutSynced))
+	writeChromiumBookmarks(t, withoutSynced, testChromiumBookmarks[:len(testChromiumBookmarks)/2])
+
+	// the main file is kept instead of the backup
+	var c ChromiumBookmark
+	require.NoError(t, c.Extract(nil))
+	assert.Len(t, c, 3)
+	assert.NoFileExists(t, types.ChromiumBookmark.TempBackupFilename())
+}
+
+func TestChromiumBookmark_ExtractMetaInfo(t *testing.T) {
+	withMeta := strings.Replace(testChromiumBookmarks, `"name": "GitHub",`, `"meta_info": {
+               "last_visited_desktop": "13312345679901234",
+               "last_visited": "13312345680000000",
+               "power_bookmark_meta": "CgA="
+            },
+            "name": "GitHub",`, 1)
+	writeChromiumBookmarks(t, withMeta, "")
+
+	var c ChromiumBookmark
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 4)
+	var github, folder bookmark
+	for _, b := range c {
+		switch b.Name {
+		case "GitHub":
+			github = b
+		case "Bookmarks bar":
+			folder = b
+		}
+	}
+	assert.Equal(t, "CgA=", github.MetaInfo["power_bookmark_meta"])
+	assert.Equal(t, typeutil.TimeEpoch(13312345680000000), github.LastVisited)
+	assert.Nil(t, folder.MetaInfo)
+	assert.True(t, folder.LastVisited.IsZero())
+}
+
+func TestVerifyChromiumChecksum(t *testing.T) {
+	r := gjson.Parse(testChromiumBookmarks)
+	result := VerifyChromiumChecksum(r)
+	// the md5 of the nodes as bookmark_codec.cc hashes them, computed outside of this package
+	assert.Equal(t, "ecdf367c8c02ac665344ec0f3c1500f9", result.Computed)
+	assert.False(t, result.Valid())
+
+	signed := strings.Replace(testChromiumBookmarks, `"checksum": ""`, `"checksum": "`+result.Computed+`"`, 1)
+	assert.True(t, VerifyChromiumChecksum(gjson.Parse(signed)).Valid())
+
+	tampered := strings.Replace(signed, "https://github.com/", "https://evil.test/", 1)
+	assert.False(t, VerifyChromiumChecksum(gjson.Parse(tampered)).Valid())
+
+	// the titles are hashed as UTF-16LE, the emoji is a surrogate pair
+	utf16Names := `{"roots": {
+		"bookmark_bar": {"id": "1", "name": "Barre de favoris", "type": "folder", "children": [
+			{"id": "3", "name": "🔖 Lesezeichen", "type": "url", "url": "https://example.com/"}]},
+		"other": {"id": "2", "name": "Other", "type": "folder", "children": []}}}`
+	assert.Equal(t, "0dc0c31cdccce9e1d4b5ff880b279a1f", VerifyChromiumChecksum(gjson.Parse(utf16Names)).Computed)
+}
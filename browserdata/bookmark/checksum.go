@@ -0,0 +1,78 @@
+package bookmark
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"unicode/utf16"
+
+	"github.com/tidwall/gjson"
+)
+
+// verifyChecksum enables the checksum verification of the Chromium Bookmarks file
+var verifyChecksum bool
+
+// SetVerifyChecksum enables or disables the checksum verification of Chromium bookmarks.
+// A mismatched checksum is reported as a warning, the bookmarks are exported anyway.
+func SetVerifyChecksum(enabled bool) {
+	verifyChecksum = enabled
+}
+
+// ChecksumResult is the result of the Chromium Bookmarks checksum verification
+type ChecksumResult struct {
+	Stored   string
+	Computed string
+}
+
+// Valid reports whether the stored checksum matches the computed one
+func (r ChecksumResult) Valid() bool {
+	return r.Stored == r.Computed
+}
+
+// chromiumChecksumRoots are the roots included in the checksum, in the order Chromium decodes them
+var chromiumChecksumRoots = []string{"bookmark_bar", "other", "synced"}
+
+// VerifyChromiumChecksum computes the checksum of the Chromium Bookmarks file the way Chromium does.
+// The checksum is a MD5 over every node in depth-first order, starting from the roots:
+//
+//	url node:    id + title (UTF-16LE) + "url" + url
+//	folder node: id + title (UTF-16LE) + "folder", followed by its children
+//
+// @https://source.chromium.org/chromium/chromium/src/+/main:components/bookmarks/browser/bookmark_codec.cc
+func VerifyChromiumChecksum(r gjson.Result) ChecksumResult {
+	h := md5.New()
+	roots := r.Get("roots")
+	for _, name := range chromiumChecksumRoots {
+		if root := roots.Get(name); root.Exists() {
+			updateChecksum(h, root)
+		}
+	}
+	return ChecksumResult{
+		Stored:   r.Get("checksum").String(),
+		Computed: hex.EncodeToString(h.Sum(nil)),
+	}
+}
+
+func updateChecksum(h hash.Hash, node gjson.Result) {
+	h.Write([]byte(node.Get(bookmarkID).String()))
+	h.Write(utf16LE(node.Get(bookmarkName).String()))
+	if node.Get(bookmarkType).String() == "url" {
+		h.Write([]byte("url"))
+		h.Write([]byte(node.Get(bookmarkURL).String()))
+		return
+	}
+	h.Write([]byte("folder"))
+	for _, child := range node.Get(bookmarkChildren).Array() {
+		updateChecksum(h, child)
+	}
+}
+
+func utf16LE(s string) []byte {
+	u := utf16.Encode([]rune(s))
+	b := make([]byte, 2*len(u))
+	for i, v := range u {
+		binary.LittleEndian.PutUint16(b[2*i:], v)
+	}
+	return b
+}
@@ -0,0 +1,39 @@
+package bookmark
+
+import (
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
+)
+
+// metaLastVisited are the keys of meta_info with the last visit of the bookmark on the
+// desktop and on the synced mobile devices, microseconds since 1601 as strings.
+// @https://source.chromium.org/chromium/chromium/src/+/main:components/bookmarks/browser/bookmark_utils.cc
+var metaLastVisited = []string{"last_visited_desktop", "last_visited"}
+
+// parseMetaInfo returns the entries of the meta_info of a node and the latest of its last
+// visits, the nodes without meta_info have no entries.
+func parseMetaInfo(metaInfo gjson.Result) (map[string]string, time.Time) {
+	if !metaInfo.IsObject() {
+		return nil, time.Time{}
+	}
+	entries := make(map[string]string)
+	metaInfo.ForEach(func(key, value gjson.Result) bool {
+		entries[key.String()] = value.String()
+		return true
+	})
+	if len(entries) == 0 {
+		return nil, time.Time{}
+	}
+	var lastVisited time.Time
+	for _, key := range metaLastVisited {
+		if v := metaInfo.Get(key).Int(); v > 0 {
+			if t := typeutil.TimeEpoch(v); t.After(lastVisited) {
+				lastVisited = t
+			}
+		}
+	}
+	return entries, lastVisited
+}
@@ -0,0 +1,52 @@
+package bookmark
+
+import (
+	"strings"
+)
+
+// the types of moz_bookmarks
+// @https://searchfox.org/mozilla-central/source/toolkit/components/places/nsINavBookmarksService.idl
+const (
+	firefoxTypeBookmark  = 1
+	firefoxTypeFolder    = 2
+	firefoxTypeSeparator = 3
+)
+
+// placeQueryPrefix is the url prefix of the smart folders, eg: place:sort=8&maxResults=10
+const placeQueryPrefix = "place:"
+
+var (
+	// includeSeparators keeps the separators of the Firefox bookmarks, they have no url or title
+	includeSeparators bool
+	// excludeQueries drops the place: queries of the smart folders, eg: Most Visited
+	excludeQueries bool
+)
+
+// SetIncludeSeparators keeps the separators in the Firefox bookmarks, they are skipped by default
+func SetIncludeSeparators(b bool) {
+	includeSeparators = b
+}
+
+// SetExcludeQueries drops the place: query bookmarks of the Firefox smart folders
+func SetExcludeQueries(b bool) {
+	excludeQueries = b
+}
+
+// skipFirefoxPlace reports whether the Firefox bookmark is filtered out by its type or url
+func skipFirefoxPlace(bt int64, url string) bool {
+	if bt == firefoxTypeSeparator {
+		return !includeSeparators
+	}
+	return excludeQueries && strings.HasPrefix(url, placeQueryPrefix)
+}
+
+func linkType(a int64) string {
+	switch a {
+	case firefoxTypeBookmark:
+		return "url"
+	case firefoxTypeSeparator:
+		return "separator"
+	default:
+		return "folder"
+	}
+}
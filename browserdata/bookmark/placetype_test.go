@@ -0,0 +1,53 @@
+package bookmark
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func createFirefoxPlacesDB(t *testing.T) {
+	t.Helper()
+	db, err := sql.Open("sqlite", types.FirefoxBookmark.TempFilename())
+	require.NoError(t, err)
+	defer db.Close()
+	for _, stmt := range []string{
+		`CREATE TABLE moz_places (id INTEGER PRIMARY KEY, url TEXT)`,
+		`CREATE TABLE moz_bookmarks (id INTEGER PRIMARY KEY, type INTEGER, fk INTEGER DEFAULT NULL, parent INTEGER, title TEXT, dateAdded INTEGER)`,
+		`INSERT INTO moz_places VALUES (1, 'https://github.com/'), (2, 'place:sort=8&maxResults=10')`,
+		`INSERT INTO moz_bookmarks VALUES
+			(1, 2, NULL, 0, '', 1000000),
+			(2, 2, NULL, 1, 'menu', 2000000),
+			(3, 1, 1, 2, 'GitHub', 3000000),
+			(4, 3, NULL, 2, NULL, 4000000),
+			(5, 1, 2, 2, 'Most Visited', 5000000)`,
+	} {
+		_, err = db.Exec(stmt)
+		require.NoError(t, err)
+	}
+}
+
+func TestFirefoxBookmark_PlaceTypes(t *testing.T) {
+	defer SetIncludeSeparators(false)
+	defer SetExcludeQueries(false)
+	names := func() map[string]string {
+		createFirefoxPlacesDB(t)
+		var f FirefoxBookmark
+		require.NoError(t, f.Extract(nil))
+		got := make(map[string]string)
+		for _, b := range f {
+			got[b.Name] = b.Type
+		}
+		return got
+	}
+
+	assert.Equal(t, map[string]string{"menu": "folder", "GitHub": "url", "Most Visited": "url"}, names())
+
+	SetIncludeSeparators(true)
+	SetExcludeQueries(true)
+	assert.Equal(t, map[string]string{"menu": "folder", "GitHub": "url", "": "separator"}, names())
+}
@@ -1,63 +1,251 @@
 package browserdata
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/moond4rk/hackbrowserdata/crypto"
 	"github.com/moond4rk/hackbrowserdata/extractor"
 	"github.com/moond4rk/hackbrowserdata/log"
 	"github.com/moond4rk/hackbrowserdata/types"
 	"github.com/moond4rk/hackbrowserdata/utils/fileutil"
 )
 
+var ErrItemNotFound = errors.New("item not found")
+
+// writeEmpty writes the items without records as well, eg: a csv file with the header only
+var writeEmpty bool
+
+// SetWriteEmpty enables writing the items without records, the failed items are still skipped
+func SetWriteEmpty(b bool) {
+	writeEmpty = b
+}
+
 type BrowserData struct {
 	extractors map[types.DataType]extractor.Extractor
+	errors     map[types.DataType]error
+	stats      map[types.DataType]ItemStats
+	// browser and profile are the names of the output name template
+	browser, profile string
+	// keySource is where the master key was read from, it's reported by Samples
+	keySource string
+	keyErr    error
 }
 
 func New(items []types.DataType) *BrowserData {
 	bd := &BrowserData{
 		extractors: make(map[types.DataType]extractor.Extractor),
+		errors:     make(map[types.DataType]error),
+		stats:      make(map[types.DataType]ItemStats),
 	}
 	bd.addExtractors(items)
 	return bd
 }
 
-func (d *BrowserData) Recovery(masterKey []byte) error {
-	for _, source := range d.extractors {
-		if err := source.Extract(masterKey); err != nil {
+// Recovery extracts every item, a failed item doesn't stop the others,
+// its error is kept and reported by Stats. The encrypted values are decrypted by decryptor,
+// built from the master key of the profile.
+func (d *BrowserData) Recovery(decryptor crypto.Decryptor) error {
+	if d.stats == nil {
+		d.stats = make(map[types.DataType]ItemStats)
+	}
+	for _, dt := range types.SortedKeys(d.extractors) {
+		source := d.extractors[dt]
+		session := extractor.NewSession(decryptor)
+		start := time.Now()
+		err := extract(source, session)
+		elapsed := time.Since(start)
+		decrypted, failed := session.DecryptStats()
+		d.stats[dt] = ItemStats{Elapsed: elapsed, Decrypted: decrypted, DecryptFailed: failed, Duplicates: session.Duplicates()}
+		if err != nil {
 			log.Errorf("parse %s error: %v", source.Name(), err)
+			d.errors[dt] = err
+		}
+	}
+	return nil
+}
+
+// extract runs the extractor, a panic is returned as error, so the other items are still extracted
+func extract(source extractor.Extractor, session *extractor.Session) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return source.Extract(session)
+}
+
+// ItemStats is the statistics of extracting an item, Duplicates is the number of records
+// dropped as duplicates, eg: with -dedupe-cookies
+type ItemStats struct {
+	Name          string        `json:"name"`
+	Records       int           `json:"records"`
+	Decrypted     int           `json:"decrypted"`
+	DecryptFailed int           `json:"decrypt_failed"`
+	Duplicates    int           `json:"duplicates,omitempty"`
+	Elapsed       time.Duration `json:"elapsed_ns"`
+	Error         string        `json:"error,omitempty"`
+}
+
+// Stats is the statistics of extracting the items of a browser, the totals are the sums of the items
+type Stats struct {
+	Items         []ItemStats   `json:"items"`
+	Records       int           `json:"records"`
+	Decrypted     int           `json:"decrypted"`
+	DecryptFailed int           `json:"decrypt_failed"`
+	Duplicates    int           `json:"duplicates,omitempty"`
+	Failed        int           `json:"failed"`
+	Elapsed       time.Duration `json:"elapsed_ns"`
+}
+
+// Stats returns the record count, decryption tallies and elapsed time of every item in the order of Recovery
+func (d *BrowserData) Stats() Stats {
+	stats := Stats{Items: make([]ItemStats, 0, len(d.extractors))}
+	for _, dt := range types.SortedKeys(d.extractors) {
+		source := d.extractors[dt]
+		item := d.stats[dt]
+		item.Name = source.Name()
+		item.Records = source.Len()
+		if err := d.errors[dt]; err != nil {
+			item.Error = err.Error()
+			stats.Failed++
+		}
+		stats.Items = append(stats.Items, item)
+		stats.Records += item.Records
+		stats.Decrypted += item.Decrypted
+		stats.DecryptFailed += item.DecryptFailed
+		stats.Duplicates += item.Duplicates
+		stats.Elapsed += item.Elapsed
+	}
+	return stats
+}
+
+func (d *BrowserData) Output(dir, browserName, flag string) error {
+	return d.output(dir, flag, func(item, ext string) string {
+		return fileutil.Filename(browserName, item, ext)
+	})
+}
+
+// OutputProfile writes the items to <dir>/<browser>/<profile>/<item>.<ext>,
+// so the profiles of a browser never share a folder.
+func (d *BrowserData) OutputProfile(dir, browserName, profile, flag string) error {
+	if dir != consoleDir {
+		dir = fileutil.ProfileDir(dir, browserName, profile)
+	}
+	return d.output(dir, flag, func(item, ext string) string {
+		return strings.ToLower(item + "." + ext)
+	})
+}
+
+// output writes the items in every format of flag, eg: csv,json, it stops at the first write
+// error and returns it, the file of the item is removed so no partial output is left.
+// The filenames are expanded from the output name template if it's set.
+func (d *BrowserData) output(dir, flag string, filenameOf func(item, ext string) string) error {
+	if outputName != "" {
+		filenameOf = d.expandOutputName
+	}
+	for _, format := range ParseFormats(flag) {
+		if mergeJSON && format == "json" {
+			if err := d.outputMerged(dir, filenameOf); err != nil {
+				return err
+			}
 			continue
 		}
+		if err := d.outputFormat(dir, format, filenameOf); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (d *BrowserData) Output(dir, browserName, flag string) {
+func (d *BrowserData) outputFormat(dir, flag string, filenameOf func(item, ext string) string) error {
 	output := newOutPutter(flag)
 
-	for _, source := range d.extractors {
-		if source.Len() == 0 {
-			// if the length of the export data is 0, then it is not necessary to output
+	for _, dt := range types.SortedKeys(d.extractors) {
+		source := d.extractors[dt]
+		if source.Len() == 0 && (!writeEmpty || d.errors[dt] != nil) {
+			// a fresh profile has the files but no records, it's not necessary to output
+			log.Debugf("skip %s, no records", source.Name())
 			continue
 		}
-		filename := fileutil.Filename(browserName, source.Name(), output.Ext())
+		if !output.Supports(source) {
+			log.Debugf("skip %s, format %s is not supported", source.Name(), flag)
+			continue
+		}
+		if dir == consoleDir {
+			if err := console.WriteItem(func(w io.Writer) error { return output.Write(source, w) }); err != nil {
+				return fmt.Errorf("write %s to console: %w", source.Name(), err)
+			}
+			continue
+		}
+		filename := compressedFilename(filenameOf(source.Name(), output.Ext()))
 
 		f, err := output.CreateFile(dir, filename)
 		if err != nil {
-			log.Errorf("create file %s error: %v", filename, err)
-			continue
+			return fmt.Errorf("create file %s: %w", filename, err)
 		}
-		if err := output.Write(source, f); err != nil {
-			log.Errorf("write to file %s error: %v", filename, err)
-			continue
+		size := appendedSize(f)
+		if err := writeCompressed(f, func(w io.Writer) error {
+			if size > 0 && output.Ext() == csvFormat {
+				// the csv appended to keeps the header of its first run only
+				w = &headerSkipper{w: w}
+			}
+			return output.Write(source, w)
+		}); err != nil {
+			discardOutput(f, size)
+			return fmt.Errorf("write to file %s: %w", filename, err)
 		}
 		if err := f.Close(); err != nil {
-			log.Errorf("close file %s error: %v", filename, err)
-			continue
+			return fmt.Errorf("close file %s: %w", filename, err)
 		}
+		recordOutput(f.Name(), source.Len())
 		log.Warnf("export success: %s", filename)
 	}
+	return nil
+}
+
+// ItemNames returns the names of the extracted items which have data
+func (d *BrowserData) ItemNames() []string {
+	names := make([]string, 0, len(d.extractors))
+	for _, source := range d.sortedExtractors() {
+		if source.Len() > 0 {
+			names = append(names, source.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteItem writes the extracted item with the name to w, in the format of flag
+func (d *BrowserData) WriteItem(w io.Writer, name, flag string) error {
+	for _, source := range d.sortedExtractors() {
+		if source.Name() == name {
+			return newOutPutter(flag).Write(source, w)
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrItemNotFound, name)
+}
+
+// sortedExtractors returns the extractors in the order of their data type,
+// so the items are always processed and written in the same order.
+func (d *BrowserData) sortedExtractors() []extractor.Extractor {
+	dataTypes := types.SortedKeys(d.extractors)
+	extractors := make([]extractor.Extractor, 0, len(dataTypes))
+	for _, dt := range dataTypes {
+		extractors = append(extractors, d.extractors[dt])
+	}
+	return extractors
 }
 
 func (d *BrowserData) addExtractors(items []types.DataType) {
 	for _, itemType := range items {
+		if sampleDecrypt && !sampleItems[itemType] {
+			continue
+		}
 		if source := extractor.CreateExtractor(itemType); source != nil {
 			d.extractors[itemType] = source
 		} else {
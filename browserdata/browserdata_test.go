@@ -0,0 +1,205 @@
+package browserdata
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func TestBrowserData_SortedExtractors(t *testing.T) {
+	bd := New(types.DefaultChromiumTypes)
+
+	var want []string
+	for _, item := range types.SortedKeys(bd.extractors) {
+		want = append(want, bd.extractors[item].Name())
+	}
+	for i := 0; i < 10; i++ {
+		var got []string
+		for _, e := range bd.sortedExtractors() {
+			got = append(got, e.Name())
+		}
+		assert.Equal(t, want, got)
+	}
+	assert.Len(t, want, len(bd.extractors))
+}
+
+type fakeExtractor struct {
+	name      string
+	records   int
+	err       error
+	panic     bool
+	decrypted int
+	failed    int
+}
+
+func (f *fakeExtractor) Extract(session *extractor.Session) error {
+	for i := 0; i < f.decrypted; i++ {
+		session.CountDecrypt(nil)
+	}
+	for i := 0; i < f.failed; i++ {
+		session.CountDecrypt(errors.New("decrypt failed"))
+	}
+	if f.panic {
+		panic("index out of range")
+	}
+	if f.err != nil {
+		return f.err
+	}
+	f.records = 2
+	return nil
+}
+
+func (f *fakeExtractor) Name() string { return f.name }
+
+func (f *fakeExtractor) Len() int { return f.records }
+
+func TestBrowserData_RecoveryPartial(t *testing.T) {
+	errLocked := errors.New("database is locked")
+	bd := &BrowserData{
+		extractors: map[types.DataType]extractor.Extractor{
+			types.ChromiumPassword: &fakeExtractor{name: "password"},
+			types.ChromiumHistory:  &fakeExtractor{name: "history", err: errLocked},
+			types.ChromiumCookie:   &fakeExtractor{name: "cookie", panic: true},
+		},
+		errors: make(map[types.DataType]error),
+	}
+	require.NoError(t, bd.Recovery(nil))
+
+	stats := bd.Stats()
+	require.Len(t, stats.Items, 3)
+	assert.Equal(t, 2, stats.Failed)
+	assert.Equal(t, "password", stats.Items[0].Name)
+	assert.Equal(t, 2, stats.Items[0].Records)
+	assert.Empty(t, stats.Items[0].Error)
+	assert.Equal(t, "cookie", stats.Items[1].Name)
+	assert.Contains(t, stats.Items[1].Error, "panic: index out of range")
+	assert.Equal(t, "history", stats.Items[2].Name)
+	assert.Equal(t, errLocked.Error(), stats.Items[2].Error)
+}
+
+func TestBrowserData_OutputProfile(t *testing.T) {
+	dir := t.TempDir()
+	for _, profile := range []string{"Profile 1", "Profile 2"} {
+		bd := &BrowserData{
+			extractors: map[types.DataType]extractor.Extractor{
+				types.ChromiumCookie: newTestCookies(t, profile),
+			},
+		}
+		require.NoError(t, bd.OutputProfile(dir, "Chrome", profile, "json"))
+	}
+
+	for _, profile := range []string{"profile_1", "profile_2"} {
+		data, err := os.ReadFile(filepath.Join(dir, "chrome", profile, "cookie.json"))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), strings.Replace(profile, "profile_", "Profile ", 1))
+	}
+}
+
+func TestBrowserData_OutputFormats(t *testing.T) {
+	dir := t.TempDir()
+	bd := &BrowserData{
+		extractors: map[types.DataType]extractor.Extractor{
+			types.ChromiumCookie: newTestCookies(t, "abc"),
+		},
+	}
+	require.NoError(t, bd.Output(dir, "chrome_default", "all"))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"chrome_default_cookie.csv", "chrome_default_cookie.json"}, names)
+	data, err := os.ReadFile(filepath.Join(dir, "chrome_default_cookie.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"Value": "abc"`)
+}
+
+func TestBrowserData_OutputWriteEmpty(t *testing.T) {
+	defer SetWriteEmpty(false)
+	newData := func() *BrowserData {
+		return &BrowserData{
+			extractors: map[types.DataType]extractor.Extractor{
+				types.ChromiumCookie:   newTestCookies(t),
+				types.ChromiumPassword: &fakeExtractor{name: "password"},
+			},
+			errors: map[types.DataType]error{types.ChromiumPassword: errors.New("database is locked")},
+		}
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, newData().Output(dir, "chrome_default", "csv"))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the items without records are skipped")
+
+	SetWriteEmpty(true)
+	require.NoError(t, newData().Output(dir, "chrome_default", "json"))
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "the failed items are skipped")
+	data, err := os.ReadFile(filepath.Join(dir, "chrome_default_cookie.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "[]\n", string(data))
+}
+
+func TestBrowserData_Stats(t *testing.T) {
+	bd := &BrowserData{
+		extractors: map[types.DataType]extractor.Extractor{
+			types.ChromiumPassword: &fakeExtractor{name: "password", decrypted: 2, failed: 1},
+			types.ChromiumHistory:  &fakeExtractor{name: "history", err: errors.New("database is locked")},
+			types.ChromiumCookie:   &fakeExtractor{name: "cookie", decrypted: 3},
+		},
+		errors: make(map[types.DataType]error),
+	}
+	require.NoError(t, bd.Recovery(nil))
+
+	stats := bd.Stats()
+	require.Len(t, stats.Items, 3)
+	assert.Equal(t, "password", stats.Items[0].Name)
+	assert.Equal(t, 2, stats.Items[0].Records)
+	assert.Equal(t, 2, stats.Items[0].Decrypted)
+	assert.Equal(t, 1, stats.Items[0].DecryptFailed)
+	assert.Equal(t, 3, stats.Items[1].Decrypted, "every item has its own counter")
+	assert.Zero(t, stats.Items[1].DecryptFailed)
+	assert.Equal(t, "database is locked", stats.Items[2].Error)
+
+	assert.Equal(t, 4, stats.Records)
+	assert.Equal(t, 5, stats.Decrypted)
+	assert.Equal(t, 1, stats.DecryptFailed)
+	assert.Equal(t, 1, stats.Failed)
+	assert.Equal(t, stats.Items[0].Elapsed+stats.Items[1].Elapsed+stats.Items[2].Elapsed, stats.Elapsed)
+}
+
+func TestBrowserData_StatsConcurrent(t *testing.T) {
+	browsers := make([]*BrowserData, 8)
+	var wg sync.WaitGroup
+	for i := range browsers {
+		browsers[i] = &BrowserData{
+			extractors: map[types.DataType]extractor.Extractor{
+				types.ChromiumPassword: &fakeExtractor{name: "password", decrypted: 100 * (i + 1)},
+			},
+			errors: make(map[types.DataType]error),
+		}
+		wg.Add(1)
+		go func(bd *BrowserData) {
+			defer wg.Done()
+			assert.NoError(t, bd.Recovery(nil))
+		}(browsers[i])
+	}
+	wg.Wait()
+	// the browsers extracted at the same time don't count the values of each other
+	for i, bd := range browsers {
+		assert.Equal(t, 100*(i+1), bd.Stats().Decrypted)
+	}
+}
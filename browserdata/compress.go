@@ -0,0 +1,48 @@
+package browserdata
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// compressGzip is the only supported per file compression of --compress-output
+const compressGzip = "gzip"
+
+// gzipOutput writes every output file gzipped with the .gz extension appended
+var gzipOutput bool
+
+// SetCompressOutput sets the compression of each output file, gzip or empty for none
+func SetCompressOutput(mode string) error {
+	switch mode {
+	case "":
+		gzipOutput = false
+	case compressGzip:
+		gzipOutput = true
+	default:
+		return fmt.Errorf("unsupported output compression %s, only %s is supported", mode, compressGzip)
+	}
+	return nil
+}
+
+// compressedFilename appends the .gz extension to the filename if the output is gzipped
+func compressedFilename(filename string) string {
+	if gzipOutput {
+		return filename + ".gz"
+	}
+	return filename
+}
+
+// writeCompressed writes to w through a gzip writer if the output is gzipped, the gzip
+// writer is closed before returning so the footer is written and the file isn't truncated.
+func writeCompressed(w io.Writer, write func(io.Writer) error) error {
+	if !gzipOutput {
+		return write(w)
+	}
+	gz := gzip.NewWriter(w)
+	if err := write(gz); err != nil {
+		_ = gz.Close()
+		return err
+	}
+	return gz.Close()
+}
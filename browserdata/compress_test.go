@@ -0,0 +1,48 @@
+package browserdata
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func TestSetCompressOutput(t *testing.T) {
+	defer func() { _ = SetCompressOutput("") }()
+	require.NoError(t, SetCompressOutput("gzip"))
+	assert.True(t, gzipOutput)
+	require.NoError(t, SetCompressOutput(""))
+	assert.False(t, gzipOutput)
+	assert.Error(t, SetCompressOutput("zstd"))
+}
+
+func TestBrowserData_OutputGzip(t *testing.T) {
+	require.NoError(t, SetCompressOutput("gzip"))
+	defer func() { _ = SetCompressOutput("") }()
+	dir := t.TempDir()
+	bd := &BrowserData{
+		extractors: map[types.DataType]extractor.Extractor{
+			types.ChromiumCookie: newTestCookies(t, "abc"),
+		},
+	}
+	require.NoError(t, bd.Output(dir, "chrome_default", "csv,json"))
+
+	for _, name := range []string{"chrome_default_cookie.csv.gz", "chrome_default_cookie.json.gz"} {
+		f, err := os.Open(filepath.Join(dir, name))
+		require.NoError(t, err)
+		gz, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		// reading to the end checks the footer, a truncated file fails with unexpected EOF
+		data, err := io.ReadAll(gz)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "abc")
+		require.NoError(t, f.Close())
+	}
+}
@@ -0,0 +1,107 @@
+package container
+
+import (
+	"sort"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/fileutil"
+)
+
+func init() {
+	extractor.RegisterExtractor(types.FirefoxContainer, extractor.Info{
+		Description: "the containers",
+		Sources:     []string{"containers.json"},
+	}, func() extractor.Extractor {
+		return new(FirefoxContainer)
+	})
+}
+
+// FirefoxContainer is the contextual identities defined in containers.json, the cookies and
+// storage of a container are kept apart by the userContextId of their originAttributes.
+type FirefoxContainer []container
+
+type container struct {
+	UserContextID int64
+	Name          string
+	Icon          string
+	Color         string
+	// Public is false for the internal containers, eg: the one of the thumbnails
+	Public bool
+}
+
+// builtinNames are the names of the default containers, they have a l10nID instead of a name
+var builtinNames = map[string]string{
+	"userContextPersonal.label": "Personal",
+	"userContextWork.label":     "Work",
+	"userContextBanking.label":  "Banking",
+	"userContextShopping.label": "Shopping",
+}
+
+func (f *FirefoxContainer) Extract(_ *extractor.Session) error {
+	containers, err := load(types.FirefoxContainer.TempFilename())
+	if err != nil {
+		return err
+	}
+	types.FirefoxContainer.RemoveTemp()
+	for _, c := range containers {
+		if extractor.ReachedMaxRows(len(*f)) {
+			break
+		}
+		*f = append(*f, c)
+	}
+	return nil
+}
+
+// Names returns the names of the public containers in the containers.json by their userContextId,
+// it's empty if the file doesn't exist.
+func Names(filename string) map[int64]string {
+	containers, err := load(filename)
+	if err != nil {
+		return nil
+	}
+	names := make(map[int64]string, len(containers))
+	for _, c := range containers {
+		if c.Public {
+			names[c.UserContextID] = c.Name
+		}
+	}
+	return names
+}
+
+// load parses the identities of containers.json sorted by userContextId
+// @https://searchfox.org/mozilla-central/source/toolkit/components/contextualidentity/ContextualIdentityService.sys.mjs
+func load(filename string) ([]container, error) {
+	s, err := fileutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var containers []container
+	for _, v := range gjson.Get(s, "identities").Array() {
+		c := container{
+			UserContextID: v.Get("userContextId").Int(),
+			Name:          v.Get("name").String(),
+			Icon:          v.Get("icon").String(),
+			Color:         v.Get("color").String(),
+			Public:        v.Get("public").Bool(),
+		}
+		if c.Name == "" {
+			c.Name = builtinNames[v.Get("l10nID").String()]
+		}
+		containers = append(containers, c)
+	}
+	sort.SliceStable(containers, func(i, j int) bool {
+		return containers[i].UserContextID < containers[j].UserContextID
+	})
+	return containers, nil
+}
+
+func (f *FirefoxContainer) Name() string {
+	return "container"
+}
+
+func (f *FirefoxContainer) Len() int {
+	return len(*f)
+}
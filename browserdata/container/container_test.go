@@ -0,0 +1,39 @@
+package container
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+const containersJSON = `{"version": 5, "lastUserContextId": 6, "identities": [
+	{"userContextId": 2, "public": true, "icon": "briefcase", "color": "orange", "l10nID": "userContextWork.label", "accessKey": "userContextWork.accesskey"},
+	{"userContextId": 1, "public": true, "icon": "fingerprint", "color": "blue", "l10nID": "userContextPersonal.label", "accessKey": "userContextPersonal.accesskey"},
+	{"userContextId": 5, "public": false, "icon": "", "color": "", "name": "userContextIdInternal.thumbnail", "accessKey": ""},
+	{"userContextId": 6, "public": true, "icon": "circle", "color": "red", "name": "Research"}
+]}`
+
+func TestFirefoxContainer_Extract(t *testing.T) {
+	require.NoError(t, os.WriteFile(types.FirefoxContainer.TempFilename(), []byte(containersJSON), 0o600))
+
+	var f FirefoxContainer
+	require.NoError(t, f.Extract(nil))
+	require.Len(t, f, 4)
+	assert.Equal(t, container{UserContextID: 1, Name: "Personal", Icon: "fingerprint", Color: "blue", Public: true}, f[0])
+	assert.Equal(t, "Work", f[1].Name)
+	assert.False(t, f[2].Public)
+	assert.Equal(t, "Research", f[3].Name)
+}
+
+func TestNames(t *testing.T) {
+	filename := types.FirefoxContainer.TempFilename()
+	require.NoError(t, os.WriteFile(filename, []byte(containersJSON), 0o600))
+	defer types.FirefoxContainer.RemoveTemp()
+
+	assert.Equal(t, map[int64]string{1: "Personal", 2: "Work", 6: "Research"}, Names(filename))
+	assert.Nil(t, Names(filename+".missing"))
+}
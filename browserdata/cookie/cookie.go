@@ -2,25 +2,37 @@ package cookie
 
 import (
 	"database/sql"
-	"os"
+	"fmt"
+	"net/url"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	// import sqlite3 driver
 	_ "modernc.org/sqlite"
 
+	"github.com/moond4rk/hackbrowserdata/browserdata/container"
 	"github.com/moond4rk/hackbrowserdata/crypto"
 	"github.com/moond4rk/hackbrowserdata/extractor"
 	"github.com/moond4rk/hackbrowserdata/log"
 	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/sqliteutil"
 	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
 )
 
 func init() {
-	extractor.RegisterExtractor(types.ChromiumCookie, func() extractor.Extractor {
+	extractor.RegisterExtractor(types.ChromiumCookie, extractor.Info{
+		Description: "the cookies of the sites",
+		Sources:     []string{"Network/Cookies", "Cookies"},
+	}, func() extractor.Extractor {
 		return new(ChromiumCookie)
 	})
-	extractor.RegisterExtractor(types.FirefoxCookie, func() extractor.Extractor {
+	extractor.RegisterExtractor(types.FirefoxCookie, extractor.Info{
+		Description: "the cookies of the sites",
+		Sources:     []string{"cookies.sqlite", "containers.json"},
+	}, func() extractor.Extractor {
 		return new(FirefoxCookie)
 	})
 }
@@ -32,71 +44,191 @@ type cookie struct {
 	Path         string
 	KeyName      string
 	encryptValue []byte
-	Value        string
-	IsSecure     bool
-	IsHTTPOnly   bool
-	HasExpire    bool
-	IsPersistent bool
-	CreateDate   time.Time
-	ExpireDate   time.Time
+	// encryptSize is the size of encrypted_value, which is selected empty if it's too big
+	encryptSize int
+	Value       string
+	// DecryptMethod is how the value was decrypted, eg: AES-GCM-v10, it's hidden from csv unless requested
+	DecryptMethod string `csv:"-"`
+	IsSecure      bool
+	IsHTTPOnly    bool
+	HasExpire     bool
+	IsPersistent  bool
+	CreateDate    time.Time
+	ExpireDate    time.Time
+	// PartitionKey is the top-level site of a partitioned (CHIPS) cookie, empty if unpartitioned
+	PartitionKey string
+	// IsPartitioned reports whether the cookie was set with the Partitioned attribute
+	IsPartitioned bool
+	// Container is the name of the firefox container of the cookie, empty for the default one
+	Container string
+	// SameSite is None, Lax or Strict, empty if the cookie was set without the attribute
+	SameSite string
 }
 
 const (
-	queryChromiumCookie = `SELECT name, encrypted_value, host_key, path, creation_utc, expires_utc, is_secure, is_httponly, has_expires, is_persistent FROM cookies`
+	queryChromiumCookie = `SELECT name, %s, %s, %s, %s, host_key, path, creation_utc, expires_utc, is_secure, is_httponly, has_expires, is_persistent, %s, %s FROM cookies`
+	// chromiumPartitionColumn is added since Chrome 114 for partitioned cookies
+	// @https://source.chromium.org/chromium/chromium/src/+/main:net/extras/sqlite/sqlite_persistent_cookie_store.cc
+	chromiumPartitionColumn = "top_frame_site_key"
+	// chromiumSameSiteColumn is -1 unspecified, 0 none, 1 lax and 2 strict, it's added since Chrome 51
+	chromiumSameSiteColumn = "samesite"
 )
 
-func (c *ChromiumCookie) Extract(masterKey []byte) error {
-	db, err := sql.Open("sqlite", types.ChromiumCookie.TempFilename())
+// chromiumSameSite are the values of the samesite column, the unspecified -1 is empty
+var chromiumSameSite = map[int]string{0: "None", 1: "Lax", 2: "Strict"}
+
+func (c *ChromiumCookie) Extract(session *extractor.Session) error {
+	cookies, err := extractChromiumCookies(types.ChromiumCookie, session)
+	*c = cookies
+	return err
+}
+
+// extractChromiumCookies reads and decrypts the cookies of the chromium cookie database of the item
+func extractChromiumCookies(item types.DataType, session *extractor.Session) ([]cookie, error) {
+	db, err := sql.Open("sqlite", item.DSN())
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer os.Remove(types.ChromiumCookie.TempFilename())
+	defer item.RemoveTemp()
 	defer db.Close()
-	rows, err := db.Query(queryChromiumCookie)
+	partitionColumn := "''"
+	if ok, err := sqliteutil.ColumnExists(db, "cookies", chromiumPartitionColumn); err == nil && ok {
+		partitionColumn = chromiumPartitionColumn
+	}
+	sameSiteColumn := "-1"
+	if ok, err := sqliteutil.ColumnExists(db, "cookies", chromiumSameSiteColumn); err == nil && ok {
+		sameSiteColumn = chromiumSameSiteColumn
+	}
+	// the values are cut to the max value size in sql, so a crafted cookie isn't read in full
+	query, args := extractor.FilterHost(fmt.Sprintf(queryChromiumCookie,
+		extractor.ValueColumn("value"), extractor.SizeColumn("value"), extractor.EncryptedColumn("encrypted_value"), extractor.SizeColumn("encrypted_value"),
+		partitionColumn, sameSiteColumn), "host_key")
+	query = extractor.FilterEncrypted(query, "encrypted_value")
+	rows, err := db.Query(extractor.LimitQuery(query), args...)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer rows.Close()
+	var cookies []cookie
 	for rows.Next() {
 		var (
-			key, host, path                               string
+			key, host, path, partitionKey                 string
 			isSecure, isHTTPOnly, hasExpire, isPersistent int
+			sameSite                                      int
 			createDate, expireDate                        int64
-			value, encryptValue                           []byte
+			plainValue, encryptValue                      []byte
+			plainSize, encryptSize                        int
 		)
-		if err = rows.Scan(&key, &encryptValue, &host, &path, &createDate, &expireDate, &isSecure, &isHTTPOnly, &hasExpire, &isPersistent); err != nil {
+		if err = rows.Scan(&key, &plainValue, &plainSize, &encryptValue, &encryptSize, &host, &path, &createDate, &expireDate, &isSecure, &isHTTPOnly, &hasExpire, &isPersistent, &partitionKey, &sameSite); err != nil {
 			log.Errorf("scan chromium cookie error: %v", err)
 		}
 
 		cookie := cookie{
-			KeyName:      key,
-			Host:         host,
-			Path:         path,
-			encryptValue: encryptValue,
-			IsSecure:     typeutil.IntToBool(isSecure),
-			IsHTTPOnly:   typeutil.IntToBool(isHTTPOnly),
-			HasExpire:    typeutil.IntToBool(hasExpire),
-			IsPersistent: typeutil.IntToBool(isPersistent),
-			CreateDate:   typeutil.TimeEpoch(createDate),
-			ExpireDate:   typeutil.TimeEpoch(expireDate),
+			KeyName:       key,
+			Host:          host,
+			Path:          path,
+			encryptValue:  encryptValue,
+			encryptSize:   encryptSize,
+			IsSecure:      typeutil.IntToBool(isSecure),
+			IsHTTPOnly:    typeutil.IntToBool(isHTTPOnly),
+			HasExpire:     typeutil.IntToBool(hasExpire),
+			IsPersistent:  typeutil.IntToBool(isPersistent),
+			CreateDate:    typeutil.TimeEpoch(createDate),
+			ExpireDate:    typeutil.TimeEpoch(expireDate),
+			PartitionKey:  partitionKey,
+			IsPartitioned: partitionKey != "",
+			SameSite:      chromiumSameSite[sameSite],
 		}
-		if len(encryptValue) > 0 {
-			if len(masterKey) == 0 {
-				value, err = crypto.DecryptWithDPAPI(encryptValue)
-			} else {
-				value, err = crypto.DecryptWithChromium(masterKey, encryptValue)
-			}
-			if err != nil {
-				log.Errorf("decrypt chromium cookie error: %v", err)
-			}
+		if encryptSize == 0 && plainSize > 0 {
+			// the cookies are kept in the value column unencrypted if os_crypt is off, eg: headless linux
+			cookie.Value, cookie.DecryptMethod = extractor.TruncateValue(string(plainValue), plainSize), crypto.MethodPlaintext
 		}
-		cookie.Value = string(value)
-		*c = append(*c, cookie)
+		cookies = append(cookies, cookie)
 	}
-	sort.Slice(*c, func(i, j int) bool {
-		return (*c)[i].CreateDate.After((*c)[j].CreateDate)
+	// the rows are read first, decrypting the values is the slow part with many cookies
+	extractor.DecryptEach(len(cookies), func(i int) {
+		decryptCookie(&cookies[i], session)
 	})
-	return nil
+	cookies, duplicates := dedupeCookies(cookies)
+	session.CountDuplicates(duplicates)
+	sortCookies(cookies)
+	return cookies, nil
+}
+
+// decryptCookie decrypts the encrypted_value of the cookie, it's called concurrently
+func decryptCookie(c *cookie, session *extractor.Session) {
+	if len(c.encryptValue) == 0 {
+		if c.encryptSize > 0 {
+			// too big to be decrypted under the max value size
+			c.Value = extractor.TruncateValue("", c.encryptSize)
+		}
+		return
+	}
+	decryptor := session.Decryptor()
+	value, err := decryptor.Decrypt(c.encryptValue)
+	c.DecryptMethod = decryptor.Method(c.encryptValue)
+	if err != nil && isPlaintextValue(c.encryptValue) {
+		value, c.DecryptMethod, err = c.encryptValue, crypto.MethodPlaintext, nil
+	}
+	session.CountDecrypt(err)
+	if err != nil {
+		log.Errorf("decrypt chromium cookie error: %v", err)
+	}
+	c.Value = extractor.TruncateValue(string(value), len(value))
+}
+
+// isPlaintextValue reports whether the encrypted_value which can't be decrypted is the value
+// itself, it has neither a version prefix, eg: v10 or v20, nor the header of a DPAPI blob.
+func isPlaintextValue(value []byte) bool {
+	return !crypto.HasVersionPrefix(value) && !crypto.IsDPAPIBlob(value) && utf8.Valid(value)
+}
+
+// sortCookies sorts cookies by host, name and path, so the output is the same across runs.
+// Partitioned cookies follow the unpartitioned one, the newest comes first for the rest.
+// The hosts are grouped by their registrable domain first with includeSubdomains.
+func sortCookies(c []cookie) {
+	s := cookieSorter{cookies: c}
+	if includeSubdomains {
+		s.domains = make([]string, len(c))
+		for i := range c {
+			s.domains[i] = registrableDomain(c[i].Host)
+		}
+	}
+	sort.Stable(s)
+}
+
+// cookieSorter sorts the cookies with the registrable domains of their hosts, if any
+type cookieSorter struct {
+	cookies []cookie
+	domains []string
+}
+
+func (s cookieSorter) Len() int {
+	return len(s.cookies)
+}
+
+func (s cookieSorter) Less(i, j int) bool {
+	c := s.cookies
+	switch {
+	case s.domains != nil && s.domains[i] != s.domains[j]:
+		return s.domains[i] < s.domains[j]
+	case c[i].Host != c[j].Host:
+		return c[i].Host < c[j].Host
+	case c[i].KeyName != c[j].KeyName:
+		return c[i].KeyName < c[j].KeyName
+	case c[i].Path != c[j].Path:
+		return c[i].Path < c[j].Path
+	case c[i].PartitionKey != c[j].PartitionKey:
+		return c[i].PartitionKey < c[j].PartitionKey
+	}
+	return c[i].CreateDate.After(c[j].CreateDate)
+}
+
+func (s cookieSorter) Swap(i, j int) {
+	s.cookies[i], s.cookies[j] = s.cookies[j], s.cookies[i]
+	if s.domains != nil {
+		s.domains[i], s.domains[j] = s.domains[j], s.domains[i]
+	}
 }
 
 func (c *ChromiumCookie) Name() string {
@@ -110,49 +242,134 @@ func (c *ChromiumCookie) Len() int {
 type FirefoxCookie []cookie
 
 const (
-	queryFirefoxCookie = `SELECT name, value, host, path, creationTime, expiry, isSecure, isHttpOnly FROM moz_cookies`
+	queryFirefoxCookie = `SELECT name, %s, %s, host, path, creationTime, expiry, isSecure, isHttpOnly, %s, %s, %s FROM moz_cookies`
+	// firefoxOriginColumn holds the partitionKey of cookies partitioned by the top-level site
+	firefoxOriginColumn = "originAttributes"
+	// firefoxPartitionedColumn is added by newer Firefox versions for the Partitioned (CHIPS) attribute
+	firefoxPartitionedColumn = "isPartitionedAttributeSet"
+	// firefoxSameSiteColumn is 0 none, 1 lax and 2 strict, it's added since Firefox 60
+	firefoxSameSiteColumn = "sameSite"
 )
 
-func (f *FirefoxCookie) Extract(_ []byte) error {
-	db, err := sql.Open("sqlite", types.FirefoxCookie.TempFilename())
+// firefoxSameSite are the values of the sameSite column
+var firefoxSameSite = map[int]string{0: "None", 1: "Lax", 2: "Strict"}
+
+func (f *FirefoxCookie) Extract(session *extractor.Session) error {
+	db, err := sql.Open("sqlite", types.FirefoxCookie.DSN())
 	if err != nil {
 		return err
 	}
-	defer os.Remove(types.FirefoxCookie.TempFilename())
+	defer types.FirefoxCookie.RemoveTemp()
 	defer db.Close()
 
-	rows, err := db.Query(queryFirefoxCookie)
+	originColumn, partitionedColumn := "''", "0"
+	if ok, err := sqliteutil.ColumnExists(db, "moz_cookies", firefoxOriginColumn); err == nil && ok {
+		originColumn = firefoxOriginColumn
+	}
+	if ok, err := sqliteutil.ColumnExists(db, "moz_cookies", firefoxPartitionedColumn); err == nil && ok {
+		partitionedColumn = firefoxPartitionedColumn
+	}
+	sameSiteColumn := "-1"
+	if ok, err := sqliteutil.ColumnExists(db, "moz_cookies", firefoxSameSiteColumn); err == nil && ok {
+		sameSiteColumn = firefoxSameSiteColumn
+	}
+	query, args := extractor.FilterHost(fmt.Sprintf(queryFirefoxCookie, extractor.ValueColumn("value"), extractor.SizeColumn("value"),
+		originColumn, partitionedColumn, sameSiteColumn), "host")
+	rows, err := db.Query(extractor.LimitQuery(query), args...)
 	if err != nil {
 		return err
 	}
 	defer rows.Close()
+	// the cookies keep their own copy of containers.json, the containers item removes its copy
+	containers := container.Names(types.FirefoxCookieContainer.TempFilename())
+	defer types.FirefoxCookieContainer.RemoveTemp()
 	for rows.Next() {
 		var (
-			name, value, host, path string
-			isSecure, isHTTPOnly    int
-			creationTime, expiry    int64
+			name, value, host, path, originAttributes string
+			creationTime, expiry                      int64
+			valueSize                                 int
+			// the flags are NULL in the rows written by some old versions and tools, they're
+			// exported as false instead of dropping the cookie.
+			isSecure, isHTTPOnly, isPartitioned sql.NullInt64
+			sameSite                            sql.NullInt64
 		)
-		if err = rows.Scan(&name, &value, &host, &path, &creationTime, &expiry, &isSecure, &isHTTPOnly); err != nil {
+		if err = rows.Scan(&name, &value, &valueSize, &host, &path, &creationTime, &expiry, &isSecure, &isHTTPOnly, &originAttributes, &isPartitioned, &sameSite); err != nil {
 			log.Errorf("scan firefox cookie error: %v", err)
+			continue
 		}
 		*f = append(*f, cookie{
-			KeyName:    name,
-			Host:       host,
-			Path:       path,
-			IsSecure:   typeutil.IntToBool(isSecure),
-			IsHTTPOnly: typeutil.IntToBool(isHTTPOnly),
-			CreateDate: typeutil.TimeStamp(creationTime / 1000000),
-			ExpireDate: typeutil.TimeStamp(expiry),
-			Value:      value,
+			KeyName:       name,
+			Host:          host,
+			Path:          path,
+			IsSecure:      typeutil.IntToBool(isSecure.Int64),
+			IsHTTPOnly:    typeutil.IntToBool(isHTTPOnly.Int64),
+			CreateDate:    typeutil.TimeStamp(creationTime / 1000000),
+			ExpireDate:    typeutil.TimeStamp(expiry),
+			Value:         extractor.TruncateValue(value, valueSize),
+			DecryptMethod: crypto.MethodPlaintext,
+			PartitionKey:  firefoxPartitionKey(originAttributes),
+			IsPartitioned: typeutil.IntToBool(isPartitioned.Int64),
+			Container:     firefoxContainer(originAttributes, containers),
+			SameSite:      firefoxSameSite[firefoxSameSiteValue(sameSite)],
 		})
 	}
 
-	sort.Slice(*f, func(i, j int) bool {
-		return (*f)[i].CreateDate.After((*f)[j].CreateDate)
-	})
+	var duplicates int
+	*f, duplicates = dedupeCookies(*f)
+	session.CountDuplicates(duplicates)
+	sortCookies(*f)
 	return nil
 }
 
+// firefoxSameSiteValue returns the sameSite of the row, a NULL one is unknown like a missing column
+func firefoxSameSiteValue(v sql.NullInt64) int {
+	if !v.Valid {
+		return -1
+	}
+	return int(v.Int64)
+}
+
+// firefoxPartitionKey returns the top-level site of the partitionKey in originAttributes,
+// e.g. ^partitionKey=%28https%2Cexample.com%29 is https://example.com,
+// it's formatted the same as top_frame_site_key of Chromium.
+func firefoxPartitionKey(originAttributes string) string {
+	attrs, err := url.ParseQuery(strings.TrimPrefix(originAttributes, "^"))
+	if err != nil {
+		return ""
+	}
+	key := strings.Trim(attrs.Get("partitionKey"), "()")
+	if key == "" {
+		return ""
+	}
+	// (scheme,host[,port][,f]), f is set for foreign ancestor contexts
+	parts := strings.Split(key, ",")
+	if len(parts) < 2 {
+		return key
+	}
+	site := parts[0] + "://" + parts[1]
+	if len(parts) > 2 && parts[2] != "f" {
+		site += ":" + parts[2]
+	}
+	return site
+}
+
+// firefoxContainer returns the name of the container of the userContextId in originAttributes,
+// e.g. ^userContextId=2 is Work, the id is returned for the containers without a name.
+func firefoxContainer(originAttributes string, names map[int64]string) string {
+	attrs, err := url.ParseQuery(strings.TrimPrefix(originAttributes, "^"))
+	if err != nil {
+		return ""
+	}
+	id, err := strconv.ParseInt(attrs.Get("userContextId"), 10, 64)
+	if err != nil || id == 0 {
+		return ""
+	}
+	if name, ok := names[id]; ok && name != "" {
+		return name
+	}
+	return strconv.FormatInt(id, 10)
+}
+
 func (f *FirefoxCookie) Name() string {
 	return "cookie"
 }
@@ -0,0 +1,286 @@
+package cookie
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/crypto"
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+const createChromiumCookieTable = `CREATE TABLE cookies (creation_utc INTEGER NOT NULL, host_key TEXT NOT NULL, top_frame_site_key TEXT NOT NULL, name TEXT NOT NULL, value TEXT NOT NULL, encrypted_value BLOB NOT NULL, path TEXT NOT NULL, expires_utc INTEGER NOT NULL, is_secure INTEGER NOT NULL, is_httponly INTEGER NOT NULL, last_access_utc INTEGER NOT NULL, has_expires INTEGER NOT NULL, is_persistent INTEGER NOT NULL)`
+
+type chromiumCookieRow struct {
+	host, partition, name, path string
+	creation                    int64
+}
+
+// createChromiumCookieDB writes a Chromium Cookies database to the temp file of the item
+func createChromiumCookieDB(t *testing.T, schema string, rows []chromiumCookieRow) {
+	t.Helper()
+	db, err := sql.Open("sqlite", types.ChromiumCookie.TempFilename())
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(schema)
+	require.NoError(t, err)
+	for _, r := range rows {
+		if schema == createChromiumCookieTable {
+			_, err = db.Exec(`INSERT INTO cookies VALUES (?, ?, ?, ?, '', x'', ?, 0, 1, 1, 0, 0, 0)`, r.creation, r.host, r.partition, r.name, r.path)
+		} else {
+			_, err = db.Exec(`INSERT INTO cookies VALUES (?, ?, ?, '', x'', ?, 0, 1, 1, 0, 0, 0)`, r.creation, r.host, r.name, r.path)
+		}
+		require.NoError(t, err)
+	}
+}
+
+func TestChromiumCookie_ExtractPartitioned(t *testing.T) {
+	createChromiumCookieDB(t, createChromiumCookieTable, []chromiumCookieRow{
+		{host: ".example.com", partition: "https://site.test", name: "a", path: "/", creation: 3},
+		{host: ".example.com", partition: "", name: "a", path: "/", creation: 2},
+	})
+
+	var c ChromiumCookie
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 2)
+	assert.Equal(t, "", c[0].PartitionKey)
+	assert.Equal(t, "https://site.test", c[1].PartitionKey)
+	assert.True(t, c[0].IsSecure)
+	assert.True(t, c[0].IsHTTPOnly)
+}
+
+func TestChromiumCookie_ExtractDeterministic(t *testing.T) {
+	rows := []chromiumCookieRow{
+		{host: "b.example.com", name: "session", path: "/", creation: 1},
+		{host: "a.example.com", name: "token", path: "/", creation: 1},
+		{host: "a.example.com", name: "id", path: "/app", creation: 1},
+		{host: "a.example.com", name: "id", path: "/", creation: 1},
+	}
+	var outputs [][]byte
+	for _, order := range [][]int{{0, 1, 2, 3}, {3, 2, 1, 0}} {
+		shuffled := make([]chromiumCookieRow, 0, len(rows))
+		for _, i := range order {
+			shuffled = append(shuffled, rows[i])
+		}
+		createChromiumCookieDB(t, createChromiumCookieTable, shuffled)
+
+		var c ChromiumCookie
+		require.NoError(t, c.Extract(nil))
+		b, err := json.Marshal(c)
+		require.NoError(t, err)
+		outputs = append(outputs, b)
+	}
+	assert.Equal(t, string(outputs[0]), string(outputs[1]))
+
+	var c []cookie
+	require.NoError(t, json.Unmarshal(outputs[0], &c))
+	assert.Equal(t, []string{"a.example.com/", "a.example.com/app", "a.example.com/", "b.example.com/"},
+		[]string{c[0].Host + c[0].Path, c[1].Host + c[1].Path, c[2].Host + c[2].Path, c[3].Host + c[3].Path})
+	assert.Equal(t, "id", c[0].KeyName)
+	assert.Equal(t, "token", c[2].KeyName)
+}
+
+func TestChromiumCookie_ExtractLegacySchema(t *testing.T) {
+	const legacySchema = `CREATE TABLE cookies (creation_utc INTEGER NOT NULL, host_key TEXT NOT NULL, name TEXT NOT NULL, value TEXT NOT NULL, encrypted_value BLOB NOT NULL, path TEXT NOT NULL, expires_utc INTEGER NOT NULL, is_secure INTEGER NOT NULL, is_httponly INTEGER NOT NULL, last_access_utc INTEGER NOT NULL, has_expires INTEGER NOT NULL, is_persistent INTEGER NOT NULL)`
+	createChromiumCookieDB(t, legacySchema, []chromiumCookieRow{
+		{host: ".example.com", name: "a", path: "/", creation: 1},
+	})
+
+	var c ChromiumCookie
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 1)
+	assert.Equal(t, "", c[0].PartitionKey)
+}
+
+const (
+	createFirefoxCookieTable       = `CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, originAttributes TEXT NOT NULL DEFAULT '', name TEXT, value TEXT, host TEXT, path TEXT, expiry INTEGER, lastAccessed INTEGER, creationTime INTEGER, isSecure INTEGER, isHttpOnly INTEGER, inBrowserElement INTEGER DEFAULT 0, sameSite INTEGER DEFAULT 0, schemeMap INTEGER DEFAULT 0, isPartitionedAttributeSet INTEGER DEFAULT 0)`
+	createLegacyFirefoxCookieTable = `CREATE TABLE moz_cookies (id INTEGER PRIMARY KEY, name TEXT, value TEXT, host TEXT, path TEXT, expiry INTEGER, lastAccessed INTEGER, creationTime INTEGER, isSecure INTEGER, isHttpOnly INTEGER)`
+)
+
+func TestFirefoxCookie_ExtractPartitioned(t *testing.T) {
+	db, err := sql.Open("sqlite", types.FirefoxCookie.TempFilename())
+	require.NoError(t, err)
+	_, err = db.Exec(createFirefoxCookieTable)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO moz_cookies (originAttributes, name, value, host, path, expiry, creationTime, isSecure, isHttpOnly, isPartitionedAttributeSet) VALUES
+		('', 'a', 'v1', '.example.com', '/', 0, 1000000, 1, 1, 0),
+		('^partitionKey=%28https%2Csite.test%29', 'a', 'v2', '.example.com', '/', 0, 2000000, 1, 0, 1),
+		('^userContextId=1&partitionKey=%28https%2Cother.test%2C8443%29', 'a', 'v3', '.example.com', '/', 0, 3000000, 0, 0, 0)`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	var f FirefoxCookie
+	require.NoError(t, f.Extract(nil))
+	require.Len(t, f, 3)
+
+	assert.Equal(t, "", f[0].PartitionKey)
+	assert.False(t, f[0].IsPartitioned)
+	assert.Equal(t, "https://other.test:8443", f[1].PartitionKey)
+	assert.False(t, f[1].IsPartitioned)
+	assert.Equal(t, "https://site.test", f[2].PartitionKey)
+	assert.True(t, f[2].IsPartitioned)
+	assert.Equal(t, "v2", f[2].Value)
+}
+
+func TestFirefoxCookie_ExtractContainer(t *testing.T) {
+	containers := `{"identities": [{"userContextId": 2, "public": true, "l10nID": "userContextWork.label"}]}`
+	require.NoError(t, os.WriteFile(types.FirefoxCookieContainer.TempFilename(), []byte(containers), 0o600))
+	db, err := sql.Open("sqlite", types.FirefoxCookie.TempFilename())
+	require.NoError(t, err)
+	_, err = db.Exec(createFirefoxCookieTable)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO moz_cookies (originAttributes, name, value, host, path, expiry, creationTime, isSecure, isHttpOnly) VALUES
+		('', 'a', 'v1', '.a.example.com', '/', 0, 1000000, 0, 0),
+		('^userContextId=2', 'a', 'v2', '.b.example.com', '/', 0, 1000000, 0, 0),
+		('^userContextId=9', 'a', 'v3', '.c.example.com', '/', 0, 1000000, 0, 0)`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	var f FirefoxCookie
+	require.NoError(t, f.Extract(nil))
+	require.Len(t, f, 3)
+	assert.Equal(t, "", f[0].Container)
+	assert.Equal(t, "Work", f[1].Container)
+	assert.Equal(t, "9", f[2].Container)
+	assert.NoFileExists(t, types.FirefoxCookieContainer.TempFilename())
+}
+
+func TestFirefoxCookie_ExtractLegacySchema(t *testing.T) {
+	db, err := sql.Open("sqlite", types.FirefoxCookie.TempFilename())
+	require.NoError(t, err)
+	_, err = db.Exec(createLegacyFirefoxCookieTable)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO moz_cookies (name, value, host, path, expiry, creationTime, isSecure, isHttpOnly) VALUES ('a', 'v', '.example.com', '/', 0, 1000000, 1, 0)`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	var f FirefoxCookie
+	require.NoError(t, f.Extract(nil))
+	require.Len(t, f, 1)
+	assert.Equal(t, "", f[0].PartitionKey)
+	assert.False(t, f[0].IsPartitioned)
+	assert.Equal(t, "v", f[0].Value)
+}
+
+func TestFirefoxCookie_ExtractFlags(t *testing.T) {
+	db, err := sql.Open("sqlite", types.FirefoxCookie.TempFilename())
+	require.NoError(t, err)
+	_, err = db.Exec(createFirefoxCookieTable)
+	require.NoError(t, err)
+	// sameSite and schemeMap are set to values which would flip the flags if they were read by position
+	_, err = db.Exec(`INSERT INTO moz_cookies (name, value, host, path, expiry, creationTime, isSecure, isHttpOnly, sameSite, schemeMap) VALUES
+		('secure', 'v', '.example.com', '/', 0, 4000000, 1, 0, 0, 2),
+		('httponly', 'v', '.example.com', '/', 0, 3000000, 0, 1, 2, 1),
+		('both', 'v', '.example.com', '/', 0, 2000000, 1, 1, 1, 0),
+		('none', 'v', '.example.com', '/', 0, 1000000, 0, 0, 1, 2),
+		('null', 'v', '.example.com', '/', 0, 500000, NULL, 1, 0, 0)`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	var f FirefoxCookie
+	require.NoError(t, f.Extract(nil))
+	require.Len(t, f, 5)
+	flags := make(map[string][2]bool)
+	for _, c := range f {
+		flags[c.KeyName] = [2]bool{c.IsSecure, c.IsHTTPOnly}
+	}
+	assert.Equal(t, [2]bool{true, false}, flags["secure"])
+	assert.Equal(t, [2]bool{false, true}, flags["httponly"])
+	assert.Equal(t, [2]bool{true, true}, flags["both"])
+	assert.Equal(t, [2]bool{false, false}, flags["none"])
+	assert.Equal(t, [2]bool{false, true}, flags["null"])
+}
+
+func TestChromiumCookie_ExtractInPlace(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "Cookies")
+	db, err := sql.Open("sqlite", source)
+	require.NoError(t, err)
+	_, err = db.Exec(createChromiumCookieTable)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO cookies VALUES (1, '.example.com', '', 'a', '', x'', '/', 0, 1, 1, 0, 0, 0)`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	types.SetInPlace(true)
+	types.SetSourcePaths(map[types.DataType]string{types.ChromiumCookie: source})
+	t.Cleanup(func() {
+		types.SetInPlace(false)
+		types.SetSourcePaths(nil)
+	})
+
+	var c ChromiumCookie
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 1)
+	assert.Equal(t, "a", c[0].KeyName)
+	assert.FileExists(t, source)
+}
+
+func TestChromiumCookie_ExtractLargeValue(t *testing.T) {
+	defer extractor.SetMaxValueSize(extractor.DefaultMaxValueSize)
+	extractor.SetMaxValueSize(8)
+	db, err := sql.Open("sqlite", types.ChromiumCookie.TempFilename())
+	require.NoError(t, err)
+	_, err = db.Exec(createChromiumCookieTable)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO cookies VALUES (2, '.example.com', '', 'plain', ?, x'', '/', 0, 1, 1, 0, 0, 0), (1, '.example.com', '', 'encrypted', '', ?, '/', 0, 1, 1, 0, 0, 0)`,
+		strings.Repeat("€", 1<<10), append([]byte("v10"), make([]byte, 1<<20)...))
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	var c ChromiumCookie
+	require.NoError(t, c.Extract(extractor.NewSession(crypto.NewChromiumDecryptor([]byte("0123456789abcdef0123456789abcdef")))))
+	require.Len(t, c, 2)
+	// the plain value is cut at a character, the encrypted one is too big to be decrypted
+	values := map[string]string{c[0].KeyName: c[0].Value, c[1].KeyName: c[1].Value}
+	assert.Equal(t, "€€ (truncated, 3072 bytes)", values["plain"])
+	assert.Equal(t, "(truncated, 1048579 bytes)", values["encrypted"])
+}
+
+func TestFirefoxCookie_ExtractLargeValue(t *testing.T) {
+	defer extractor.SetMaxValueSize(extractor.DefaultMaxValueSize)
+	extractor.SetMaxValueSize(8)
+	db, err := sql.Open("sqlite", types.FirefoxCookie.TempFilename())
+	require.NoError(t, err)
+	_, err = db.Exec(createFirefoxCookieTable)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO moz_cookies (name, value, host, path, expiry, creationTime, isSecure, isHttpOnly) VALUES
+		('big', ?, '.example.com', '/', 0, 1000000, 0, 0)`, strings.Repeat("v", 1<<20))
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	var f FirefoxCookie
+	require.NoError(t, f.Extract(nil))
+	require.Len(t, f, 1)
+	assert.Equal(t, "vvvvvvvv (truncated, 1048576 bytes)", f[0].Value)
+}
+
+func TestChromiumCookie_ExtractPlaintext(t *testing.T) {
+	db, err := sql.Open("sqlite", types.ChromiumCookie.TempFilename())
+	require.NoError(t, err)
+	_, err = db.Exec(createChromiumCookieTable)
+	require.NoError(t, err)
+	// the value column without os_crypt, and an encrypted_value which is the value itself
+	_, err = db.Exec(`INSERT INTO cookies VALUES (1, 'a.example.com', '', 'plain', 'in-value', x'', '/', 0, 0, 0, 0, 0, 0),
+		(2, 'b.example.com', '', 'raw', '', CAST('in-encrypted' AS BLOB), '/', 0, 0, 0, 0, 0, 0),
+		(3, 'c.example.com', '', 'app-bound', '', CAST('v20-not-plaintext' AS BLOB), '/', 0, 0, 0, 0, 0, 0)`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	var c ChromiumCookie
+	require.NoError(t, c.Extract(extractor.NewSession(crypto.NewChromiumDecryptor([]byte("0123456789abcdef0123456789abcdef")))))
+	require.Len(t, c, 3)
+	assert.Equal(t, "in-value", c[0].Value)
+	assert.Equal(t, "in-encrypted", c[1].Value)
+	assert.Equal(t, crypto.MethodPlaintext, c[0].DecryptMethod)
+	assert.Equal(t, crypto.MethodPlaintext, c[1].DecryptMethod)
+	// a value of an unknown version is encrypted, it's never written as plaintext
+	assert.Empty(t, c[2].Value)
+	assert.NotEqual(t, crypto.MethodPlaintext, c[2].DecryptMethod)
+}
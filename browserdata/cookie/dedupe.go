@@ -0,0 +1,42 @@
+package cookie
+
+// dedupe keeps a single cookie of every host, name and path, chromium may have the same
+// cookie twice while the cookie store is being written.
+var dedupe bool
+
+// SetDedupe enables dropping the duplicate cookies, the newest one of every host, name and path is kept
+func SetDedupe(b bool) {
+	dedupe = b
+}
+
+// cookieKey is the identity of a cookie, the partition key and the container are part of it
+// as the browser keeps a cookie of every top-level site or container.
+type cookieKey struct {
+	host, name, path, partition, container string
+}
+
+// dedupeCookies drops the cookies of the same key but the one with the latest CreateDate,
+// the order of the kept cookies is unchanged, it returns the number of the dropped ones.
+func dedupeCookies(c []cookie) ([]cookie, int) {
+	if !dedupe {
+		return c, 0
+	}
+	newest := make(map[cookieKey]int, len(c))
+	for i := range c {
+		key := cookieKey{c[i].Host, c[i].KeyName, c[i].Path, c[i].PartitionKey, c[i].Container}
+		if j, ok := newest[key]; !ok || c[i].CreateDate.After(c[j].CreateDate) {
+			newest[key] = i
+		}
+	}
+	if len(newest) == len(c) {
+		return c, 0
+	}
+	kept := make([]cookie, 0, len(newest))
+	for i := range c {
+		key := cookieKey{c[i].Host, c[i].KeyName, c[i].Path, c[i].PartitionKey, c[i].Container}
+		if newest[key] == i {
+			kept = append(kept, c[i])
+		}
+	}
+	return kept, len(c) - len(kept)
+}
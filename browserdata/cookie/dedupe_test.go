@@ -0,0 +1,41 @@
+package cookie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
+)
+
+func TestDedupeCookies_Disabled(t *testing.T) {
+	c := []cookie{{Host: "a", KeyName: "k"}, {Host: "a", KeyName: "k"}}
+	kept, duplicates := dedupeCookies(c)
+	assert.Len(t, kept, 2)
+	assert.Zero(t, duplicates)
+}
+
+func TestChromiumCookie_ExtractDedupe(t *testing.T) {
+	createChromiumCookieDB(t, createChromiumCookieTable, []chromiumCookieRow{
+		{host: ".example.com", name: "sid", path: "/", creation: 1},
+		{host: ".example.com", name: "sid", path: "/", creation: 3},
+		{host: ".example.com", name: "sid", path: "/", creation: 2},
+		{host: ".example.com", partition: "https://site.test", name: "sid", path: "/", creation: 1},
+		{host: ".example.com", name: "sid", path: "/app", creation: 1},
+	})
+
+	SetDedupe(true)
+	defer SetDedupe(false)
+	session := extractor.NewSession(nil)
+	var c ChromiumCookie
+	require.NoError(t, c.Extract(session))
+	require.Len(t, c, 3)
+	assert.Equal(t, 2, session.Duplicates())
+	assert.Equal(t, "/", c[0].Path)
+	assert.Equal(t, "", c[0].PartitionKey)
+	assert.Equal(t, typeutil.TimeEpoch(3), c[0].CreateDate)
+	assert.Equal(t, "https://site.test", c[1].PartitionKey)
+	assert.Equal(t, "/app", c[2].Path)
+}
@@ -0,0 +1,27 @@
+package cookie
+
+import (
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// includeSubdomains groups the cookies by their registrable domain instead of the exact host,
+// eg: the cookies of a.example.com and b.example.com are written together under example.com.
+var includeSubdomains bool
+
+// SetIncludeSubdomains enables grouping the cookies by registrable domain
+func SetIncludeSubdomains(b bool) {
+	includeSubdomains = b
+}
+
+// registrableDomain returns the domain one label below the public suffix of the host,
+// eg: example.co.uk for .a.example.co.uk, the host is returned if it has none, eg: localhost.
+func registrableDomain(host string) string {
+	host = strings.ToLower(strings.TrimPrefix(host, "."))
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
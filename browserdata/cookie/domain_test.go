@@ -0,0 +1,42 @@
+package cookie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistrableDomain(t *testing.T) {
+	assert.Equal(t, "example.com", registrableDomain(".a.example.com"))
+	assert.Equal(t, "example.co.uk", registrableDomain("b.EXAMPLE.co.uk"))
+	assert.Equal(t, "localhost", registrableDomain("localhost"))
+}
+
+func TestSortCookies_IncludeSubdomains(t *testing.T) {
+	newCookies := func() []cookie {
+		return []cookie{
+			{Host: "b.example.com", KeyName: "b"},
+			{Host: "www.other.org", KeyName: "w"},
+			{Host: ".a.example.com", KeyName: "a"},
+			{Host: "example.com", KeyName: "e"},
+		}
+	}
+	hosts := func(c []cookie) []string {
+		var h []string
+		for _, v := range c {
+			h = append(h, v.Host)
+		}
+		return h
+	}
+
+	c := newCookies()
+	sortCookies(c)
+	assert.Equal(t, []string{".a.example.com", "b.example.com", "example.com", "www.other.org"}, hosts(c))
+
+	SetIncludeSubdomains(true)
+	t.Cleanup(func() { SetIncludeSubdomains(false) })
+	c = newCookies()
+	c = append(c, cookie{Host: "a.example.net", KeyName: "n"})
+	sortCookies(c)
+	assert.Equal(t, []string{".a.example.com", "b.example.com", "example.com", "a.example.net", "www.other.org"}, hosts(c))
+}
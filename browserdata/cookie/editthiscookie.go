@@ -0,0 +1,71 @@
+package cookie
+
+import (
+	"strings"
+)
+
+// editThisCookie is a cookie in the json imported and exported by the EditThisCookie and
+// Cookie-Editor extensions, so the cookies can be imported into another browser.
+type editThisCookie struct {
+	Domain         string  `json:"domain"`
+	ExpirationDate float64 `json:"expirationDate,omitempty"`
+	HostOnly       bool    `json:"hostOnly"`
+	HTTPOnly       bool    `json:"httpOnly"`
+	Name           string  `json:"name"`
+	Path           string  `json:"path"`
+	SameSite       string  `json:"sameSite"`
+	Secure         bool    `json:"secure"`
+	Session        bool    `json:"session"`
+	StoreID        string  `json:"storeId"`
+	Value          string  `json:"value"`
+	ID             int     `json:"id"`
+}
+
+// editThisCookieSameSite are the sameSite values of chrome.cookies used by the extensions
+var editThisCookieSameSite = map[string]string{
+	"None":   "no_restriction",
+	"Lax":    "lax",
+	"Strict": "strict",
+}
+
+// EditThisCookie returns the cookies in the json format of the EditThisCookie extension
+func (c *ChromiumCookie) EditThisCookie() any {
+	return editThisCookies(*c)
+}
+
+// EditThisCookie returns the cookies in the json format of the EditThisCookie extension
+func (f *FirefoxCookie) EditThisCookie() any {
+	return editThisCookies(*f)
+}
+
+// editThisCookies converts the cookies, the session cookies have no expirationDate and the
+// cookies of a host without the leading dot are host-only.
+func editThisCookies(cookies []cookie) []editThisCookie {
+	out := make([]editThisCookie, 0, len(cookies))
+	for i, c := range cookies {
+		e := editThisCookie{
+			Domain:   c.Host,
+			HostOnly: !strings.HasPrefix(c.Host, "."),
+			HTTPOnly: c.IsHTTPOnly,
+			Name:     c.KeyName,
+			Path:     c.Path,
+			SameSite: "unspecified",
+			Secure:   c.IsSecure,
+			// the cookies of the default store, the incognito store is never saved
+			StoreID: "0",
+			Value:   c.Value,
+			ID:      i + 1,
+		}
+		if v, ok := editThisCookieSameSite[c.SameSite]; ok {
+			e.SameSite = v
+		}
+		// cookies without expiry have a zero or 1601-01-01 expire date
+		if c.ExpireDate.Unix() > 0 {
+			e.ExpirationDate = float64(c.ExpireDate.UnixNano()) / 1e9
+		} else {
+			e.Session = true
+		}
+		out = append(out, e)
+	}
+	return out
+}
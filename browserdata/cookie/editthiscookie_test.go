@@ -0,0 +1,34 @@
+package cookie
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
+)
+
+func TestEditThisCookie(t *testing.T) {
+	expire := time.Date(2030, 1, 2, 3, 4, 5, 500000000, time.UTC)
+	c := ChromiumCookie{
+		{
+			Host: ".example.com", Path: "/", KeyName: "sid", Value: "abc",
+			IsSecure: true, IsHTTPOnly: true, HasExpire: true, ExpireDate: expire, SameSite: "Lax",
+		},
+		{
+			Host: "app.example.com", Path: "/app", KeyName: "session", Value: "1",
+			ExpireDate: typeutil.TimeEpoch(0),
+		},
+	}
+	assert.Equal(t, []editThisCookie{
+		{
+			Domain: ".example.com", ExpirationDate: 1893553445.5, HTTPOnly: true, Name: "sid", Path: "/",
+			SameSite: "lax", Secure: true, StoreID: "0", Value: "abc", ID: 1,
+		},
+		{
+			Domain: "app.example.com", HostOnly: true, Name: "session", Path: "/app",
+			SameSite: "unspecified", Session: true, StoreID: "0", Value: "1", ID: 2,
+		},
+	}, c.EditThisCookie())
+}
@@ -0,0 +1,102 @@
+package cookie
+
+import (
+	"strings"
+	"time"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func init() {
+	extractor.RegisterExtractor(types.ChromiumExtensionCookie, extractor.Info{
+		Description: "the cookies of the extensions",
+		Sources:     []string{"Extension Cookies"},
+	}, func() extractor.Extractor {
+		return new(ChromiumExtensionCookie)
+	})
+}
+
+// ChromiumExtensionCookie is the cookies of the Extension Cookies database, the store of the
+// cookies set by the pages of the extensions, it has the schema and encryption of Cookies.
+type ChromiumExtensionCookie []extensionCookie
+
+type extensionCookie struct {
+	// ExtensionID is the id of the extension owning the cookie, empty if it's unknown
+	ExtensionID   string
+	Host          string
+	Path          string
+	KeyName       string
+	Value         string
+	DecryptMethod string `csv:"-"`
+	IsSecure      bool
+	IsHTTPOnly    bool
+	HasExpire     bool
+	IsPersistent  bool
+	CreateDate    time.Time
+	ExpireDate    time.Time
+	PartitionKey  string
+	IsPartitioned bool
+	SameSite      string
+}
+
+// extensionScheme is the scheme of the extension pages, eg: chrome-extension://<id>/popup.html
+const extensionScheme = "chrome-extension://"
+
+func (c *ChromiumExtensionCookie) Extract(session *extractor.Session) error {
+	cookies, err := extractChromiumCookies(types.ChromiumExtensionCookie, session)
+	for _, v := range cookies {
+		*c = append(*c, extensionCookie{
+			ExtensionID:   extensionID(v.Host, v.PartitionKey),
+			Host:          v.Host,
+			Path:          v.Path,
+			KeyName:       v.KeyName,
+			Value:         v.Value,
+			DecryptMethod: v.DecryptMethod,
+			IsSecure:      v.IsSecure,
+			IsHTTPOnly:    v.IsHTTPOnly,
+			HasExpire:     v.HasExpire,
+			IsPersistent:  v.IsPersistent,
+			CreateDate:    v.CreateDate,
+			ExpireDate:    v.ExpireDate,
+			PartitionKey:  v.PartitionKey,
+			IsPartitioned: v.IsPartitioned,
+			SameSite:      v.SameSite,
+		})
+	}
+	return err
+}
+
+// extensionID returns the extension of the cookie, the host of the cookies of an extension
+// origin is the extension id, the partitioned cookies of its frames are keyed by its origin.
+func extensionID(host, partitionKey string) string {
+	if strings.HasPrefix(partitionKey, extensionScheme) {
+		id, _, _ := strings.Cut(strings.TrimPrefix(partitionKey, extensionScheme), "/")
+		return id
+	}
+	if isExtensionID(host) {
+		return host
+	}
+	return ""
+}
+
+// isExtensionID reports whether s is an extension id, 32 letters from a to p
+func isExtensionID(s string) bool {
+	if len(s) != 32 {
+		return false
+	}
+	for _, r := range s {
+		if r < 'a' || r > 'p' {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *ChromiumExtensionCookie) Name() string {
+	return "extensionCookie"
+}
+
+func (c *ChromiumExtensionCookie) Len() int {
+	return len(*c)
+}
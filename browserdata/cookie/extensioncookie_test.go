@@ -0,0 +1,39 @@
+package cookie
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func TestChromiumExtensionCookie_Extract(t *testing.T) {
+	const id = "nkbihfbeogaeaoehlefnkodbefgpgknn"
+	db, err := sql.Open("sqlite", types.ChromiumExtensionCookie.TempFilename())
+	require.NoError(t, err)
+	_, err = db.Exec(createChromiumCookieTable)
+	require.NoError(t, err)
+	for _, r := range []chromiumCookieRow{
+		{host: id, name: "token", path: "/"},
+		{host: ".example.com", partition: extensionScheme + id, name: "sid", path: "/"},
+		{host: ".example.com", name: "other", path: "/"},
+	} {
+		_, err = db.Exec(`INSERT INTO cookies VALUES (?, ?, ?, ?, '', x'', ?, 0, 1, 1, 0, 0, 0)`, r.creation, r.host, r.partition, r.name, r.path)
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.Close())
+
+	var c ChromiumExtensionCookie
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 3)
+	assert.Equal(t, "other", c[0].KeyName)
+	assert.Empty(t, c[0].ExtensionID)
+	assert.Equal(t, "sid", c[1].KeyName)
+	assert.Equal(t, id, c[1].ExtensionID)
+	assert.Equal(t, "token", c[2].KeyName)
+	assert.Equal(t, id, c[2].ExtensionID)
+	assert.NoFileExists(t, types.ChromiumExtensionCookie.TempFilename())
+}
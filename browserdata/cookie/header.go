@@ -0,0 +1,77 @@
+package cookie
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/moond4rk/hackbrowserdata/log"
+)
+
+// SetCookieHeaders returns every cookie as a Set-Cookie header line
+func (c *ChromiumCookie) SetCookieHeaders() []string {
+	return setCookieHeaders(*c)
+}
+
+// SetCookieHeaders returns every cookie as a Set-Cookie header line
+func (f *FirefoxCookie) SetCookieHeaders() []string {
+	return setCookieHeaders(*f)
+}
+
+// setCookieHeaders returns the headers of the cookies, the cookies with a control character in
+// their name or value, eg: a value which failed to decrypt, are skipped since a CR or LF would
+// split the header.
+func setCookieHeaders(cookies []cookie) []string {
+	headers := make([]string, 0, len(cookies))
+	for _, c := range cookies {
+		if hasControlChar(c.KeyName) || hasControlChar(c.Value) {
+			log.Warnf("skip the Set-Cookie header of cookie %s of %s, it has a control character", c.KeyName, c.Host)
+			continue
+		}
+		headers = append(headers, c.setCookieHeader())
+	}
+	return headers
+}
+
+// setCookieHeader formats the cookie as a Set-Cookie header, session cookies have no Expires.
+// The domain cookies have a host with a leading dot, the host-only ones are written without
+// Domain so they aren't sent to the subdomains.
+func (c cookie) setCookieHeader() string {
+	var b strings.Builder
+	b.WriteString("Set-Cookie: ")
+	b.WriteString(c.KeyName)
+	b.WriteByte('=')
+	b.WriteString(c.Value)
+	if host := strings.TrimPrefix(c.Host, "."); host != "" && host != c.Host {
+		b.WriteString("; Domain=")
+		b.WriteString(host)
+	}
+	if c.Path != "" {
+		b.WriteString("; Path=")
+		b.WriteString(c.Path)
+	}
+	// cookies without expiry have a zero or 1601-01-01 expire date
+	if c.ExpireDate.Unix() > 0 {
+		b.WriteString("; Expires=")
+		b.WriteString(c.ExpireDate.UTC().Format(http.TimeFormat))
+	}
+	if c.IsSecure {
+		b.WriteString("; Secure")
+	}
+	if c.IsHTTPOnly {
+		b.WriteString("; HttpOnly")
+	}
+	if c.IsPartitioned {
+		b.WriteString("; Partitioned")
+	}
+	return b.String()
+}
+
+// hasControlChar reports whether s has an ASCII control character, which a header can't hold
+func hasControlChar(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] == 0x7f {
+			return true
+		}
+	}
+	return false
+}
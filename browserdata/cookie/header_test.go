@@ -0,0 +1,52 @@
+package cookie
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
+)
+
+func TestSetCookieHeaders(t *testing.T) {
+	expire := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	c := ChromiumCookie{
+		{
+			Host: ".example.com", Path: "/", KeyName: "sid", Value: "abc",
+			IsSecure: true, IsHTTPOnly: true, HasExpire: true, ExpireDate: expire,
+		},
+		{
+			Host: "app.example.com", Path: "/app", KeyName: "session", Value: "1",
+			ExpireDate: typeutil.TimeEpoch(0),
+		},
+		{
+			Host: ".cdn.example.com", Path: "/", KeyName: "chips", Value: "2",
+			IsSecure: true, IsPartitioned: true, PartitionKey: "https://site.test",
+		},
+		{
+			Host: "evil.example.com", Path: "/", KeyName: "raw", Value: "x\r\nSet-Cookie: injected=1",
+		},
+	}
+	headers := c.SetCookieHeaders()
+	assert.Equal(t, []string{
+		"Set-Cookie: sid=abc; Domain=example.com; Path=/; Expires=Wed, 02 Jan 2030 03:04:05 GMT; Secure; HttpOnly",
+		"Set-Cookie: session=1; Path=/app",
+		"Set-Cookie: chips=2; Domain=cdn.example.com; Path=/; Secure; Partitioned",
+	}, headers, "the host-only cookie has no Domain, the value with CR LF is skipped")
+
+	// the headers are valid for net/http
+	resp := http.Response{Header: http.Header{}}
+	for _, h := range headers {
+		resp.Header.Add("Set-Cookie", h[len("Set-Cookie: "):])
+	}
+	parsed := resp.Cookies()
+	require.Len(t, parsed, 3)
+	assert.Equal(t, "sid", parsed[0].Name)
+	assert.True(t, parsed[0].Secure)
+	assert.True(t, parsed[0].HttpOnly)
+	assert.Equal(t, expire, parsed[0].Expires)
+	assert.True(t, parsed[1].Expires.IsZero())
+}
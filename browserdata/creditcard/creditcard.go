@@ -2,28 +2,35 @@ package creditcard
 
 import (
 	"database/sql"
-	"os"
+	"time"
 
 	// import sqlite3 driver
 	_ "modernc.org/sqlite"
 
-	"github.com/moond4rk/hackbrowserdata/crypto"
 	"github.com/moond4rk/hackbrowserdata/extractor"
 	"github.com/moond4rk/hackbrowserdata/log"
 	"github.com/moond4rk/hackbrowserdata/types"
 )
 
 func init() {
-	extractor.RegisterExtractor(types.ChromiumCreditCard, func() extractor.Extractor {
+	extractor.RegisterExtractor(types.ChromiumCreditCard, extractor.Info{
+		Description: "the saved credit cards",
+		Sources:     []string{"Web Data"},
+	}, func() extractor.Extractor {
 		return new(ChromiumCreditCard)
 	})
-	extractor.RegisterExtractor(types.YandexCreditCard, func() extractor.Extractor {
+	extractor.RegisterExtractor(types.YandexCreditCard, extractor.Info{
+		Description: "the saved credit cards",
+		Sources:     []string{"Ya Credit Cards"},
+	}, func() extractor.Extractor {
 		return new(YandexCreditCard)
 	})
 }
 
 type ChromiumCreditCard []card
 
+// card is a credit card saved by autofill, UseCount, LastUsed and Modified are only set
+// with the usage metadata.
 type card struct {
 	GUID            string
 	Name            string
@@ -32,25 +39,29 @@ type card struct {
 	CardNumber      string
 	Address         string
 	NickName        string
+	UseCount        int
+	LastUsed        time.Time
+	Modified        time.Time
 }
 
 const (
 	queryChromiumCredit = `SELECT guid, name_on_card, expiration_month, expiration_year, card_number_encrypted, billing_address_id, nickname FROM credit_cards`
 )
 
-func (c *ChromiumCreditCard) Extract(masterKey []byte) error {
-	db, err := sql.Open("sqlite", types.ChromiumCreditCard.TempFilename())
+func (c *ChromiumCreditCard) Extract(session *extractor.Session) error {
+	db, err := sql.Open("sqlite", types.ChromiumCreditCard.DSN())
 	if err != nil {
 		return err
 	}
-	defer os.Remove(types.ChromiumCreditCard.TempFilename())
+	defer types.ChromiumCreditCard.RemoveTemp()
 	defer db.Close()
 
-	rows, err := db.Query(queryChromiumCredit)
+	rows, err := db.Query(extractor.LimitQuery(queryChromiumCredit))
 	if err != nil {
 		return err
 	}
 	defer rows.Close()
+	decryptor := session.Decryptor()
 	for rows.Next() {
 		var (
 			name, month, year, guid, address, nickname string
@@ -68,11 +79,8 @@ func (c *ChromiumCreditCard) Extract(masterKey []byte) error {
 			NickName:        nickname,
 		}
 		if len(encryptValue) > 0 {
-			if len(masterKey) == 0 {
-				value, err = crypto.DecryptWithDPAPI(encryptValue)
-			} else {
-				value, err = crypto.DecryptWithChromium(masterKey, encryptValue)
-			}
+			value, err = decryptor.Decrypt(encryptValue)
+			session.CountDecrypt(err)
 			if err != nil {
 				log.Errorf("decrypt chromium credit card error: %v", err)
 			}
@@ -81,6 +89,7 @@ func (c *ChromiumCreditCard) Extract(masterKey []byte) error {
 		ccInfo.CardNumber = string(value)
 		*c = append(*c, ccInfo)
 	}
+	addUsage(db, *c)
 	return nil
 }
 
@@ -94,18 +103,19 @@ func (c *ChromiumCreditCard) Len() int {
 
 type YandexCreditCard []card
 
-func (c *YandexCreditCard) Extract(masterKey []byte) error {
-	db, err := sql.Open("sqlite", types.YandexCreditCard.TempFilename())
+func (c *YandexCreditCard) Extract(session *extractor.Session) error {
+	db, err := sql.Open("sqlite", types.YandexCreditCard.DSN())
 	if err != nil {
 		return err
 	}
-	defer os.Remove(types.YandexCreditCard.TempFilename())
+	defer types.YandexCreditCard.RemoveTemp()
 	defer db.Close()
-	rows, err := db.Query(queryChromiumCredit)
+	rows, err := db.Query(extractor.LimitQuery(queryChromiumCredit))
 	if err != nil {
 		return err
 	}
 	defer rows.Close()
+	decryptor := session.Decryptor()
 	for rows.Next() {
 		var (
 			name, month, year, guid, address, nickname string
@@ -123,11 +133,8 @@ func (c *YandexCreditCard) Extract(masterKey []byte) error {
 			NickName:        nickname,
 		}
 		if len(encryptValue) > 0 {
-			if len(masterKey) == 0 {
-				value, err = crypto.DecryptWithDPAPI(encryptValue)
-			} else {
-				value, err = crypto.DecryptWithChromium(masterKey, encryptValue)
-			}
+			value, err = decryptor.Decrypt(encryptValue)
+			session.CountDecrypt(err)
 			if err != nil {
 				log.Errorf("decrypt chromium credit card error: %v", err)
 			}
@@ -135,6 +142,7 @@ func (c *YandexCreditCard) Extract(masterKey []byte) error {
 		ccInfo.CardNumber = string(value)
 		*c = append(*c, ccInfo)
 	}
+	addUsage(db, *c)
 	return nil
 }
 
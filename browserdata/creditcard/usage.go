@@ -0,0 +1,79 @@
+package creditcard
+
+import (
+	"database/sql"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/log"
+	"github.com/moond4rk/hackbrowserdata/utils/sqliteutil"
+	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
+)
+
+// withUsage adds how often and when every card was filled by autofill to the output,
+// it shows which cards are actually used besides the saved ones.
+var withUsage bool
+
+// SetUsage enables the usage metadata of the credit cards
+func SetUsage(b bool) {
+	withUsage = b
+}
+
+// @https://source.chromium.org/chromium/chromium/src/+/main:components/autofill/core/browser/webdata/payments/payments_autofill_table.cc
+const queryChromiumCardUsage = `SELECT guid, use_count, use_date, date_modified FROM credit_cards`
+
+// usageColumns are added to credit_cards over time, the usage is skipped if any is absent
+var usageColumns = []string{"use_count", "use_date", "date_modified"}
+
+// addUsage sets the use count, last use and modified dates of the cards by guid, the cards
+// are left untouched if the tables or columns don't exist.
+func addUsage(db *sql.DB, cards []card) {
+	if !withUsage || len(cards) == 0 {
+		return
+	}
+	index := make(map[string]int, len(cards))
+	for i, c := range cards {
+		index[c.GUID] = i
+	}
+	for _, column := range usageColumns {
+		if ok, err := sqliteutil.ColumnExists(db, "credit_cards", column); err != nil || !ok {
+			log.Debugf("skip credit card usage, column %s does not exist", column)
+			return
+		}
+	}
+	if err := readUsage(db, cards, index); err != nil {
+		log.Debugf("query credit card usage error: %v", err)
+	}
+}
+
+func readUsage(db *sql.DB, cards []card, index map[string]int) error {
+	rows, err := db.Query(extractor.LimitQuery(queryChromiumCardUsage))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			guid              string
+			useCount          int
+			useDate, modified int64
+		)
+		if err := rows.Scan(&guid, &useCount, &useDate, &modified); err != nil {
+			log.Warnf("scan credit card usage error: %v", err)
+			continue
+		}
+		i, ok := index[guid]
+		if !ok {
+			continue
+		}
+		c := &cards[i]
+		c.UseCount = useCount
+		// the dates are seconds since the unix epoch, 0 is never
+		if useDate > 0 {
+			c.LastUsed = typeutil.TimeStamp(useDate)
+		}
+		if modified > 0 {
+			c.Modified = typeutil.TimeStamp(modified)
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,58 @@
+package creditcard
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+const createCreditCardTable = `CREATE TABLE credit_cards (guid VARCHAR PRIMARY KEY, name_on_card VARCHAR, expiration_month INTEGER, expiration_year INTEGER, card_number_encrypted BLOB, date_modified INTEGER NOT NULL DEFAULT 0, origin VARCHAR DEFAULT '', use_count INTEGER NOT NULL DEFAULT 0, use_date INTEGER NOT NULL DEFAULT 0, billing_address_id VARCHAR, nickname VARCHAR)`
+
+func createCreditCardDB(t *testing.T, schema string, inserts ...string) {
+	t.Helper()
+	db, err := sql.Open("sqlite", types.ChromiumCreditCard.TempFilename())
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(schema)
+	require.NoError(t, err)
+	for _, insert := range inserts {
+		_, err = db.Exec(insert)
+		require.NoError(t, err)
+	}
+}
+
+func TestChromiumCreditCard_ExtractUsage(t *testing.T) {
+	insert := `INSERT INTO credit_cards (guid, name_on_card, expiration_month, expiration_year, card_number_encrypted, date_modified, use_count, use_date, billing_address_id, nickname)
+		VALUES ('a', 'Alice', 1, 2030, x'', 1700000000, 7, 1710000000, '', ''), ('b', 'Bob', 2, 2031, x'', 1700000000, 0, 0, '', '')`
+
+	createCreditCardDB(t, createCreditCardTable, insert)
+	var c ChromiumCreditCard
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 2)
+	assert.Zero(t, c[0].UseCount, "the usage is only read when it's enabled")
+
+	defer SetUsage(false)
+	SetUsage(true)
+	createCreditCardDB(t, createCreditCardTable, insert)
+	c = nil
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 2)
+	assert.Equal(t, 7, c[0].UseCount)
+	assert.True(t, c[0].LastUsed.Equal(time.Unix(1710000000, 0)))
+	assert.True(t, c[0].Modified.Equal(time.Unix(1700000000, 0)))
+	assert.Zero(t, c[1].UseCount)
+	assert.True(t, c[1].LastUsed.IsZero(), "a card never used has no last use")
+
+	// the old schemas without the usage columns are extracted without the usage
+	createCreditCardDB(t, `CREATE TABLE credit_cards (guid VARCHAR PRIMARY KEY, name_on_card VARCHAR, expiration_month INTEGER, expiration_year INTEGER, card_number_encrypted BLOB, billing_address_id VARCHAR, nickname VARCHAR)`,
+		`INSERT INTO credit_cards VALUES ('a', 'Alice', 1, 2030, x'', '', '')`)
+	c = nil
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 1)
+	assert.Zero(t, c[0].UseCount)
+}
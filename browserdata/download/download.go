@@ -2,7 +2,6 @@ package download
 
 import (
 	"database/sql"
-	"os"
 	"sort"
 	"strings"
 	"time"
@@ -17,10 +16,16 @@ import (
 )
 
 func init() {
-	extractor.RegisterExtractor(types.ChromiumDownload, func() extractor.Extractor {
+	extractor.RegisterExtractor(types.ChromiumDownload, extractor.Info{
+		Description: "the downloaded files",
+		Sources:     []string{"History"},
+	}, func() extractor.Extractor {
 		return new(ChromiumDownload)
 	})
-	extractor.RegisterExtractor(types.FirefoxDownload, func() extractor.Extractor {
+	extractor.RegisterExtractor(types.FirefoxDownload, extractor.Info{
+		Description: "the downloaded files",
+		Sources:     []string{"places.sqlite"},
+	}, func() extractor.Extractor {
 		return new(FirefoxDownload)
 	})
 }
@@ -40,14 +45,14 @@ const (
 	queryChromiumDownload = `SELECT target_path, tab_url, total_bytes, start_time, end_time, mime_type FROM downloads`
 )
 
-func (c *ChromiumDownload) Extract(_ []byte) error {
-	db, err := sql.Open("sqlite", types.ChromiumDownload.TempFilename())
+func (c *ChromiumDownload) Extract(_ *extractor.Session) error {
+	db, err := sql.Open("sqlite", types.ChromiumDownload.DSN())
 	if err != nil {
 		return err
 	}
-	defer os.Remove(types.ChromiumDownload.TempFilename())
+	defer types.ChromiumDownload.RemoveTemp()
 	defer db.Close()
-	rows, err := db.Query(queryChromiumDownload)
+	rows, err := db.Query(extractor.LimitQuery(queryChromiumDownload))
 	if err != nil {
 		return err
 	}
@@ -91,19 +96,19 @@ const (
 	closeJournalMode     = `PRAGMA journal_mode=off`
 )
 
-func (f *FirefoxDownload) Extract(_ []byte) error {
-	db, err := sql.Open("sqlite", types.FirefoxDownload.TempFilename())
+func (f *FirefoxDownload) Extract(_ *extractor.Session) error {
+	db, err := sql.Open("sqlite", types.FirefoxDownload.DSN())
 	if err != nil {
 		return err
 	}
-	defer os.Remove(types.FirefoxDownload.TempFilename())
+	defer types.FirefoxDownload.RemoveTemp()
 	defer db.Close()
 
 	_, err = db.Exec(closeJournalMode)
 	if err != nil {
 		log.Errorf("close journal mode error: %v", err)
 	}
-	rows, err := db.Query(queryFirefoxDownload)
+	rows, err := db.Query(extractor.LimitQuery(queryFirefoxDownload))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,52 @@
+package browserdata
+
+// The exit codes of a run for the scripts, the empty items are a success.
+const (
+	ExitSuccess = 0
+	// ExitPartial is some browsers, items or values failed, the others were extracted
+	ExitPartial = 2
+	// ExitFailure is no browser or profile was found, or they all failed, or every decryption failed
+	ExitFailure = 3
+	// ExitNotFound is no browser, no profile of the profile glob or no usable profile archive was found
+	ExitNotFound = ExitFailure
+)
+
+// RunSummary aggregates the stats of the browsers of a run to its exit code
+type RunSummary struct {
+	Browsers       int
+	FailedBrowsers int
+	Items          int
+	FailedItems    int
+	Decrypted      int
+	DecryptFailed  int
+}
+
+// Add adds the stats of a browser whose data was extracted and written
+func (s *RunSummary) Add(stats Stats) {
+	s.Browsers++
+	s.Items += len(stats.Items)
+	s.FailedItems += stats.Failed
+	s.Decrypted += stats.Decrypted
+	s.DecryptFailed += stats.DecryptFailed
+}
+
+// AddFailed adds a browser whose data couldn't be extracted or written
+func (s *RunSummary) AddFailed() {
+	s.Browsers++
+	s.FailedBrowsers++
+}
+
+// ExitCode returns the exit code of the run
+func (s *RunSummary) ExitCode() int {
+	switch {
+	case s.Browsers == 0, s.FailedBrowsers == s.Browsers:
+		return ExitFailure
+	case s.Items > 0 && s.FailedItems == s.Items:
+		return ExitFailure
+	case s.Decrypted == 0 && s.DecryptFailed > 0:
+		return ExitFailure
+	case s.FailedBrowsers > 0, s.FailedItems > 0, s.DecryptFailed > 0:
+		return ExitPartial
+	}
+	return ExitSuccess
+}
@@ -0,0 +1,42 @@
+package browserdata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunSummary_ExitCode(t *testing.T) {
+	ok := Stats{Items: []ItemStats{{Name: "password", Decrypted: 2}, {Name: "history"}}, Decrypted: 2}
+	empty := Stats{Items: []ItemStats{{Name: "history"}}}
+	partial := Stats{Items: []ItemStats{{Name: "password"}, {Name: "history", Error: "locked"}}, Failed: 1}
+	undecrypted := Stats{Items: []ItemStats{{Name: "password", DecryptFailed: 3}}, DecryptFailed: 3}
+
+	tests := []struct {
+		name   string
+		stats  []Stats
+		failed int
+		want   int
+	}{
+		{"no browser", nil, 0, ExitFailure},
+		{"success", []Stats{ok}, 0, ExitSuccess},
+		{"legitimately empty", []Stats{empty}, 0, ExitSuccess},
+		{"failed item", []Stats{ok, partial}, 0, ExitPartial},
+		{"failed browser", []Stats{ok}, 1, ExitPartial},
+		{"some decryption failed", []Stats{ok, undecrypted}, 0, ExitPartial},
+		{"all browsers failed", nil, 2, ExitFailure},
+		{"all decryption failed", []Stats{undecrypted}, 0, ExitFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s RunSummary
+			for _, stats := range tt.stats {
+				s.Add(stats)
+			}
+			for i := 0; i < tt.failed; i++ {
+				s.AddFailed()
+			}
+			assert.Equal(t, tt.want, s.ExitCode())
+		})
+	}
+}
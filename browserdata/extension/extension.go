@@ -2,7 +2,6 @@ package extension
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/tidwall/gjson"
@@ -14,10 +13,16 @@ import (
 )
 
 func init() {
-	extractor.RegisterExtractor(types.ChromiumExtension, func() extractor.Extractor {
+	extractor.RegisterExtractor(types.ChromiumExtension, extractor.Info{
+		Description: "the installed extensions",
+		Sources:     []string{"Secure Preferences"},
+	}, func() extractor.Extractor {
 		return new(ChromiumExtension)
 	})
-	extractor.RegisterExtractor(types.FirefoxExtension, func() extractor.Extractor {
+	extractor.RegisterExtractor(types.FirefoxExtension, extractor.Info{
+		Description: "the installed extensions",
+		Sources:     []string{"extensions.json"},
+	}, func() extractor.Extractor {
 		return new(FirefoxExtension)
 	})
 }
@@ -34,12 +39,12 @@ type extension struct {
 	HomepageURL string
 }
 
-func (c *ChromiumExtension) Extract(_ []byte) error {
+func (c *ChromiumExtension) Extract(_ *extractor.Session) error {
 	extensionFile, err := fileutil.ReadFile(types.ChromiumExtension.TempFilename())
 	if err != nil {
 		return err
 	}
-	defer os.Remove(types.ChromiumExtension.TempFilename())
+	defer types.ChromiumExtension.RemoveTemp()
 
 	result, err := parseChromiumExtensions(extensionFile)
 	if err != nil {
@@ -68,6 +73,9 @@ func parseChromiumExtensions(content string) ([]*extension, error) {
 	var c []*extension
 
 	settings.ForEach(func(id, ext gjson.Result) bool {
+		if extractor.ReachedMaxRows(len(c)) {
+			return false
+		}
 		location := ext.Get("location")
 		if !location.Exists() {
 			return true
@@ -123,14 +131,17 @@ type FirefoxExtension []*extension
 
 var lang = language.Und
 
-func (f *FirefoxExtension) Extract(_ []byte) error {
+func (f *FirefoxExtension) Extract(_ *extractor.Session) error {
 	s, err := fileutil.ReadFile(types.FirefoxExtension.TempFilename())
 	if err != nil {
 		return err
 	}
-	_ = os.Remove(types.FirefoxExtension.TempFilename())
+	types.FirefoxExtension.RemoveTemp()
 	j := gjson.Parse(s)
 	for _, v := range j.Get("addons").Array() {
+		if extractor.ReachedMaxRows(len(*f)) {
+			break
+		}
 		// https://searchfox.org/mozilla-central/source/toolkit/mozapps/extensions/internal/XPIDatabase.jsm#157
 		if v.Get("location").String() != "app-profile" {
 			continue
@@ -0,0 +1,119 @@
+package browserdata
+
+import (
+	"io"
+	"sync"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+)
+
+// Formatter writes the records of an item in an output format, it's registered by the name
+// of the format flag, eg: csv.
+type Formatter interface {
+	// Ext is the extension of the output files, eg: csv
+	Ext() string
+	// Supports reports whether the item can be written in the format
+	Supports(data extractor.Extractor) bool
+	// Format writes the records of the item to w
+	Format(data extractor.Extractor, w io.Writer) error
+}
+
+// csvFormat is the format of the unknown format flags
+const csvFormat = "csv"
+
+var (
+	formattersMu sync.RWMutex
+	formatters   = map[string]Formatter{
+		csvFormat:            csvFormatter{},
+		"json":               jsonFormatter{},
+		jsonlFormat:          jsonlFormatter{},
+		headerFormat:         headerFormatter{},
+		editThisCookieFormat: editThisCookieFormatter{},
+	}
+)
+
+// RegisterFormatter registers the formatter of the format name, a registered name is replaced
+func RegisterFormatter(name string, f Formatter) {
+	formattersMu.Lock()
+	defer formattersMu.Unlock()
+	formatters[name] = f
+}
+
+// formatterOf returns the formatter of the format name, the unknown names are csv
+func formatterOf(name string) Formatter {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+	if f, ok := formatters[name]; ok {
+		return f
+	}
+	return formatters[csvFormat]
+}
+
+type csvFormatter struct{}
+
+func (csvFormatter) Ext() string { return "csv" }
+
+func (csvFormatter) Supports(extractor.Extractor) bool { return true }
+
+func (csvFormatter) Format(data extractor.Extractor, w io.Writer) error {
+	return WriteCSV(w, data)
+}
+
+type jsonFormatter struct{}
+
+func (jsonFormatter) Ext() string { return "json" }
+
+func (jsonFormatter) Supports(extractor.Extractor) bool { return true }
+
+func (jsonFormatter) Format(data extractor.Extractor, w io.Writer) error {
+	return WriteJSON(w, data)
+}
+
+// jsonlFormatter writes the records as json lines, the json format which can be appended to
+type jsonlFormatter struct{}
+
+func (jsonlFormatter) Ext() string { return "jsonl" }
+
+func (jsonlFormatter) Supports(extractor.Extractor) bool { return true }
+
+func (jsonlFormatter) Format(data extractor.Extractor, w io.Writer) error {
+	return WriteJSONL(w, data)
+}
+
+// headerFormatter writes the cookies as Set-Cookie header lines, the selected fields are checked
+// but the lines keep the fields of the header
+type headerFormatter struct{}
+
+func (headerFormatter) Ext() string { return "txt" }
+
+func (headerFormatter) Supports(data extractor.Extractor) bool {
+	_, ok := data.(setCookieHeaders)
+	return ok
+}
+
+func (headerFormatter) Format(data extractor.Extractor, w io.Writer) error {
+	rows, err := itemRecords(data)
+	if err != nil {
+		return err
+	}
+	return writeHeaders(rows, w)
+}
+
+// editThisCookieFormatter writes the cookies as the json of the EditThisCookie extension, like the
+// headers the selected fields are checked only
+type editThisCookieFormatter struct{}
+
+func (editThisCookieFormatter) Ext() string { return "editthiscookie.json" }
+
+func (editThisCookieFormatter) Supports(data extractor.Extractor) bool {
+	_, ok := data.(editThisCookies)
+	return ok
+}
+
+func (editThisCookieFormatter) Format(data extractor.Extractor, w io.Writer) error {
+	rows, err := itemRecords(data)
+	if err != nil {
+		return err
+	}
+	return writeEditThisCookie(rows, w)
+}
@@ -0,0 +1,53 @@
+package browserdata
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+// countFormatter writes the name and the number of records of the item
+type countFormatter struct{}
+
+func (countFormatter) Ext() string { return "count" }
+
+func (countFormatter) Supports(extractor.Extractor) bool { return true }
+
+func (countFormatter) Format(data extractor.Extractor, w io.Writer) error {
+	_, err := fmt.Fprintf(w, "%s %d\n", data.Name(), data.Len())
+	return err
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	RegisterFormatter("count", countFormatter{})
+	defer func() {
+		formattersMu.Lock()
+		delete(formatters, "count")
+		formattersMu.Unlock()
+	}()
+
+	dir := t.TempDir()
+	bd := &BrowserData{
+		extractors: map[types.DataType]extractor.Extractor{
+			types.ChromiumCookie: newTestCookies(t, "abc"),
+		},
+	}
+	require.NoError(t, bd.Output(dir, "chrome_default", "count,csv"))
+
+	data, err := os.ReadFile(filepath.Join(dir, "chrome_default_cookie.count"))
+	require.NoError(t, err)
+	assert.Equal(t, "cookie 1\n", string(data))
+	assert.FileExists(t, filepath.Join(dir, "chrome_default_cookie.csv"))
+}
+
+func TestFormatterOf_Unknown(t *testing.T) {
+	assert.Equal(t, "csv", formatterOf("xml").Ext())
+}
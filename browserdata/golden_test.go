@@ -0,0 +1,56 @@
+package browserdata
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// assertGolden compares the output with testdata/<name>, run the tests with -update to rewrite it
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	golden := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.MkdirAll("testdata", 0o750))
+		require.NoError(t, os.WriteFile(golden, got, 0o600))
+	}
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestWriteGolden(t *testing.T) {
+	c := newTestCookies(t, "abc", `quoted "value", with comma`)
+	tests := []struct {
+		golden string
+		write  func(w *bytes.Buffer, data extractor.Extractor) error
+	}{
+		{"cookie.csv", func(w *bytes.Buffer, data extractor.Extractor) error { return WriteCSV(w, data) }},
+		{"cookie.json", func(w *bytes.Buffer, data extractor.Extractor) error { return WriteJSON(w, data) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, tt.write(&buf, c))
+			assertGolden(t, tt.golden, buf.Bytes())
+		})
+	}
+}
+
+func TestWriteJSON_Legacy(t *testing.T) {
+	SetLegacyJSON(true)
+	t.Cleanup(func() { SetLegacyJSON(false) })
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, newTestCookies(t, "abc", "def")))
+	assertGolden(t, "cookie_legacy.json", buf.Bytes())
+}
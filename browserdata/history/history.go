@@ -2,7 +2,6 @@ package history
 
 import (
 	"database/sql"
-	"os"
 	"sort"
 	"time"
 
@@ -16,10 +15,16 @@ import (
 )
 
 func init() {
-	extractor.RegisterExtractor(types.ChromiumHistory, func() extractor.Extractor {
+	extractor.RegisterExtractor(types.ChromiumHistory, extractor.Info{
+		Description: "the visited urls",
+		Sources:     []string{"History", "Archived History"},
+	}, func() extractor.Extractor {
 		return new(ChromiumHistory)
 	})
-	extractor.RegisterExtractor(types.FirefoxHistory, func() extractor.Extractor {
+	extractor.RegisterExtractor(types.FirefoxHistory, extractor.Info{
+		Description: "the visited urls",
+		Sources:     []string{"places.sqlite"},
+	}, func() extractor.Extractor {
 		return new(FirefoxHistory)
 	})
 }
@@ -37,20 +42,55 @@ const (
 	queryChromiumHistory = `SELECT url, title, visit_count, last_visit_time FROM urls`
 )
 
-func (c *ChromiumHistory) Extract(_ []byte) error {
-	db, err := sql.Open("sqlite", types.ChromiumHistory.TempFilename())
+func (c *ChromiumHistory) Extract(_ *extractor.Session) error {
+	defer types.ChromiumHistory.RemoveTemp()
+	defer types.ChromiumArchivedHistory.RemoveTemp()
+	if err := c.extractFrom(types.ChromiumHistory.DSN(), nil); err != nil {
+		return err
+	}
+	// the versions before Chrome 37 moved the visits older than 90 days to Archived History
+	if types.ChromiumArchivedHistory.Exists() && !extractor.ReachedMaxRows(len(*c)) {
+		// the max rows apply to the merged history, the archived urls fill the rows left
+		seen := make(map[visitKey]bool, len(*c))
+		for _, h := range *c {
+			seen[visitKey{h.URL, h.LastVisitTime}] = true
+		}
+		if err := c.extractFrom(types.ChromiumArchivedHistory.DSN(), seen); err != nil {
+			log.Warnf("extract chromium archived history error: %v", err)
+		}
+	}
+	sort.SliceStable(*c, func(i, j int) bool {
+		return (*c)[i].VisitCount > (*c)[j].VisitCount
+	})
+	return nil
+}
+
+// visitKey is the key of a history record, the records of the archived history which are in
+// the live history too are skipped.
+type visitKey struct {
+	url       string
+	visitTime time.Time
+}
+
+// extractFrom appends the urls of the history database until the max rows, the ones in seen
+// are skipped and don't count to the max rows.
+func (c *ChromiumHistory) extractFrom(dsn string, seen map[visitKey]bool) error {
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return err
 	}
-	defer os.Remove(types.ChromiumHistory.TempFilename())
 	defer db.Close()
 
-	rows, err := db.Query(queryChromiumHistory)
+	query := queryChromiumHistory
+	if seen == nil {
+		query = extractor.LimitQuery(query)
+	}
+	rows, err := db.Query(query)
 	if err != nil {
 		return err
 	}
 	defer rows.Close()
-	for rows.Next() {
+	for rows.Next() && !extractor.ReachedMaxRows(len(*c)) {
 		var (
 			url, title    string
 			visitCount    int
@@ -65,12 +105,15 @@ func (c *ChromiumHistory) Extract(_ []byte) error {
 			VisitCount:    visitCount,
 			LastVisitTime: typeutil.TimeEpoch(lastVisitTime),
 		}
+		if key := (visitKey{data.URL, data.LastVisitTime}); seen != nil {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
 		*c = append(*c, data)
 	}
-	sort.Slice(*c, func(i, j int) bool {
-		return (*c)[i].VisitCount > (*c)[j].VisitCount
-	})
-	return nil
+	return rows.Err()
 }
 
 func (c *ChromiumHistory) Name() string {
@@ -88,12 +131,12 @@ const (
 	closeJournalMode    = `PRAGMA journal_mode=off`
 )
 
-func (f *FirefoxHistory) Extract(_ []byte) error {
-	db, err := sql.Open("sqlite", types.FirefoxHistory.TempFilename())
+func (f *FirefoxHistory) Extract(_ *extractor.Session) error {
+	db, err := sql.Open("sqlite", types.FirefoxHistory.DSN())
 	if err != nil {
 		return err
 	}
-	defer os.Remove(types.FirefoxHistory.TempFilename())
+	defer types.FirefoxHistory.RemoveTemp()
 	defer db.Close()
 
 	_, err = db.Exec(closeJournalMode)
@@ -101,7 +144,7 @@ func (f *FirefoxHistory) Extract(_ []byte) error {
 		return err
 	}
 	defer db.Close()
-	rows, err := db.Query(queryFirefoxHistory)
+	rows, err := db.Query(extractor.LimitQuery(queryFirefoxHistory))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,60 @@
+package history
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func createChromiumHistoryDB(t *testing.T, item types.DataType, rows string) {
+	t.Helper()
+	db, err := sql.Open("sqlite", item.TempFilename())
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE urls (id INTEGER PRIMARY KEY, url LONGVARCHAR, title LONGVARCHAR, visit_count INTEGER DEFAULT 0 NOT NULL, last_visit_time INTEGER NOT NULL)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO urls (url, title, visit_count, last_visit_time) VALUES ` + rows)
+	require.NoError(t, err)
+}
+
+func TestChromiumHistory_ExtractArchived(t *testing.T) {
+	createChromiumHistoryDB(t, types.ChromiumHistory, `('https://example.com/', 'Example', 3, 13300000000000000)`)
+	createChromiumHistoryDB(t, types.ChromiumArchivedHistory, `('https://example.com/', 'Example', 3, 13300000000000000),
+		('https://old.test/', 'Old', 5, 13100000000000000)`)
+
+	var c ChromiumHistory
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 2)
+	assert.Equal(t, "https://old.test/", c[0].URL)
+	assert.Equal(t, "https://example.com/", c[1].URL)
+	assert.NoFileExists(t, types.ChromiumArchivedHistory.TempFilename())
+}
+
+func TestChromiumHistory_ExtractArchivedMaxRows(t *testing.T) {
+	extractor.SetMaxRows(2)
+	defer extractor.SetMaxRows(0)
+	createChromiumHistoryDB(t, types.ChromiumHistory, `('https://example.com/', 'Example', 3, 13300000000000000)`)
+	createChromiumHistoryDB(t, types.ChromiumArchivedHistory, `('https://old.test/', 'Old', 5, 13100000000000000),
+		('https://older.test/', 'Older', 1, 13000000000000000),
+		('https://example.com/', 'Example', 3, 13300000000000000)`)
+
+	var c ChromiumHistory
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 2)
+	assert.Equal(t, "https://old.test/", c[0].URL)
+	assert.Equal(t, "https://example.com/", c[1].URL)
+}
+
+func TestChromiumHistory_ExtractNoArchived(t *testing.T) {
+	createChromiumHistoryDB(t, types.ChromiumHistory, `('https://example.com/', 'Example', 3, 13300000000000000)`)
+
+	var c ChromiumHistory
+	require.NoError(t, c.Extract(nil))
+	assert.Len(t, c, 1)
+	assert.NoFileExists(t, types.ChromiumArchivedHistory.TempFilename())
+}
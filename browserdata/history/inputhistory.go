@@ -0,0 +1,82 @@
+package history
+
+import (
+	"database/sql"
+	"time"
+
+	// import sqlite3 driver
+	_ "modernc.org/sqlite"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/log"
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
+)
+
+func init() {
+	extractor.RegisterExtractor(types.FirefoxInputHistory, extractor.Info{
+		Description: "the text typed in the address bar",
+		Sources:     []string{"places.sqlite"},
+	}, func() extractor.Extractor {
+		return new(FirefoxInputHistory)
+	})
+}
+
+// FirefoxInputHistory is the text typed in the address bar and the url picked for it, the
+// address bar suggests the url the next time the text is typed. The use count decays over
+// time, so a fragment used recently often ranks first.
+type FirefoxInputHistory []inputHistory
+
+type inputHistory struct {
+	Input         string
+	URL           string
+	Title         string
+	UseCount      float64
+	LastVisitTime time.Time
+}
+
+// @https://searchfox.org/mozilla-central/source/toolkit/components/places/nsPlacesTables.h
+const queryFirefoxInputHistory = `SELECT i.input, p.url, COALESCE(p.title, ''), i.use_count, COALESCE(p.last_visit_date, 0)
+	FROM moz_inputhistory i JOIN moz_places p ON p.id = i.place_id ORDER BY i.use_count DESC, i.input`
+
+func (f *FirefoxInputHistory) Extract(_ *extractor.Session) error {
+	db, err := sql.Open("sqlite", types.FirefoxInputHistory.DSN())
+	if err != nil {
+		return err
+	}
+	defer types.FirefoxInputHistory.RemoveTemp()
+	defer db.Close()
+
+	rows, err := db.Query(extractor.LimitQuery(queryFirefoxInputHistory))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			input, url, title string
+			useCount          float64
+			visitDate         int64
+		)
+		if err := rows.Scan(&input, &url, &title, &useCount, &visitDate); err != nil {
+			log.Warnf("scan firefox input history error: %v", err)
+			continue
+		}
+		*f = append(*f, inputHistory{
+			Input:         input,
+			URL:           url,
+			Title:         title,
+			UseCount:      useCount,
+			LastVisitTime: typeutil.TimeStamp(visitDate / 1000000),
+		})
+	}
+	return rows.Err()
+}
+
+func (f *FirefoxInputHistory) Name() string {
+	return "inputHistory"
+}
+
+func (f *FirefoxInputHistory) Len() int {
+	return len(*f)
+}
@@ -0,0 +1,38 @@
+package history
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func TestFirefoxInputHistory_Extract(t *testing.T) {
+	db, err := sql.Open("sqlite", types.FirefoxInputHistory.TempFilename())
+	require.NoError(t, err)
+	for _, stmt := range []string{
+		`CREATE TABLE moz_places (id INTEGER PRIMARY KEY, url LONGVARCHAR, title LONGVARCHAR, last_visit_date INTEGER)`,
+		`CREATE TABLE moz_inputhistory (place_id INTEGER NOT NULL, input LONGVARCHAR NOT NULL, use_count INTEGER, PRIMARY KEY (place_id, input))`,
+		`INSERT INTO moz_places VALUES (1, 'https://github.com/', 'GitHub', 1700000000000000), (2, 'https://mail.example.com/', NULL, NULL)`,
+		`INSERT INTO moz_inputhistory VALUES (1, 'gi', 0.5), (1, 'git', 2.25), (2, 'mail', 1)`,
+	} {
+		_, err = db.Exec(stmt)
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.Close())
+
+	var f FirefoxInputHistory
+	require.NoError(t, f.Extract(nil))
+	require.Len(t, f, 3)
+	assert.Equal(t, "git", f[0].Input)
+	assert.Equal(t, "https://github.com/", f[0].URL)
+	assert.Equal(t, "GitHub", f[0].Title)
+	assert.InDelta(t, 2.25, f[0].UseCount, 0.001)
+	assert.Equal(t, int64(1700000000), f[0].LastVisitTime.Unix())
+	assert.Equal(t, "mail", f[1].Input)
+	assert.Equal(t, "", f[1].Title)
+	assert.Equal(t, "gi", f[2].Input)
+}
@@ -8,13 +8,27 @@
 package browserdata
 
 import (
+	_ "github.com/moond4rk/hackbrowserdata/browserdata/affiliation"
 	_ "github.com/moond4rk/hackbrowserdata/browserdata/bookmark"
+	_ "github.com/moond4rk/hackbrowserdata/browserdata/container"
 	_ "github.com/moond4rk/hackbrowserdata/browserdata/cookie"
 	_ "github.com/moond4rk/hackbrowserdata/browserdata/creditcard"
 	_ "github.com/moond4rk/hackbrowserdata/browserdata/download"
 	_ "github.com/moond4rk/hackbrowserdata/browserdata/extension"
 	_ "github.com/moond4rk/hackbrowserdata/browserdata/history"
 	_ "github.com/moond4rk/hackbrowserdata/browserdata/localstorage"
+	_ "github.com/moond4rk/hackbrowserdata/browserdata/mostvisited"
+	_ "github.com/moond4rk/hackbrowserdata/browserdata/networkstate"
 	_ "github.com/moond4rk/hackbrowserdata/browserdata/password"
+	_ "github.com/moond4rk/hackbrowserdata/browserdata/privacysandbox"
+	_ "github.com/moond4rk/hackbrowserdata/browserdata/pushsubscription"
+	_ "github.com/moond4rk/hackbrowserdata/browserdata/rewards"
+	_ "github.com/moond4rk/hackbrowserdata/browserdata/safebrowsing"
+	_ "github.com/moond4rk/hackbrowserdata/browserdata/sessions"
 	_ "github.com/moond4rk/hackbrowserdata/browserdata/sessionstorage"
+	_ "github.com/moond4rk/hackbrowserdata/browserdata/settings"
+	_ "github.com/moond4rk/hackbrowserdata/browserdata/siteengagement"
+	_ "github.com/moond4rk/hackbrowserdata/browserdata/storagequota"
+	_ "github.com/moond4rk/hackbrowserdata/browserdata/syncdata"
+	_ "github.com/moond4rk/hackbrowserdata/browserdata/webapp"
 )
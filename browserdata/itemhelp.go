@@ -0,0 +1,130 @@
+package browserdata
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+// ItemHelp describes an item of the output, its fields and the files it's read from
+type ItemHelp struct {
+	Name        string
+	Description string
+	Fields      []string
+	Sources     []ItemSource
+}
+
+// ItemSource is the files an item is read from for a browser engine, and the browsers of the
+// engine which have the item
+type ItemSource struct {
+	Engine   string
+	Files    []string
+	Browsers []string
+}
+
+// itemEngines are the engines of the data types, by the prefix of their name
+var itemEngines = []string{"Chromium", "Yandex", "Brave", "Firefox"}
+
+// ItemsHelp returns the help of every registered item ordered by name, the description and
+// the files are the ones the item is registered with, the fields are the ones of its records.
+// browsers returns the browsers which have a data type, nil lists no browser.
+func ItemsHelp(browsers func(types.DataType) []string) []ItemHelp {
+	byName := make(map[string]*ItemHelp)
+	for _, dt := range extractor.RegisteredTypes() {
+		info, _ := extractor.ExtractorInfo(dt)
+		source := extractor.CreateExtractor(dt)
+		name := source.Name()
+		help, ok := byName[name]
+		if !ok {
+			help = &ItemHelp{Name: name, Description: info.Description}
+			byName[name] = help
+		}
+		help.Fields = appendFields(help.Fields, recordFields(source))
+		if len(info.Sources) == 0 {
+			// the item is registered for the engine but has no file to read yet
+			continue
+		}
+		s := ItemSource{Engine: engineOf(dt), Files: info.Sources}
+		if browsers != nil {
+			s.Browsers = browsers(dt)
+		}
+		help.Sources = append(help.Sources, s)
+	}
+	helps := make([]ItemHelp, 0, len(byName))
+	for _, h := range byName {
+		helps = append(helps, *h)
+	}
+	sort.Slice(helps, func(i, j int) bool {
+		return helps[i].Name < helps[j].Name
+	})
+	return helps
+}
+
+// WriteItemsHelp writes the help of every item to w, eg:
+//
+//	password: the saved logins
+//	  fields:   username, password, loginurl
+//	  chromium: Login Data
+//	  browsers: chrome, edge
+func WriteItemsHelp(w io.Writer, browsers func(types.DataType) []string) error {
+	for _, h := range ItemsHelp(browsers) {
+		if _, err := fmt.Fprintf(w, "%s: %s\n  %-9s %s\n", h.Name, h.Description, "fields:", strings.Join(h.Fields, ", ")); err != nil {
+			return err
+		}
+		for _, s := range h.Sources {
+			if _, err := fmt.Fprintf(w, "  %-9s %s\n", s.Engine+":", strings.Join(s.Files, ", ")); err != nil {
+				return err
+			}
+			if len(s.Browsers) == 0 {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "  %-9s %s\n", "browsers:", strings.Join(s.Browsers, ", ")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// recordFields returns the names of the fields of the records of the item, as --fields takes them
+func recordFields(source extractor.Extractor) []string {
+	t := reflect.TypeOf(source)
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return exportedFields(t)
+}
+
+// appendFields appends the fields not in fields yet, the items of the engines share most fields
+func appendFields(fields, more []string) []string {
+	for _, f := range more {
+		found := false
+		for _, existing := range fields {
+			if existing == f {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+func engineOf(dt types.DataType) string {
+	for _, engine := range itemEngines {
+		if strings.HasPrefix(dt.String(), engine) {
+			return strings.ToLower(engine)
+		}
+	}
+	return "unknown"
+}
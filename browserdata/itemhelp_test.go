@@ -0,0 +1,39 @@
+package browserdata
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func TestItemsHelp(t *testing.T) {
+	browsers := func(dt types.DataType) []string {
+		if dt == types.ChromiumCookie {
+			return []string{"chrome", "edge"}
+		}
+		return nil
+	}
+	var cookie *ItemHelp
+	for _, h := range ItemsHelp(browsers) {
+		if h.Name == "cookie" {
+			h := h
+			cookie = &h
+		}
+	}
+	require.NotNil(t, cookie)
+	assert.Equal(t, "the cookies of the sites", cookie.Description)
+	assert.Contains(t, cookie.Fields, "host")
+	assert.Contains(t, cookie.Fields, "value")
+	assert.Contains(t, cookie.Sources, ItemSource{Engine: "chromium", Files: []string{"Network/Cookies", "Cookies"}, Browsers: []string{"chrome", "edge"}})
+	assert.Contains(t, cookie.Sources, ItemSource{Engine: "firefox", Files: []string{"cookies.sqlite", "containers.json"}})
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteItemsHelp(&buf, browsers))
+	assert.Contains(t, buf.String(), "cookie: the cookies of the sites\n  fields:   host, path, keyname, value")
+	assert.Contains(t, buf.String(), "  chromium: Network/Cookies, Cookies\n  browsers: chrome, edge\n  firefox:  cookies.sqlite, containers.json\n")
+	assert.NotContains(t, buf.String(), types.UnsupportedItem)
+}
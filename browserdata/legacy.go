@@ -0,0 +1,102 @@
+package browserdata
+
+import (
+	"reflect"
+)
+
+// legacyJSON writes the json in the layout of HackBrowserData 0.3, for the scripts built on it.
+var legacyJSON bool
+
+// SetLegacyJSON enables the 0.3 json layout
+func SetLegacyJSON(b bool) {
+	legacyJSON = b
+}
+
+// legacyField is a field of the 0.3 layout, Name is the current field and Legacy its old name
+type legacyField struct {
+	Name   string
+	Legacy string
+}
+
+// legacyFields are the fields of the items in the 0.3 layout, in their old order:
+//
+//	password:   UserName, Password, LoginURL as LoginUrl, CreateDate
+//	cookie:     Host, Path, KeyName, Value, IsSecure, IsHTTPOnly, HasExpire, IsPersistent, CreateDate, ExpireDate
+//	history:    Title, URL as Url, VisitCount, LastVisitTime
+//	download:   TargetPath, URL as Url, TotalBytes, StartTime, EndTime, MimeType
+//	bookmark:   ID, Name, Type, URL, DateAdded
+//	creditcard: GUID, Name, ExpirationYear, ExpirationMonth, CardNumber
+//
+// The fields added since are dropped, the cookies are grouped by their host in an object,
+// and the items which didn't exist in 0.3 are written in the current layout.
+var legacyFields = map[string][]legacyField{
+	"password": {
+		{"UserName", "UserName"}, {"Password", "Password"}, {"LoginURL", "LoginUrl"}, {"CreateDate", "CreateDate"},
+	},
+	"cookie": {
+		{"Host", "Host"}, {"Path", "Path"}, {"KeyName", "KeyName"}, {"Value", "Value"},
+		{"IsSecure", "IsSecure"}, {"IsHTTPOnly", "IsHTTPOnly"}, {"HasExpire", "HasExpire"},
+		{"IsPersistent", "IsPersistent"}, {"CreateDate", "CreateDate"}, {"ExpireDate", "ExpireDate"},
+	},
+	"history": {
+		{"Title", "Title"}, {"URL", "Url"}, {"VisitCount", "VisitCount"}, {"LastVisitTime", "LastVisitTime"},
+	},
+	"download": {
+		{"TargetPath", "TargetPath"}, {"URL", "Url"}, {"TotalBytes", "TotalBytes"},
+		{"StartTime", "StartTime"}, {"EndTime", "EndTime"}, {"MimeType", "MimeType"},
+	},
+	"bookmark": {
+		{"ID", "ID"}, {"Name", "Name"}, {"Type", "Type"}, {"URL", "URL"}, {"DateAdded", "DateAdded"},
+	},
+	"creditcard": {
+		{"GUID", "GUID"}, {"Name", "Name"}, {"ExpirationYear", "ExpirationYear"},
+		{"ExpirationMonth", "ExpirationMonth"}, {"CardNumber", "CardNumber"},
+	},
+}
+
+// legacyGroupBy is the field the records of the item are grouped by in the 0.3 layout
+var legacyGroupBy = map[string]string{
+	"cookie": "Host",
+}
+
+// legacyRecords returns the records of the item in the 0.3 layout
+func legacyRecords(item string, rows reflect.Value) any {
+	if !rows.IsValid() {
+		return nil
+	}
+	mapping, ok := legacyFields[item]
+	elemType, isStruct := recordType(rows)
+	if !ok || !isStruct {
+		return rows.Interface()
+	}
+
+	fields := make([]viewField, 0, len(mapping))
+	for _, f := range mapping {
+		// the field isn't there if it's not selected by --fields
+		sf, ok := elemType.FieldByName(f.Name)
+		if !ok {
+			continue
+		}
+		fields = append(fields, viewField{
+			StructField: reflect.StructField{Name: f.Name, Type: sf.Type, Tag: reflect.StructTag(`json:"` + f.Legacy + `"`)},
+			value:       copyField(sf.Index[0]),
+		})
+	}
+	out := makeView(rows, fields)
+
+	groupBy, grouped := legacyGroupBy[item]
+	if _, ok := out.Type().Elem().FieldByName(groupBy); !grouped || !ok {
+		return out.Interface()
+	}
+	groups := reflect.MakeMap(reflect.MapOf(reflect.TypeOf(""), out.Type()))
+	for i := 0; i < out.Len(); i++ {
+		r := out.Index(i)
+		key := r.FieldByName(groupBy)
+		group := groups.MapIndex(key)
+		if !group.IsValid() {
+			group = reflect.MakeSlice(out.Type(), 0, 1)
+		}
+		groups.SetMapIndex(key, reflect.Append(group, r))
+	}
+	return groups.Interface()
+}
@@ -0,0 +1,26 @@
+package browserdata
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegacyRecords_RenamesFields(t *testing.T) {
+	logins := []struct {
+		UserName string
+		LoginURL string
+		Realm    string
+	}{{UserName: "user", LoginURL: "https://example.com/login", Realm: "https://example.com/"}}
+
+	b, err := json.Marshal(legacyRecords("password", reflect.ValueOf(logins)))
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"UserName": "user", "LoginUrl": "https://example.com/login"}]`, string(b))
+
+	b, err = json.Marshal(legacyRecords("mostVisited", reflect.ValueOf(logins)))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"LoginURL"`, "the items added since 0.3 keep their layout")
+}
@@ -4,10 +4,10 @@ import (
 	"bytes"
 	"database/sql"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
 	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
 
@@ -19,10 +19,16 @@ import (
 )
 
 func init() {
-	extractor.RegisterExtractor(types.ChromiumLocalStorage, func() extractor.Extractor {
+	extractor.RegisterExtractor(types.ChromiumLocalStorage, extractor.Info{
+		Description: "the local storage of the sites",
+		Sources:     []string{"Local Storage/leveldb"},
+	}, func() extractor.Extractor {
 		return new(ChromiumLocalStorage)
 	})
-	extractor.RegisterExtractor(types.FirefoxLocalStorage, func() extractor.Extractor {
+	extractor.RegisterExtractor(types.FirefoxLocalStorage, extractor.Info{
+		Description: "the local storage of the sites",
+		Sources:     []string{"webappsstore.sqlite"},
+	}, func() extractor.Extractor {
 		return new(FirefoxLocalStorage)
 	})
 }
@@ -38,16 +44,19 @@ type storage struct {
 
 const maxLocalStorageValueLength = 1024 * 2
 
-func (c *ChromiumLocalStorage) Extract(_ []byte) error {
-	db, err := leveldb.OpenFile(types.ChromiumLocalStorage.TempFilename(), nil)
+func (c *ChromiumLocalStorage) Extract(_ *extractor.Session) error {
+	db, err := leveldb.OpenFile(types.ChromiumLocalStorage.TempFilename(), &opt.Options{ReadOnly: types.InPlace()})
 	if err != nil {
 		return err
 	}
-	defer os.RemoveAll(types.ChromiumLocalStorage.TempFilename())
+	defer types.ChromiumLocalStorage.RemoveTemp()
 	defer db.Close()
 
 	iter := db.NewIterator(nil, nil)
 	for iter.Next() {
+		if extractor.ReachedMaxRows(len(*c)) {
+			break
+		}
 		key := iter.Key()
 		value := iter.Value()
 		s := new(storage)
@@ -115,19 +124,19 @@ const (
 	closeJournalMode  = `PRAGMA journal_mode=off`
 )
 
-func (f *FirefoxLocalStorage) Extract(_ []byte) error {
-	db, err := sql.Open("sqlite", types.FirefoxLocalStorage.TempFilename())
+func (f *FirefoxLocalStorage) Extract(_ *extractor.Session) error {
+	db, err := sql.Open("sqlite", types.FirefoxLocalStorage.DSN())
 	if err != nil {
 		return err
 	}
-	defer os.Remove(types.FirefoxLocalStorage.TempFilename())
+	defer types.FirefoxLocalStorage.RemoveTemp()
 	defer db.Close()
 
 	_, err = db.Exec(closeJournalMode)
 	if err != nil {
 		log.Errorf("close journal mode error: %v", err)
 	}
-	rows, err := db.Query(queryLocalStorage)
+	rows, err := db.Query(extractor.LimitQuery(queryLocalStorage))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,129 @@
+package browserdata
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ManifestFilename is the name of the manifest written to the results dir
+const ManifestFilename = "manifest.json"
+
+// Manifest describes the output files of a run, it's written after all files
+// are exported, so the results can be checked for tampering later.
+type Manifest struct {
+	Tool      string         `json:"tool"`
+	Version   string         `json:"version"`
+	CreatedAt time.Time      `json:"created_at"`
+	Files     []ManifestFile `json:"files"`
+}
+
+// ManifestFile is a single output file in the manifest
+type ManifestFile struct {
+	Name    string `json:"name"`
+	SHA256  string `json:"sha256"`
+	Size    int64  `json:"size"`
+	Records int    `json:"records"`
+}
+
+// outputs collects the files written by Output when the manifest is enabled
+var outputs *outputRecorder
+
+type outputRecorder struct {
+	mu    sync.Mutex
+	files []recordedFile
+}
+
+type recordedFile struct {
+	path    string
+	records int
+}
+
+// SetManifest enables or disables the collection of output files for WriteManifest
+func SetManifest(enabled bool) {
+	if !enabled {
+		outputs = nil
+		return
+	}
+	outputs = &outputRecorder{}
+}
+
+// recordOutput remembers an output file and the number of records written to it,
+// a file written again, eg: in watch mode, replaces the previous record.
+func recordOutput(path string, records int) {
+	if outputs == nil {
+		return
+	}
+	outputs.mu.Lock()
+	defer outputs.mu.Unlock()
+	for i, f := range outputs.files {
+		if f.path == path {
+			outputs.files[i].records = records
+			return
+		}
+	}
+	outputs.files = append(outputs.files, recordedFile{path: path, records: records})
+}
+
+// WriteManifest hashes every file exported since the manifest was enabled
+// and writes the result to ManifestFilename in dir.
+func WriteManifest(dir, tool, version string) error {
+	if outputs == nil {
+		return nil
+	}
+	outputs.mu.Lock()
+	files := append([]recordedFile(nil), outputs.files...)
+	outputs.mu.Unlock()
+
+	m := Manifest{
+		Tool:      tool,
+		Version:   version,
+		CreatedAt: time.Now().UTC(),
+		Files:     make([]ManifestFile, 0, len(files)),
+	}
+	for _, f := range files {
+		sum, size, err := hashFile(f.path)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, f.path)
+		if err != nil {
+			name = filepath.Base(f.path)
+		}
+		m.Files = append(m.Files, ManifestFile{
+			Name:    filepath.ToSlash(name),
+			SHA256:  sum,
+			Size:    size,
+			Records: f.records,
+		})
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ManifestFilename), append(data, '\n'), 0o600)
+}
+
+func hashFile(path string) (string, int64, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
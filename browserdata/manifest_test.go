@@ -0,0 +1,49 @@
+package browserdata
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteManifest(t *testing.T) {
+	SetManifest(true)
+	defer SetManifest(false)
+
+	dir := t.TempDir()
+	content := []byte("host,name\nexample.com,sid\n")
+	path := filepath.Join(dir, "chrome_default_cookie.csv")
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+	recordOutput(path, 1)
+
+	require.NoError(t, WriteManifest(dir, "hack-browser-data", "0.5.0"))
+
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFilename))
+	require.NoError(t, err)
+	var m Manifest
+	require.NoError(t, json.Unmarshal(data, &m))
+
+	sum := sha256.Sum256(content)
+	assert.Equal(t, "hack-browser-data", m.Tool)
+	assert.Equal(t, "0.5.0", m.Version)
+	assert.False(t, m.CreatedAt.IsZero())
+	assert.Equal(t, []ManifestFile{{
+		Name:    "chrome_default_cookie.csv",
+		SHA256:  hex.EncodeToString(sum[:]),
+		Size:    int64(len(content)),
+		Records: 1,
+	}}, m.Files)
+}
+
+func TestWriteManifestDisabled(t *testing.T) {
+	SetManifest(false)
+	dir := t.TempDir()
+	require.NoError(t, WriteManifest(dir, "hack-browser-data", "0.5.0"))
+	assert.NoFileExists(t, filepath.Join(dir, ManifestFilename))
+}
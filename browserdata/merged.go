@@ -0,0 +1,71 @@
+package browserdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/moond4rk/hackbrowserdata/log"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+// mergedItem is the item name of the file with the records of every item of the browser
+const mergedItem = "all"
+
+// mergeJSON writes the json of a browser as one object keyed by the item names instead of a file per item
+var mergeJSON bool
+
+// SetMergeJSON writes the json output of every browser as one object, eg: {"cookie":[...],"password":[...]},
+// it's convenient for the tools which ingest a single file per profile.
+func SetMergeJSON(b bool) {
+	mergeJSON = b
+}
+
+// outputMerged writes the records of every item to a single json file, the items are
+// skipped as they are by the per item output.
+func (d *BrowserData) outputMerged(dir string, filenameOf func(item, ext string) string) error {
+	merged := make(map[string]any)
+	count := 0
+	for _, dt := range types.SortedKeys(d.extractors) {
+		source := d.extractors[dt]
+		if source.Len() == 0 && (!writeEmpty || d.errors[dt] != nil) {
+			log.Debugf("skip %s, no records", source.Name())
+			continue
+		}
+		rows, err := jsonRecords(source)
+		if err != nil {
+			return fmt.Errorf("merge %s: %w", source.Name(), err)
+		}
+		merged[source.Name()] = rows
+		count += source.Len()
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	write := func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(merged)
+	}
+	if dir == consoleDir {
+		return console.WriteItem(write)
+	}
+
+	output := newOutPutter("json")
+	filename := compressedFilename(filenameOf(mergedItem, output.Ext()))
+	f, err := output.CreateFile(dir, filename)
+	if err != nil {
+		return fmt.Errorf("create file %s: %w", filename, err)
+	}
+	if err := writeCompressed(f, write); err != nil {
+		discardOutput(f, 0)
+		return fmt.Errorf("write to file %s: %w", filename, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close file %s: %w", filename, err)
+	}
+	recordOutput(f.Name(), count)
+	log.Warnf("export success: %s", filename)
+	return nil
+}
@@ -0,0 +1,38 @@
+package browserdata
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/browserdata/bookmark"
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func TestBrowserData_OutputMergedJSON(t *testing.T) {
+	SetMergeJSON(true)
+	defer SetMergeJSON(false)
+	dir := t.TempDir()
+	bd := &BrowserData{
+		extractors: map[types.DataType]extractor.Extractor{
+			types.ChromiumCookie:   newTestCookies(t, "abc", "def"),
+			types.ChromiumBookmark: new(bookmark.ChromiumBookmark),
+		},
+	}
+	require.NoError(t, bd.Output(dir, "chrome_default", "csv,json"))
+
+	assert.FileExists(t, filepath.Join(dir, "chrome_default_cookie.csv"))
+	assert.NoFileExists(t, filepath.Join(dir, "chrome_default_cookie.json"))
+	raw, err := os.ReadFile(filepath.Join(dir, "chrome_default_all.json"))
+	require.NoError(t, err)
+	var merged map[string][]map[string]any
+	require.NoError(t, json.Unmarshal(raw, &merged))
+	assert.Len(t, merged, 1, "the items without records are skipped")
+	require.Len(t, merged["cookie"], 2)
+	assert.Equal(t, "abc", merged["cookie"][0]["Value"])
+}
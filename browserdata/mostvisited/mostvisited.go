@@ -0,0 +1,156 @@
+package mostvisited
+
+import (
+	"database/sql"
+	"math"
+	"sort"
+	"time"
+
+	// import sqlite3 driver
+	_ "modernc.org/sqlite"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/log"
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
+)
+
+func init() {
+	extractor.RegisterExtractor(types.ChromiumMostVisited, extractor.Info{
+		Description: "the most visited sites",
+		Sources:     []string{"History"},
+	}, func() extractor.Extractor {
+		return new(ChromiumMostVisited)
+	})
+}
+
+// ChromiumMostVisited is the ranked sites of the most visited tiles on the new tab page,
+// it's scored from the segment usage of the last 90 days as Chromium does, the urls are
+// scored by visit count and last visit time if there are no segments.
+type ChromiumMostVisited []mostVisited
+
+type mostVisited struct {
+	URL           string
+	Title         string
+	Score         float64
+	VisitCount    int
+	LastVisitTime time.Time
+}
+
+// @https://source.chromium.org/chromium/chromium/src/+/main:components/history/core/browser/visitsegment_database.cc
+const (
+	queryChromiumSegments = `SELECT s.id, u.url, u.title, su.time_slot, su.visit_count FROM segments s
+		JOIN segment_usage su ON su.segment_id = s.id JOIN urls u ON u.id = s.url_id WHERE su.time_slot >= ?`
+	queryChromiumURLs = `SELECT url, title, visit_count, last_visit_time FROM urls WHERE hidden = 0 AND visit_count > 0`
+	// segmentDays is how far the new tab page looks back at the segment usage
+	segmentDays = 90
+)
+
+var now = time.Now
+
+func (c *ChromiumMostVisited) Extract(_ *extractor.Session) error {
+	db, err := sql.Open("sqlite", types.ChromiumMostVisited.DSN())
+	if err != nil {
+		return err
+	}
+	defer types.ChromiumMostVisited.RemoveTemp()
+	defer db.Close()
+
+	if err := c.fromSegments(db); err != nil {
+		log.Debugf("query chromium segments error: %v", err)
+	}
+	if len(*c) == 0 {
+		if err := c.fromURLs(db); err != nil {
+			return err
+		}
+	}
+	sort.SliceStable(*c, func(i, j int) bool {
+		return (*c)[i].Score > (*c)[j].Score
+	})
+	return nil
+}
+
+// fromSegments sums the score of every day a segment was visited
+func (c *ChromiumMostVisited) fromSegments(db *sql.DB) error {
+	cutoff := now().AddDate(0, 0, -segmentDays)
+	rows, err := db.Query(extractor.LimitQuery(queryChromiumSegments), typeutil.EpochFromTime(cutoff))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	index := make(map[int64]int)
+	for rows.Next() {
+		var (
+			id, timeSlot int64
+			url, title   string
+			visitCount   int
+		)
+		if err := rows.Scan(&id, &url, &title, &timeSlot, &visitCount); err != nil {
+			log.Warnf("scan chromium segment error: %v", err)
+			continue
+		}
+		day := typeutil.TimeEpoch(timeSlot)
+		i, ok := index[id]
+		if !ok {
+			i = len(*c)
+			index[id] = i
+			*c = append(*c, mostVisited{URL: url, Title: title})
+		}
+		m := &(*c)[i]
+		m.Score += score(visitCount, day)
+		m.VisitCount += visitCount
+		if day.After(m.LastVisitTime) {
+			m.LastVisitTime = day
+		}
+	}
+	return rows.Err()
+}
+
+// fromURLs scores the urls by their visit count and last visit time, the frecency of the history
+func (c *ChromiumMostVisited) fromURLs(db *sql.DB) error {
+	rows, err := db.Query(extractor.LimitQuery(queryChromiumURLs))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			url, title    string
+			visitCount    int
+			lastVisitTime int64
+		)
+		if err := rows.Scan(&url, &title, &visitCount, &lastVisitTime); err != nil {
+			log.Warnf("scan chromium history error: %v", err)
+			continue
+		}
+		last := typeutil.TimeEpoch(lastVisitTime)
+		*c = append(*c, mostVisited{
+			URL:           url,
+			Title:         title,
+			Score:         score(visitCount, last),
+			VisitCount:    visitCount,
+			LastVisitTime: last,
+		})
+	}
+	return rows.Err()
+}
+
+// score is the score of the visits of a day, today counts 3x, a week ago 2x and it
+// falls off to 1x for the old visits, same as the new tab page.
+func score(visitCount int, day time.Time) float64 {
+	if visitCount <= 0 {
+		return 0
+	}
+	daysAgo := math.Max(0, math.Floor(now().Sub(day).Hours()/24))
+	recencyBoost := 1 + 2*(1/(1+daysAgo/7))
+	return recencyBoost * (1 + math.Log(float64(visitCount)))
+}
+
+func (c *ChromiumMostVisited) Name() string {
+	return "mostVisited"
+}
+
+func (c *ChromiumMostVisited) Len() int {
+	return len(*c)
+}
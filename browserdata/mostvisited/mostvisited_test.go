@@ -0,0 +1,83 @@
+package mostvisited
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/testutil"
+	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
+)
+
+const createHistoryTables = `CREATE TABLE urls (id INTEGER PRIMARY KEY, url LONGVARCHAR, title LONGVARCHAR, visit_count INTEGER DEFAULT 0 NOT NULL, typed_count INTEGER DEFAULT 0 NOT NULL, last_visit_time INTEGER NOT NULL, hidden INTEGER DEFAULT 0 NOT NULL);
+	CREATE TABLE segments (id INTEGER PRIMARY KEY, name VARCHAR, url_id INTEGER NON NULL);
+	CREATE TABLE segment_usage (id INTEGER PRIMARY KEY, segment_id INTEGER NOT NULL, time_slot INTEGER NOT NULL, visit_count INTEGER DEFAULT 0 NOT NULL)`
+
+func createHistoryDB(t *testing.T, stmts ...string) {
+	t.Helper()
+	testutil.CreateSQLite(t, types.ChromiumMostVisited, append([]string{createHistoryTables}, stmts...)...)
+}
+
+func setNow(t *testing.T, n time.Time) {
+	t.Helper()
+	old := now
+	now = func() time.Time { return n }
+	t.Cleanup(func() { now = old })
+}
+
+func TestChromiumMostVisited_ExtractSegments(t *testing.T) {
+	today := time.Date(2024, 6, 30, 12, 0, 0, 0, time.Local)
+	setNow(t, today)
+	day := func(daysAgo int) int64 {
+		return typeutil.EpochFromTime(today.AddDate(0, 0, -daysAgo))
+	}
+	createHistoryDB(t,
+		`INSERT INTO urls (id, url, title, visit_count, last_visit_time) VALUES
+			(1, 'https://old.test/', 'Old', 50, 0),
+			(2, 'https://recent.test/', 'Recent', 3, 0),
+			(3, 'https://expired.test/', 'Expired', 100, 0)`,
+		`INSERT INTO segments (id, name, url_id) VALUES (1, 'http://old.test/', 1), (2, 'http://recent.test/', 2), (3, 'http://expired.test/', 3)`,
+		fmt.Sprintf(`INSERT INTO segment_usage (segment_id, time_slot, visit_count) VALUES (1, %d, 4), (2, %d, 3), (2, %d, 1), (3, %d, 100)`,
+			day(60), day(0), day(1), day(120)),
+	)
+
+	var c ChromiumMostVisited
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 2, "the segments visited more than 90 days ago are skipped")
+	assert.Equal(t, "https://recent.test/", c[0].URL)
+	assert.Equal(t, "Recent", c[0].Title)
+	assert.Equal(t, 4, c[0].VisitCount)
+	assert.InDelta(t, score(3, today)+score(1, today.AddDate(0, 0, -1)), c[0].Score, 1e-9)
+	assert.Equal(t, today.UnixMicro(), c[0].LastVisitTime.UnixMicro())
+	assert.Equal(t, "https://old.test/", c[1].URL)
+}
+
+func TestChromiumMostVisited_ExtractFrecency(t *testing.T) {
+	today := time.Date(2024, 6, 30, 12, 0, 0, 0, time.Local)
+	setNow(t, today)
+	createHistoryDB(t, fmt.Sprintf(`INSERT INTO urls (url, title, visit_count, last_visit_time, hidden) VALUES
+		('https://often.test/', 'Often', 20, %d, 0),
+		('https://today.test/', 'Today', 5, %d, 0),
+		('https://hidden.test/', 'Hidden', 99, 0, 1)`,
+		typeutil.EpochFromTime(today.AddDate(0, 0, -30)), typeutil.EpochFromTime(today)))
+
+	var c ChromiumMostVisited
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 2, "the urls are used when there are no segments")
+	assert.Equal(t, "https://today.test/", c[0].URL)
+	assert.Equal(t, "https://often.test/", c[1].URL)
+	assert.Greater(t, c[0].Score, c[1].Score)
+}
+
+func TestScore(t *testing.T) {
+	today := time.Date(2024, 6, 30, 12, 0, 0, 0, time.Local)
+	setNow(t, today)
+	assert.InDelta(t, 3.0, score(1, today), 1e-9)
+	assert.InDelta(t, 2.0, score(1, today.AddDate(0, 0, -7)), 1e-9)
+	assert.InDelta(t, 1.5, score(1, today.AddDate(0, 0, -21)), 1e-9)
+	assert.Zero(t, score(0, today))
+}
@@ -0,0 +1,209 @@
+package networkstate
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/log"
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/fileutil"
+	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
+)
+
+func init() {
+	extractor.RegisterExtractor(types.ChromiumNetworkState, extractor.Info{
+		Description: "the network servers and the HSTS hosts",
+		Sources:     []string{"Network/Network Persistent State", "Network Persistent State", "Network/TransportSecurity", "TransportSecurity"},
+	}, func() extractor.Extractor {
+		return new(ChromiumNetworkState)
+	})
+}
+
+// ChromiumNetworkState is the hosts Chromium keeps network hints for, the servers and QUIC
+// hints of Network Persistent State and the HSTS of TransportSecurity. They outlive the
+// history, so the https hosts are found even if the history was cleared. TransportSecurity
+// keeps the hash of the host only, the HSTS of a host not in Network Persistent State has
+// the hash and no host.
+type ChromiumNetworkState []networkHost
+
+type networkHost struct {
+	Host              string
+	HostHash          string
+	HSTSMode          string
+	IncludeSubdomains bool
+	HSTSObserved      time.Time
+	HSTSExpiry        time.Time
+	QUIC              bool
+	QUICExpiry        time.Time
+}
+
+// @https://source.chromium.org/chromium/chromium/src/+/main:net/http/http_server_properties_manager.cc
+// @https://source.chromium.org/chromium/chromium/src/+/main:net/http/transport_security_persister.cc
+const (
+	serversPath     = "net.http_server_properties.servers"
+	quicServersPath = "net.http_server_properties.quic_servers"
+	stsPath         = "sts"
+)
+
+func (c *ChromiumNetworkState) Extract(_ *extractor.Session) error {
+	s, err := fileutil.ReadFile(types.ChromiumNetworkState.TempFilename())
+	if err != nil {
+		return err
+	}
+	defer types.ChromiumNetworkState.RemoveTemp()
+
+	hosts := make(map[string]*networkHost)
+	host := func(h string) *networkHost {
+		if hosts[h] == nil {
+			hosts[h] = &networkHost{Host: h, HostHash: hashHost(h)}
+		}
+		return hosts[h]
+	}
+	parseServers(gjson.Get(s, serversPath), host)
+	gjson.Get(s, quicServersPath).ForEach(func(key, value gjson.Result) bool {
+		// the version 5 is a list of {server_id}, the older ones are keyed by the server
+		server := value.Get("server_id").String()
+		if server == "" {
+			server = key.String()
+		}
+		if h := hostOf(server); h != "" {
+			host(h).QUIC = true
+		}
+		return true
+	})
+
+	byHash := make(map[string]*networkHost, len(hosts))
+	for _, h := range hosts {
+		byHash[h.HostHash] = h
+	}
+	var unknown []*networkHost
+	if ts, err := fileutil.ReadFile(types.ChromiumTransportSecurity.TempFilename()); err != nil {
+		log.Debugf("read chromium transport security error: %v", err)
+	} else {
+		parseTransportSecurity(ts, func(hash string) *networkHost {
+			if h, ok := byHash[hash]; ok {
+				return h
+			}
+			h := &networkHost{HostHash: hash}
+			byHash[hash] = h
+			unknown = append(unknown, h)
+			return h
+		})
+	}
+	defer types.ChromiumTransportSecurity.RemoveTemp()
+
+	for _, h := range hosts {
+		*c = append(*c, *h)
+	}
+	sort.Slice(*c, func(i, j int) bool {
+		return (*c)[i].Host < (*c)[j].Host
+	})
+	sort.Slice(unknown, func(i, j int) bool {
+		return unknown[i].HostHash < unknown[j].HostHash
+	})
+	for _, h := range unknown {
+		*c = append(*c, *h)
+	}
+	if n := extractor.MaxRows(); n > 0 && len(*c) > n {
+		*c = (*c)[:n]
+	}
+	return nil
+}
+
+// parseServers reads the http servers, the version 5 is a list of {server, alternative_service},
+// the older ones are keyed by the server.
+func parseServers(servers gjson.Result, host func(string) *networkHost) {
+	servers.ForEach(func(key, value gjson.Result) bool {
+		server := value.Get("server").String()
+		if server == "" {
+			server = key.String()
+		}
+		h := hostOf(server)
+		if h == "" {
+			return true
+		}
+		n := host(h)
+		for _, alt := range value.Get("alternative_service").Array() {
+			if alt.Get("protocol_str").String() != "quic" {
+				continue
+			}
+			n.QUIC = true
+			if expiry := typeutil.TimeEpoch(alt.Get("expiration").Int()); expiry.After(n.QUICExpiry) {
+				n.QUICExpiry = expiry
+			}
+		}
+		return true
+	})
+}
+
+// parseTransportSecurity reads the HSTS of the hosts, the version 2 is a list of {host, mode},
+// the older ones are keyed by the hash of the host. The times are seconds since the unix epoch.
+func parseTransportSecurity(ts string, host func(hash string) *networkHost) {
+	entries := gjson.Get(ts, stsPath)
+	if !entries.Exists() {
+		entries = gjson.Parse(ts)
+	}
+	entries.ForEach(func(key, value gjson.Result) bool {
+		hash := value.Get("host").String()
+		if hash == "" {
+			hash = key.String()
+		}
+		mode := value.Get("mode").String()
+		if hash == "" || mode == "" {
+			return true
+		}
+		h := host(hash)
+		h.HSTSMode = mode
+		h.IncludeSubdomains = value.Get("sts_include_subdomains").Bool()
+		h.HSTSObserved = unixSeconds(value.Get("sts_observed").Float())
+		h.HSTSExpiry = unixSeconds(value.Get("expiry").Float())
+		return true
+	})
+}
+
+func unixSeconds(s float64) time.Time {
+	if s <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(s), 0)
+}
+
+// hostOf returns the host of the server, eg: https://example.com:443 or example.com:443
+func hostOf(server string) string {
+	if u, err := url.Parse(server); err == nil && u.Host != "" {
+		return strings.ToLower(u.Hostname())
+	}
+	if h, _, err := net.SplitHostPort(server); err == nil {
+		return strings.ToLower(h)
+	}
+	return ""
+}
+
+// hashHost is the key of the host in TransportSecurity, the base64 of the sha256 of the host
+// in the dns wire format, eg: \x07example\x03com\x00.
+func hashHost(host string) string {
+	var b []byte
+	for _, label := range strings.Split(strings.TrimSuffix(host, "."), ".") {
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	b = append(b, 0)
+	sum := sha256.Sum256(b)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func (c *ChromiumNetworkState) Name() string {
+	return "networkState"
+}
+
+func (c *ChromiumNetworkState) Len() int {
+	return len(*c)
+}
@@ -0,0 +1,72 @@
+package networkstate
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+const testNetworkState = `{"net": {"http_server_properties": {
+	"servers": [
+		{"server": "https://example.com", "supports_spdy": true, "alternative_service": [
+			{"advertised_alpns": ["h3"], "expiration": "13350000000000000", "port": 443, "protocol_str": "quic"}]},
+		{"server": "https://www.site.test:8443", "supports_spdy": true}
+	],
+	"quic_servers": [{"server_id": "https://quic.test:443"}],
+	"version": 5}}}`
+
+// example.com is matched by its hash, the other entry is a host not in Network Persistent State
+const testTransportSecurity = `{"sts": [
+	{"host": "kC6cRk+kP8qxCdGmuV3fgzOKjLw6UrT7/hqdhfIkbQ8=", "mode": "force-https", "sts_include_subdomains": true, "sts_observed": 1690000000.5, "expiry": 1721536000.5},
+	{"host": "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=", "mode": "force-https", "expiry": 1721536000}
+], "version": 2}`
+
+func TestHashHost(t *testing.T) {
+	assert.Equal(t, "kC6cRk+kP8qxCdGmuV3fgzOKjLw6UrT7/hqdhfIkbQ8=", hashHost("example.com"))
+}
+
+func TestChromiumNetworkState_Extract(t *testing.T) {
+	require.NoError(t, os.WriteFile(types.ChromiumNetworkState.TempFilename(), []byte(testNetworkState), 0o600))
+	require.NoError(t, os.WriteFile(types.ChromiumTransportSecurity.TempFilename(), []byte(testTransportSecurity), 0o600))
+
+	var c ChromiumNetworkState
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 4)
+
+	assert.Equal(t, "example.com", c[0].Host)
+	assert.Equal(t, "force-https", c[0].HSTSMode)
+	assert.True(t, c[0].IncludeSubdomains)
+	assert.Equal(t, time.Unix(1721536000, 0), c[0].HSTSExpiry)
+	assert.True(t, c[0].QUIC)
+	assert.False(t, c[0].QUICExpiry.IsZero())
+
+	assert.Equal(t, "quic.test", c[1].Host)
+	assert.True(t, c[1].QUIC)
+	assert.Equal(t, "www.site.test", c[2].Host)
+	assert.Empty(t, c[2].HSTSMode)
+
+	assert.Empty(t, c[3].Host)
+	assert.Equal(t, "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=", c[3].HostHash)
+	assert.Equal(t, "force-https", c[3].HSTSMode)
+
+	assert.NoFileExists(t, types.ChromiumNetworkState.TempFilename())
+	assert.NoFileExists(t, types.ChromiumTransportSecurity.TempFilename())
+}
+
+func TestChromiumNetworkState_ExtractLegacy(t *testing.T) {
+	legacy := `{"net": {"http_server_properties": {"servers": {"https://example.com:443": {"supports_spdy": true}}, "version": 4}}}`
+	require.NoError(t, os.WriteFile(types.ChromiumNetworkState.TempFilename(), []byte(legacy), 0o600))
+	ts := `{"kC6cRk+kP8qxCdGmuV3fgzOKjLw6UrT7/hqdhfIkbQ8=": {"mode": "force-https", "expiry": 1721536000}}`
+	require.NoError(t, os.WriteFile(types.ChromiumTransportSecurity.TempFilename(), []byte(ts), 0o600))
+
+	var c ChromiumNetworkState
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 1)
+	assert.Equal(t, "example.com", c[0].Host)
+	assert.Equal(t, "force-https", c[0].HSTSMode)
+}
@@ -0,0 +1,143 @@
+package networkstate
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func init() {
+	extractor.RegisterExtractor(types.FirefoxNetworkState, extractor.Info{
+		Description: "the HSTS hosts",
+		Sources:     []string{"SiteSecurityServiceState.bin", "SiteSecurityServiceState.txt"},
+	}, func() extractor.Extractor {
+		return new(FirefoxNetworkState)
+	})
+}
+
+// FirefoxNetworkState is the HSTS of the hosts in SiteSecurityServiceState, the hosts are
+// kept in plain text unlike Chromium, so they are the https hosts the user visited.
+// Firefox 114 moved the text file to SiteSecurityServiceState.bin, both are read.
+type FirefoxNetworkState []networkHost
+
+// @https://searchfox.org/mozilla-central/source/security/manager/ssl/data_storage/src/lib.rs
+const (
+	// a slot of the bin is checksum, score, last accessed day, the key and the value
+	sssKeyOffset   = 6
+	sssKeyLength   = 256
+	sssValueLength = 24
+	sssSlotLength  = sssKeyOffset + sssKeyLength + sssValueLength
+	// hstsSuffix is the suffix of the HSTS keys, the HPKP ones of the old versions are skipped
+	hstsSuffix = ":HSTS"
+)
+
+// the states of SiteHSTSState
+// @https://searchfox.org/mozilla-central/source/security/manager/ssl/nsSiteSecurityService.h
+var sssModes = map[string]string{
+	"1": "force-https",
+	"2": "knockout",
+}
+
+func (f *FirefoxNetworkState) Extract(_ *extractor.Session) error {
+	b, err := os.ReadFile(types.FirefoxNetworkState.TempFilename())
+	if err != nil {
+		return err
+	}
+	defer types.FirefoxNetworkState.RemoveTemp()
+
+	// the text file has no NUL, the slots of the bin are padded with it
+	entries := parseSiteSecurityText
+	if bytes.IndexByte(b, 0) >= 0 {
+		entries = parseSiteSecurityBin
+	}
+	hosts := make(map[string]*networkHost)
+	entries(b, func(key, value string) {
+		if h := siteSecurityHost(key, value); h != nil {
+			if old, ok := hosts[h.Host]; !ok || h.HSTSExpiry.After(old.HSTSExpiry) {
+				hosts[h.Host] = h
+			}
+		}
+	})
+	for _, h := range hosts {
+		*f = append(*f, *h)
+	}
+	sort.Slice(*f, func(i, j int) bool {
+		return (*f)[i].Host < (*f)[j].Host
+	})
+	if n := extractor.MaxRows(); n > 0 && len(*f) > n {
+		*f = (*f)[:n]
+	}
+	return nil
+}
+
+// parseSiteSecurityText reads the lines of SiteSecurityServiceState.txt, eg:
+// example.com:HSTS	0	19000	1721536000000,1,0
+func parseSiteSecurityText(b []byte, entry func(key, value string)) {
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 4 {
+			continue
+		}
+		entry(fields[0], fields[3])
+	}
+}
+
+// parseSiteSecurityBin reads the fixed size slots of SiteSecurityServiceState.bin, the key
+// and the value are padded with NUL, the empty slots have no key.
+func parseSiteSecurityBin(b []byte, entry func(key, value string)) {
+	for off := 0; off+sssSlotLength <= len(b); off += sssSlotLength {
+		slot := b[off : off+sssSlotLength]
+		key := string(bytes.TrimRight(slot[sssKeyOffset:sssKeyOffset+sssKeyLength], "\x00"))
+		value := string(bytes.TrimRight(slot[sssKeyOffset+sssKeyLength:], "\x00"))
+		if key != "" {
+			entry(key, value)
+		}
+	}
+}
+
+// siteSecurityHost returns the HSTS of the entry, the key is the host with the origin
+// attributes and the value is the expiry in milliseconds, the state and the include
+// subdomains flag, eg: example.com^partitionKey=%28https%2Csite.test%29:HSTS 1721536000000,1,1
+func siteSecurityHost(key, value string) *networkHost {
+	if i := strings.LastIndexByte(key, ':'); i >= 0 {
+		if key[i:] != hstsSuffix {
+			return nil
+		}
+		key = key[:i]
+	}
+	host, _, _ := strings.Cut(key, "^")
+	fields := strings.Split(value, ",")
+	if host == "" || len(fields) < 3 {
+		return nil
+	}
+	mode, ok := sssModes[fields[1]]
+	if !ok {
+		return nil
+	}
+	expiry, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &networkHost{
+		Host:              strings.ToLower(host),
+		HSTSMode:          mode,
+		IncludeSubdomains: fields[2] == "1",
+		HSTSExpiry:        time.UnixMilli(expiry),
+	}
+}
+
+func (f *FirefoxNetworkState) Name() string {
+	return "networkState"
+}
+
+func (f *FirefoxNetworkState) Len() int {
+	return len(*f)
+}
@@ -0,0 +1,55 @@
+package networkstate
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func TestFirefoxNetworkState_ExtractText(t *testing.T) {
+	state := "example.com:HSTS\t0\t19000\t1721536000000,1,1\n" +
+		"example.com^partitionKey=%28https%2Csite.test%29:HSTS\t0\t19000\t1700000000000,1,0\n" +
+		"pinned.test:HPKP\t0\t19000\t1721536000000,1,0,abc=\n" +
+		"removed.test:HSTS\t0\t19000\t0,2,0\n" +
+		"broken line\n"
+	require.NoError(t, os.WriteFile(types.FirefoxNetworkState.TempFilename(), []byte(state), 0o600))
+
+	var f FirefoxNetworkState
+	require.NoError(t, f.Extract(nil))
+	require.Len(t, f, 2)
+	assert.Equal(t, "example.com", f[0].Host)
+	assert.Equal(t, "force-https", f[0].HSTSMode)
+	assert.True(t, f[0].IncludeSubdomains)
+	assert.Equal(t, time.UnixMilli(1721536000000), f[0].HSTSExpiry)
+	assert.Equal(t, "removed.test", f[1].Host)
+	assert.Equal(t, "knockout", f[1].HSTSMode)
+	assert.NoFileExists(t, types.FirefoxNetworkState.TempFilename())
+}
+
+func TestFirefoxNetworkState_ExtractBin(t *testing.T) {
+	slot := func(key, value string) []byte {
+		b := make([]byte, sssSlotLength)
+		b[3] = 1
+		copy(b[sssKeyOffset:], key)
+		copy(b[sssKeyOffset+sssKeyLength:], value)
+		return b
+	}
+	var bin []byte
+	bin = append(bin, slot("example.com", "1721536000000,1,0")...)
+	bin = append(bin, make([]byte, sssSlotLength)...)
+	bin = append(bin, slot("www.site.test:HSTS", "1721536000000,1,1")...)
+	require.NoError(t, os.WriteFile(types.FirefoxNetworkState.TempFilename(), bin, 0o600))
+
+	var f FirefoxNetworkState
+	require.NoError(t, f.Extract(nil))
+	require.Len(t, f, 2)
+	assert.Equal(t, "example.com", f[0].Host)
+	assert.False(t, f[0].IncludeSubdomains)
+	assert.Equal(t, "www.site.test", f[1].Host)
+	assert.True(t, f[1].IncludeSubdomains)
+}
@@ -0,0 +1,70 @@
+package browserdata
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// outputName is the template of the output filenames without the extension, empty is the
+// default naming, eg: {browser}-{profile}-{item}-{date}
+var outputName string
+
+// outputNameTokens are the tokens of the output name template
+var outputNameTokens = map[string]bool{
+	"browser": true,
+	"profile": true,
+	"item":    true,
+	"date":    true,
+	"format":  true,
+}
+
+var outputNameToken = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// now is the clock of the {date} token
+var now = time.Now
+
+// SetOutputName sets the template of the output filenames, the tokens are {browser}, {profile},
+// {item}, {date} and {format}, the extension of the format is appended to the name. The
+// template must contain {item}, otherwise every item of a profile is written to the same file.
+func SetOutputName(tmpl string) error {
+	for _, m := range outputNameToken.FindAllStringSubmatch(tmpl, -1) {
+		if !outputNameTokens[m[1]] {
+			return fmt.Errorf("unknown token {%s} in output name, available: {browser}, {profile}, {item}, {date}, {format}", m[1])
+		}
+	}
+	if rest := outputNameToken.ReplaceAllString(tmpl, ""); strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("unbalanced braces in output name %q", tmpl)
+	}
+	if strings.ContainsAny(tmpl, `/\`) {
+		return fmt.Errorf("output name %q must not contain a path separator", tmpl)
+	}
+	if tmpl != "" && !strings.Contains(tmpl, "{item}") {
+		return fmt.Errorf("output name %q must contain the {item} token", tmpl)
+	}
+	outputName = tmpl
+	return nil
+}
+
+// SetProfile sets the browser and the profile of the data for the output name template
+func (d *BrowserData) SetProfile(browser, profile string) {
+	d.browser, d.profile = browser, profile
+}
+
+// expandOutputName returns the filename of the item from the template, the extension is kept
+func (d *BrowserData) expandOutputName(item, ext string) string {
+	format, _, _ := strings.Cut(ext, ".")
+	values := map[string]string{
+		"browser": d.browser,
+		"profile": d.profile,
+		"item":    item,
+		"date":    now().Format("2006-01-02"),
+		"format":  format,
+	}
+	replace := strings.NewReplacer(" ", "_", "/", "_", `\`, "_")
+	name := outputNameToken.ReplaceAllStringFunc(outputName, func(token string) string {
+		return replace.Replace(values[token[1:len(token)-1]])
+	})
+	return name + "." + ext
+}
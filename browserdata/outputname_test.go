@@ -0,0 +1,48 @@
+package browserdata
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func TestBrowserData_OutputName(t *testing.T) {
+	defer func() { _ = SetOutputName("") }()
+	defer func(clock func() time.Time) { now = clock }(now)
+	now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+	require.NoError(t, SetOutputName("{browser}-{profile}-{item}-{date}-{format}"))
+
+	dir := t.TempDir()
+	bd := &BrowserData{
+		extractors: map[types.DataType]extractor.Extractor{
+			types.ChromiumCookie: newTestCookies(t, "abc"),
+		},
+	}
+	bd.SetProfile("Chrome", "Profile 1")
+	require.NoError(t, bd.Output(dir, "chrome_user_1", "csv,json"))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"Chrome-Profile_1-cookie-2024-05-01-csv.csv", "Chrome-Profile_1-cookie-2024-05-01-json.json"}, names)
+}
+
+func TestSetOutputName(t *testing.T) {
+	defer func() { _ = SetOutputName("") }()
+	assert.NoError(t, SetOutputName("{item}"))
+	assert.ErrorContains(t, SetOutputName("{item}-{host}"), "unknown token {host}")
+	assert.ErrorContains(t, SetOutputName("{item"), "unbalanced")
+	assert.ErrorContains(t, SetOutputName("out/{item}"), "path separator")
+	assert.ErrorContains(t, SetOutputName("{browser}-{profile}"), "must contain the {item} token")
+	assert.Equal(t, "{item}", outputName)
+	assert.NoError(t, SetOutputName(""))
+}
@@ -7,6 +7,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 
 	"github.com/gocarina/gocsv"
 	"golang.org/x/text/encoding/unicode"
@@ -15,36 +17,148 @@ import (
 	"github.com/moond4rk/hackbrowserdata/extractor"
 )
 
+// outPutter writes the items with the formatter of the format flag and creates the output files
 type outPutter struct {
-	json bool
-	csv  bool
+	Formatter
 }
 
-func newOutPutter(flag string) *outPutter {
-	o := &outPutter{}
-	if flag == "json" {
-		o.json = true
-	} else {
-		o.csv = true
+const (
+	// headerFormat writes every cookie as a Set-Cookie header line, only cookies support it
+	headerFormat = "header"
+	// editThisCookieFormat writes the cookies as the json of the EditThisCookie extension
+	editThisCookieFormat = "editthiscookie"
+)
+
+var errUnsupportedFormat = errors.New("format is not supported by the item")
+
+// setCookieHeaders is implemented by the items which can be written as Set-Cookie headers
+type setCookieHeaders interface {
+	SetCookieHeaders() []string
+}
+
+// editThisCookies is implemented by the items which can be imported by the EditThisCookie extension
+type editThisCookies interface {
+	EditThisCookie() any
+}
+
+// allFormats is the flag which writes the items in every format made for all the items
+const allFormats = "all"
+
+// ParseFormats returns the formats of the comma separated flag, eg: csv,json, all is csv and
+// json. The unknown formats are csv as the outPutter treats them, every format is kept once.
+func ParseFormats(flag string) []string {
+	var formats []string
+	seen := make(map[string]bool)
+	for _, f := range strings.Split(flag, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		expanded := []string{f}
+		if f == allFormats {
+			expanded = []string{"csv", "json"}
+		}
+		for _, format := range expanded {
+			if ext := newOutPutter(format).Ext(); !seen[ext] {
+				seen[ext] = true
+				formats = append(formats, format)
+			}
+		}
 	}
-	return o
+	return formats
+}
+
+func newOutPutter(flag string) *outPutter {
+	return &outPutter{Formatter: formatterOf(flag)}
 }
 
 func (o *outPutter) Write(data extractor.Extractor, writer io.Writer) error {
-	switch o.json {
-	case true:
-		encoder := json.NewEncoder(writer)
-		encoder.SetIndent("", "  ")
-		encoder.SetEscapeHTML(false)
-		return encoder.Encode(data)
-	default:
-		gocsv.SetCSVWriter(func(w io.Writer) *gocsv.SafeCSVWriter {
-			writer := csv.NewWriter(transform.NewWriter(w, unicode.UTF8BOM.NewEncoder()))
-			writer.Comma = ','
-			return gocsv.NewSafeCSVWriter(writer)
-		})
-		return gocsv.Marshal(data, writer)
+	return o.Format(data, writer)
+}
+
+// WriteCSV writes the records of the item to w as csv with a utf8 BOM, the output transforms are applied.
+func WriteCSV(w io.Writer, data extractor.Extractor) error {
+	rows, err := records(data, true)
+	if err != nil {
+		return err
+	}
+	rows = formatTimes(rows)
+	writer := csv.NewWriter(transform.NewWriter(w, unicode.UTF8BOM.NewEncoder()))
+	writer.Comma = ','
+	return gocsv.MarshalCSV(rows, &csvWriter{w: writer})
+}
+
+// csvFlushRows is the number of rows buffered before the csv is flushed
+const csvFlushRows = 1000
+
+// csvWriter flushes the rows every csvFlushRows and checks the error of every write,
+// so a failed write stops the item at once instead of being found after the last row.
+type csvWriter struct {
+	w    *csv.Writer
+	rows int
+}
+
+func (c *csvWriter) Write(row []string) error {
+	if err := c.w.Write(row); err != nil {
+		return err
+	}
+	c.rows++
+	if c.rows%csvFlushRows == 0 {
+		c.w.Flush()
+		return c.w.Error()
 	}
+	return nil
+}
+
+func (c *csvWriter) Flush() {
+	c.w.Flush()
+}
+
+func (c *csvWriter) Error() error {
+	return c.w.Error()
+}
+
+// WriteJSON writes the records of the item to w as indented json, the output transforms are applied.
+func WriteJSON(w io.Writer, data extractor.Extractor) error {
+	rows, err := jsonRecords(data)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(rows)
+}
+
+// WriteJSONL writes the records of the item to w as json lines, one compact record per line,
+// so the files can be appended to. The output transforms are applied.
+func WriteJSONL(w io.Writer, data extractor.Extractor) error {
+	rows, err := jsonRecords(data)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	v := reflect.ValueOf(rows)
+	if v.Kind() != reflect.Slice {
+		return encoder.Encode(rows)
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := encoder.Encode(v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonRecords returns the records of the item with the transforms of the json formats applied
+func jsonRecords(data extractor.Extractor) (any, error) {
+	rows, err := records(data, false)
+	if err != nil {
+		return nil, err
+	}
+	if legacyJSON && data != nil {
+		// the 0.3 layout keeps writing the zero times as 0001-01-01T00:00:00Z
+		return legacyRecords(data.Name(), reflect.ValueOf(rows)), nil
+	}
+	return formatTimes(rows), nil
 }
 
 func (o *outPutter) CreateFile(dir, filename string) (*os.File, error) {
@@ -64,16 +178,50 @@ func (o *outPutter) CreateFile(dir, filename string) (*os.File, error) {
 	var file *os.File
 	var err error
 	p := filepath.Join(dir, filename)
-	file, err = os.OpenFile(filepath.Clean(p), os.O_TRUNC|os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	flag := os.O_TRUNC | os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if appendOutput {
+		flag &^= os.O_TRUNC
+	}
+	file, err = os.OpenFile(filepath.Clean(p), flag, 0o600)
 	if err != nil {
 		return nil, err
 	}
 	return file, nil
 }
 
-func (o *outPutter) Ext() string {
-	if o.json {
-		return "json"
+// itemOf returns the records as the item type, the methods are defined on the pointer of the item
+func itemOf(rows any) any {
+	v := reflect.ValueOf(rows)
+	if !v.IsValid() {
+		return nil
+	}
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	return p.Interface()
+}
+
+// writeEditThisCookie writes the records as the json array imported by the EditThisCookie extension
+func writeEditThisCookie(rows any, writer io.Writer) error {
+	e, ok := itemOf(rows).(editThisCookies)
+	if !ok {
+		return errUnsupportedFormat
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(e.EditThisCookie())
+}
+
+// writeHeaders writes the Set-Cookie header lines of the records, one per line
+func writeHeaders(rows any, writer io.Writer) error {
+	h, ok := itemOf(rows).(setCookieHeaders)
+	if !ok {
+		return errUnsupportedFormat
+	}
+	for _, line := range h.SetCookieHeaders() {
+		if _, err := io.WriteString(writer, line+"\n"); err != nil {
+			return err
+		}
 	}
-	return "csv"
+	return nil
 }
@@ -1,8 +1,16 @@
 package browserdata
 
 import (
+	"bytes"
+	"errors"
 	"os"
+	"strconv"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/browserdata/bookmark"
 )
 
 func TestNewOutPutter(t *testing.T) {
@@ -21,3 +29,105 @@ func TestNewOutPutter(t *testing.T) {
 		t.Error("Write() returned an error", err)
 	}
 }
+
+func TestOutPutter_WriteHeader(t *testing.T) {
+	out := newOutPutter(headerFormat)
+	assert.Equal(t, "txt", out.Ext())
+
+	c := newTestCookies(t, "abc")
+	require.True(t, out.Supports(c))
+	var buf bytes.Buffer
+	require.NoError(t, out.Write(c, &buf))
+	assert.Equal(t, "Set-Cookie: =abc\n", buf.String())
+
+	var b bookmark.ChromiumBookmark
+	assert.False(t, out.Supports(&b))
+	assert.ErrorIs(t, out.Write(&b, &buf), errUnsupportedFormat)
+}
+
+func TestOutPutter_WriteHeaderFields(t *testing.T) {
+	out := newOutPutter(headerFormat)
+	c := newTestCookies(t, "abc")
+	t.Cleanup(func() { SetFields("") })
+
+	// the header keeps its fields, the selected ones are only checked
+	SetFields("host,value")
+	var buf bytes.Buffer
+	require.NoError(t, out.Write(c, &buf))
+	assert.Equal(t, "Set-Cookie: =abc\n", buf.String())
+
+	SetFields("host,bogus")
+	assert.ErrorContains(t, out.Write(c, &buf), "unknown field bogus")
+}
+
+func TestOutPutter_WriteEditThisCookie(t *testing.T) {
+	out := newOutPutter(editThisCookieFormat)
+	assert.Equal(t, "editthiscookie.json", out.Ext())
+
+	c := newTestCookies(t, "<abc>")
+	require.True(t, out.Supports(c))
+	var buf bytes.Buffer
+	require.NoError(t, out.Write(c, &buf))
+	assert.JSONEq(t, `[{"domain":"example.com","hostOnly":true,"httpOnly":false,"name":"","path":"",
+		"sameSite":"unspecified","secure":false,"session":true,"storeId":"0","value":"<abc>","id":1}]`, buf.String())
+	assert.Contains(t, buf.String(), "<abc>")
+
+	var b bookmark.ChromiumBookmark
+	assert.False(t, out.Supports(&b))
+	assert.ErrorIs(t, out.Write(&b, &buf), errUnsupportedFormat)
+}
+
+// failingWriter fails every write after n bytes
+type failingWriter struct {
+	n      int
+	writes int
+}
+
+var errDiskFull = errors.New("no space left on device")
+
+func (f *failingWriter) Write(p []byte) (int, error) {
+	f.writes++
+	if len(p) > f.n {
+		n := f.n
+		f.n = 0
+		return n, errDiskFull
+	}
+	f.n -= len(p)
+	return len(p), nil
+}
+
+func TestWriteCSV_WriteError(t *testing.T) {
+	values := make([]string, 3*csvFlushRows)
+	for i := range values {
+		values[i] = strconv.Itoa(i)
+	}
+	c := newTestCookies(t, values...)
+
+	w := &failingWriter{n: 1024}
+	assert.ErrorIs(t, WriteCSV(w, c), errDiskFull)
+	assert.Equal(t, 1, w.writes, "nothing is written after the failed write")
+
+	// a small item is only written on the last flush, its error surfaces as well
+	assert.ErrorIs(t, WriteCSV(&failingWriter{}, newTestCookies(t, "abc")), errDiskFull)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, c))
+	assert.Equal(t, 3*csvFlushRows+1, bytes.Count(buf.Bytes(), []byte("\n")))
+}
+
+func TestParseFormats(t *testing.T) {
+	testCases := []struct {
+		flag     string
+		expected []string
+	}{
+		{"csv", []string{"csv"}},
+		{"", []string{""}},
+		{"all", []string{"csv", "json"}},
+		{" JSON , csv ", []string{"json", "csv"}},
+		{"csv,all,header", []string{"csv", "json", "header"}},
+		{"csv,xml", []string{"csv"}},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, ParseFormats(tc.flag), tc.flag)
+	}
+}
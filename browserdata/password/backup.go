@@ -0,0 +1,69 @@
+package password
+
+import (
+	"errors"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/log"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+var errInvalidLogins = errors.New("invalid logins json")
+
+// mergeBackup merges the logins of logins-backup.json missing from logins.json
+var mergeBackup bool
+
+// SetMergeBackup adds the logins of logins-backup.json which aren't in logins.json anymore,
+// eg: the removed ones, they are matched by guid.
+func SetMergeBackup(b bool) {
+	mergeBackup = b
+}
+
+// getFirefoxLoginData reads the logins of logins.json, logins-backup.json is read instead
+// when logins.json is missing or malformed, and merged into it with mergeBackup.
+func getFirefoxLoginData() ([]loginData, error) {
+	defer types.FirefoxPassword.RemoveTemp()
+	defer types.FirefoxPasswordBackup.RemoveTemp()
+
+	logins, err := readFirefoxLogins(types.FirefoxPassword)
+	if err != nil {
+		backup, backupErr := readFirefoxLogins(types.FirefoxPasswordBackup)
+		if backupErr != nil {
+			return nil, err
+		}
+		log.Warnf("read firefox logins.json error: %v, the logins are read from logins-backup.json", err)
+		return backup, nil
+	}
+	log.Debugf("read firefox logins from logins.json")
+	if !mergeBackup || !types.FirefoxPasswordBackup.Exists() {
+		return logins, nil
+	}
+	backup, err := readFirefoxLogins(types.FirefoxPasswordBackup)
+	if err != nil {
+		log.Warnf("read firefox logins-backup.json error: %v", err)
+		return logins, nil
+	}
+	merged := mergeLogins(logins, backup)
+	log.Debugf("merged %d logins of logins-backup.json", len(merged)-len(logins))
+	return merged, nil
+}
+
+// mergeLogins appends the backup logins whose guid isn't in logins, the logins without a guid
+// can't be matched and are skipped.
+func mergeLogins(logins, backup []loginData) []loginData {
+	seen := make(map[string]bool, len(logins))
+	for _, v := range logins {
+		seen[v.GUID] = true
+	}
+	for _, v := range backup {
+		if extractor.ReachedMaxRows(len(logins)) {
+			break
+		}
+		if v.GUID == "" || seen[v.GUID] {
+			continue
+		}
+		seen[v.GUID] = true
+		logins = append(logins, v)
+	}
+	return logins
+}
@@ -0,0 +1,46 @@
+package password
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+const backupLogins = `{"logins": [
+	{"formSubmitURL": "https://example.com/login", "guid": "{a}", "encryptedUsername": "dXNlcg==", "encryptedPassword": "cGFzcw=="},
+	{"formSubmitURL": "https://example.org/login", "guid": "{b}", "encryptedUsername": "dXNlcg==", "encryptedPassword": "cGFzcw=="}]}`
+
+func TestGetFirefoxLoginData_CorruptFallsBackToBackup(t *testing.T) {
+	require.NoError(t, os.WriteFile(types.FirefoxPassword.TempFilename(), []byte(`{"logins": [{"guid": `), 0o600))
+	require.NoError(t, os.WriteFile(types.FirefoxPasswordBackup.TempFilename(), []byte(backupLogins), 0o600))
+
+	data, err := getFirefoxLoginData()
+	require.NoError(t, err)
+	require.Len(t, data, 2)
+	assert.Equal(t, "{a}", data[0].GUID)
+	assert.NoFileExists(t, types.FirefoxPassword.TempFilename())
+	assert.NoFileExists(t, types.FirefoxPasswordBackup.TempFilename())
+}
+
+func TestGetFirefoxLoginData_MissingWithoutBackup(t *testing.T) {
+	_, err := getFirefoxLoginData()
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestGetFirefoxLoginData_MergeBackup(t *testing.T) {
+	SetMergeBackup(true)
+	defer SetMergeBackup(false)
+	logins := `{"logins": [{"formSubmitURL": "https://example.com/login", "guid": "{a}", "encryptedUsername": "dXNlcg==", "encryptedPassword": "cGFzcw=="}]}`
+	require.NoError(t, os.WriteFile(types.FirefoxPassword.TempFilename(), []byte(logins), 0o600))
+	require.NoError(t, os.WriteFile(types.FirefoxPasswordBackup.TempFilename(), []byte(backupLogins), 0o600))
+
+	data, err := getFirefoxLoginData()
+	require.NoError(t, err)
+	require.Len(t, data, 2)
+	assert.Equal(t, "https://example.com/login", data[0].LoginURL)
+	assert.Equal(t, "{b}", data[1].GUID)
+}
@@ -0,0 +1,88 @@
+package password
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moond4rk/hackbrowserdata/log"
+)
+
+// checkBreaches looks up the passwords in the HaveIBeenPwned range API, only the first
+// 5 hex characters of the sha1 of a password are sent, the k-anonymity model of the API.
+var checkBreaches bool
+
+// SetHIBP enables the breach lookup of the passwords, it's the only item sending data to the network.
+func SetHIBP(b bool) {
+	checkBreaches = b
+}
+
+var (
+	hibpRangeURL = "https://api.pwnedpasswords.com/range/"
+	hibpClient   = &http.Client{Timeout: 10 * time.Second}
+)
+
+// hibpPrefixLen is the length of the hash prefix sent to the range API
+const hibpPrefixLen = 5
+
+// setBreachCounts sets how many times every password appears in the known breaches and marks
+// it checked, the lookup stops at the first error, eg: there is no network, the passwords left
+// aren't checked.
+func setBreachCounts(logins []loginData) {
+	counts := make(map[string]int)
+	for i := range logins {
+		l := &logins[i]
+		if !hasPassword(*l) {
+			continue
+		}
+		count, ok := counts[l.Password]
+		if !ok {
+			var err error
+			if count, err = breachCount(l.Password); err != nil {
+				log.Warnf("check password breaches error: %v", err)
+				return
+			}
+			counts[l.Password] = count
+		}
+		l.BreachCount = count
+		l.BreachChecked = true
+	}
+}
+
+// breachCount returns the number of times the password appears in the breaches of the range API
+func breachCount(password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:hibpPrefixLen], hash[hibpPrefixLen:]
+
+	req, err := http.NewRequest(http.MethodGet, hibpRangeURL+prefix, http.NoBody)
+	if err != nil {
+		return 0, err
+	}
+	// the padding hides the number of suffixes of the prefix from the network
+	req.Header.Set("Add-Padding", "true")
+	resp, err := hibpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("range api returned %s", resp.Status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		// every line is SUFFIX:COUNT, the padding lines have a count of 0
+		s, c, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok || !strings.EqualFold(s, suffix) {
+			continue
+		}
+		return strconv.Atoi(c)
+	}
+	return 0, scanner.Err()
+}
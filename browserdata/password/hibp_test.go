@@ -0,0 +1,47 @@
+package password
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+)
+
+func TestSetBreachCounts(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		assert.Equal(t, "true", r.Header.Get("Add-Padding"))
+		// sha1("password") is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+		fmt.Fprint(w, "0000000000000000000000000000000000A:0\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:9659365\r\n")
+	}))
+	defer srv.Close()
+	rangeURL := hibpRangeURL
+	hibpRangeURL = srv.URL + "/range/"
+	t.Cleanup(func() { hibpRangeURL = rangeURL })
+
+	logins := []loginData{
+		{LoginURL: "https://a.example/", Password: "password"},
+		{LoginURL: "https://b.example/", Password: "password"},
+		{LoginURL: "https://c.example/", Password: "not-breached-x7"},
+		{LoginURL: "https://idp.example/", IsFederated: true},
+		{LoginURL: "https://d.example/", Password: extractor.KeychainDenied},
+	}
+	setBreachCounts(logins)
+	assert.Equal(t, 9659365, logins[0].BreachCount)
+	assert.Equal(t, 9659365, logins[1].BreachCount)
+	assert.Equal(t, 0, logins[2].BreachCount)
+	assert.True(t, logins[2].BreachChecked)
+	assert.False(t, logins[3].BreachChecked)
+	assert.False(t, logins[4].BreachChecked, "the placeholder isn't looked up")
+	require.Len(t, paths, 2, "a password is looked up once, the federated login and the placeholder never")
+	for _, p := range paths {
+		assert.Len(t, p, len("/range/")+hibpPrefixLen, "only the hash prefix is sent")
+	}
+	assert.Equal(t, "/range/5BAA6", paths[0])
+}
@@ -3,8 +3,10 @@ package password
 import (
 	"database/sql"
 	"encoding/base64"
+	"errors"
+	"fmt"
 	"os"
-	"sort"
+	"strings"
 	"time"
 
 	"github.com/tidwall/gjson"
@@ -14,45 +16,93 @@ import (
 	"github.com/moond4rk/hackbrowserdata/extractor"
 	"github.com/moond4rk/hackbrowserdata/log"
 	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/sqliteutil"
 	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
 )
 
 func init() {
-	extractor.RegisterExtractor(types.ChromiumPassword, func() extractor.Extractor {
+	extractor.RegisterExtractor(types.ChromiumPassword, extractor.Info{
+		Description: "the saved logins",
+		Sources:     []string{"Login Data"},
+	}, func() extractor.Extractor {
 		return new(ChromiumPassword)
 	})
-	extractor.RegisterExtractor(types.YandexPassword, func() extractor.Extractor {
+	extractor.RegisterExtractor(types.YandexPassword, extractor.Info{
+		Description: "the saved logins",
+		Sources:     []string{"Ya Passman Data"},
+	}, func() extractor.Extractor {
 		return new(YandexPassword)
 	})
-	extractor.RegisterExtractor(types.FirefoxPassword, func() extractor.Extractor {
+	extractor.RegisterExtractor(types.FirefoxPassword, extractor.Info{
+		Description: "the saved logins",
+		Sources:     []string{"logins.json", "logins-backup.json"},
+	}, func() extractor.Extractor {
 		return new(FirefoxPassword)
 	})
 }
 
 type ChromiumPassword []loginData
 
+// loginData is a saved credential, Realm, Federation and DisplayName are only read from chromium,
+// GUID and PasswordChangedDate only from firefox. TimesUsed is how often it was autofilled. The federated "Sign in with"
+// credentials have an identity provider in Federation and no password. Type is totp or hotp for
+// the 2FA seeds saved as passwords, OTPIssuer and OTPAccount are parsed from their uri. Reused is
+// set for the passwords of more than one site. Strength and StrengthScore are only set with the
+// strength analysis, BreachCount and BreachChecked with the breach lookup, a BreachCount of 0 is
+// a password not found in the breaches only if it's checked.
 type loginData struct {
 	UserName    string
 	encryptPass []byte
+	// encryptSize is the size of password_value, which is selected empty if it's too big
+	encryptSize int
 	encryptUser []byte
 	Password    string
-	LoginURL    string
-	CreateDate  time.Time
+	// DecryptMethod is how the password was decrypted, eg: NSS-AES, it's hidden from csv unless requested
+	DecryptMethod       string `csv:"-"`
+	Type                string
+	OTPIssuer           string
+	OTPAccount          string
+	LoginURL            string
+	Realm               string
+	Federation          string
+	DisplayName         string
+	IsFederated         bool
+	GUID                string
+	TimesUsed           int
+	CreateDate          time.Time
+	LastUsedDate        time.Time
+	PasswordChangedDate time.Time
+	Strength            string
+	StrengthScore       int
+	Reused              bool
+	BreachCount         int
+	BreachChecked       bool
 }
 
 const (
-	queryChromiumLogin = `SELECT origin_url, username_value, password_value, date_created FROM logins`
+	queryChromiumLogin = `SELECT origin_url, username_value, %s, %s, date_created, signon_realm, federation_url, display_name, %s, %s FROM logins`
+	// chromiumTimesUsedColumn is how often the login was autofilled
+	chromiumTimesUsedColumn = "times_used"
+	// chromiumLastUsedColumn is added since Chrome 82, 0 if the login was never used
+	// @https://source.chromium.org/chromium/chromium/src/+/main:components/password_manager/core/browser/password_store/login_database.cc
+	chromiumLastUsedColumn = "date_last_used"
+	// federatedRealmPrefix is the prefix of the signon realm of federated credentials,
+	// federation://<origin host>/<identity provider host>
+	federatedRealmPrefix = "federation://"
 )
 
-func (c *ChromiumPassword) Extract(masterKey []byte) error {
-	db, err := sql.Open("sqlite", types.ChromiumPassword.TempFilename())
+func (c *ChromiumPassword) Extract(session *extractor.Session) error {
+	db, err := sql.Open("sqlite", types.ChromiumPassword.DSN())
 	if err != nil {
 		return err
 	}
-	defer os.Remove(types.ChromiumPassword.TempFilename())
+	defer types.ChromiumPassword.RemoveTemp()
 	defer db.Close()
 
-	rows, err := db.Query(queryChromiumLogin)
+	timesUsedColumn, lastUsedColumn := optionalColumn(db, chromiumTimesUsedColumn), optionalColumn(db, chromiumLastUsedColumn)
+	query, args := extractor.FilterURL(fmt.Sprintf(queryChromiumLogin, extractor.EncryptedColumn("password_value"), extractor.SizeColumn("password_value"), timesUsedColumn, lastUsedColumn), "origin_url")
+	query = extractor.FilterEncrypted(query, "password_value")
+	rows, err := db.Query(extractor.LimitQuery(query), args...)
 	if err != nil {
 		return err
 	}
@@ -60,43 +110,79 @@ func (c *ChromiumPassword) Extract(masterKey []byte) error {
 
 	for rows.Next() {
 		var (
-			url, username string
-			pwd, password []byte
-			create        int64
+			url, username     string
+			realm, federation string
+			displayName       string
+			pwd               []byte
+			pwdSize           int
+			create, lastUsed  int64
+			timesUsed         int
 		)
-		if err := rows.Scan(&url, &username, &pwd, &create); err != nil {
+		if err := rows.Scan(&url, &username, &pwd, &pwdSize, &create, &realm, &federation, &displayName, &timesUsed, &lastUsed); err != nil {
 			log.Errorf("scan chromium password error: %v", err)
 		}
 		login := loginData{
 			UserName:    username,
 			encryptPass: pwd,
+			encryptSize: pwdSize,
 			LoginURL:    url,
+			Realm:       realm,
+			Federation:  federation,
+			DisplayName: displayName,
+			IsFederated: federation != "" || strings.HasPrefix(realm, federatedRealmPrefix),
+			TimesUsed:   timesUsed,
 		}
-		if len(pwd) > 0 {
-			if len(masterKey) == 0 {
-				password, err = crypto.DecryptWithDPAPI(pwd)
-			} else {
-				password, err = crypto.DecryptWithChromium(masterKey, pwd)
-			}
-			if err != nil {
-				log.Errorf("decrypt chromium password error: %v", err)
-			}
+		if lastUsed > 0 {
+			login.LastUsedDate = typeutil.TimeEpoch(lastUsed)
 		}
 		if create > time.Now().Unix() {
 			login.CreateDate = typeutil.TimeEpoch(create)
 		} else {
 			login.CreateDate = typeutil.TimeStamp(create)
 		}
-		login.Password = string(password)
 		*c = append(*c, login)
 	}
-	// sort with create date
-	sort.Slice(*c, func(i, j int) bool {
-		return (*c)[i].CreateDate.After((*c)[j].CreateDate)
+	extractor.DecryptEach(len(*c), func(i int) {
+		decryptLogin(&(*c)[i], session, "chromium")
 	})
+	analyze(*c)
+	sortLogins(*c)
 	return nil
 }
 
+// decryptLogin decrypts the password of the login of the browser, eg: chromium or yandex, it's
+// called concurrently by the decrypt workers.
+func decryptLogin(login *loginData, session *extractor.Session, browser string) {
+	if len(login.encryptPass) == 0 {
+		if login.encryptSize > 0 {
+			// too big to be decrypted under the max value size
+			login.Password = extractor.TruncateValue("", login.encryptSize)
+		}
+		return
+	}
+	decryptor := session.Decryptor()
+	password, err := decryptor.Decrypt(login.encryptPass)
+	if errors.Is(err, crypto.ErrKeyDenied) {
+		// the metadata of the login is kept, the value is marked instead of being counted as failed
+		login.Password = extractor.KeychainDenied
+		return
+	}
+	login.DecryptMethod = decryptor.Method(login.encryptPass)
+	session.CountDecrypt(err)
+	if err != nil {
+		log.Errorf("decrypt %s password error: %v", browser, err)
+	}
+	login.Password = extractor.TruncateValue(string(password), len(password))
+}
+
+// optionalColumn returns the column of the logins table, or 0 if the browser version hasn't it
+func optionalColumn(db *sql.DB, column string) string {
+	if ok, err := sqliteutil.ColumnExists(db, "logins", column); err == nil && ok {
+		return column
+	}
+	return "0"
+}
+
 func (c *ChromiumPassword) Name() string {
 	return "password"
 }
@@ -108,18 +194,20 @@ func (c *ChromiumPassword) Len() int {
 type YandexPassword []loginData
 
 const (
-	queryYandexLogin = `SELECT action_url, username_value, password_value, date_created FROM logins`
+	queryYandexLogin = `SELECT action_url, username_value, %s, %s, date_created, %s FROM logins`
 )
 
-func (c *YandexPassword) Extract(masterKey []byte) error {
-	db, err := sql.Open("sqlite", types.YandexPassword.TempFilename())
+func (c *YandexPassword) Extract(session *extractor.Session) error {
+	db, err := sql.Open("sqlite", types.YandexPassword.DSN())
 	if err != nil {
 		return err
 	}
-	defer os.Remove(types.YandexPassword.TempFilename())
+	defer types.YandexPassword.RemoveTemp()
 	defer db.Close()
 
-	rows, err := db.Query(queryYandexLogin)
+	query, args := extractor.FilterURL(fmt.Sprintf(queryYandexLogin, extractor.EncryptedColumn("password_value"), extractor.SizeColumn("password_value"), optionalColumn(db, chromiumTimesUsedColumn)), "action_url")
+	query = extractor.FilterEncrypted(query, "password_value")
+	rows, err := db.Query(extractor.LimitQuery(query), args...)
 	if err != nil {
 		return err
 	}
@@ -128,40 +216,34 @@ func (c *YandexPassword) Extract(masterKey []byte) error {
 	for rows.Next() {
 		var (
 			url, username string
-			pwd, password []byte
+			pwd           []byte
+			pwdSize       int
 			create        int64
+			timesUsed     int
 		)
-		if err := rows.Scan(&url, &username, &pwd, &create); err != nil {
+		if err := rows.Scan(&url, &username, &pwd, &pwdSize, &create, &timesUsed); err != nil {
 			log.Errorf("scan yandex password error: %v", err)
 		}
 		login := loginData{
 			UserName:    username,
 			encryptPass: pwd,
+			encryptSize: pwdSize,
 			LoginURL:    url,
+			TimesUsed:   timesUsed,
 		}
 
-		if len(pwd) > 0 {
-			if len(masterKey) == 0 {
-				password, err = crypto.DecryptWithDPAPI(pwd)
-			} else {
-				password, err = crypto.DecryptWithChromium(masterKey, pwd)
-			}
-			if err != nil {
-				log.Errorf("decrypt yandex password error: %v", err)
-			}
-		}
 		if create > time.Now().Unix() {
 			login.CreateDate = typeutil.TimeEpoch(create)
 		} else {
 			login.CreateDate = typeutil.TimeStamp(create)
 		}
-		login.Password = string(password)
 		*c = append(*c, login)
 	}
-	// sort with create date
-	sort.Slice(*c, func(i, j int) bool {
-		return (*c)[i].CreateDate.After((*c)[j].CreateDate)
+	extractor.DecryptEach(len(*c), func(i int) {
+		decryptLogin(&(*c)[i], session, "yandex")
 	})
+	analyze(*c)
+	sortLogins(*c)
 	return nil
 }
 
@@ -175,53 +257,70 @@ func (c *YandexPassword) Len() int {
 
 type FirefoxPassword []loginData
 
-func (f *FirefoxPassword) Extract(globalSalt []byte) error {
+func (f *FirefoxPassword) Extract(session *extractor.Session) error {
 	logins, err := getFirefoxLoginData()
 	if err != nil {
 		return err
 	}
 
+	decryptor := session.Decryptor()
 	for _, v := range logins {
-		userPBE, err := crypto.NewASN1PBE(v.encryptUser)
-		if err != nil {
-			return err
-		}
-		pwdPBE, err := crypto.NewASN1PBE(v.encryptPass)
-		if err != nil {
-			return err
-		}
-		user, err := userPBE.Decrypt(globalSalt)
-		if err != nil {
-			return err
+		if !extractor.MatchURL(v.LoginURL) {
+			continue
 		}
-		pwd, err := pwdPBE.Decrypt(globalSalt)
+		user, pwd, method, err := decryptFirefoxLogin(v, decryptor)
+		session.CountDecrypt(err)
 		if err != nil {
-			return err
+			// a corrupt login is skipped, its garbage is never written as the username or password
+			log.Errorf("decrypt firefox password of %s error: %v", v.LoginURL, err)
+			continue
 		}
 		*f = append(*f, loginData{
-			LoginURL:   v.LoginURL,
-			UserName:   string(user),
-			Password:   string(pwd),
-			CreateDate: v.CreateDate,
+			LoginURL:            v.LoginURL,
+			UserName:            string(user),
+			Password:            extractor.TruncateValue(string(pwd), len(pwd)),
+			DecryptMethod:       method,
+			GUID:                v.GUID,
+			TimesUsed:           v.TimesUsed,
+			CreateDate:          v.CreateDate,
+			LastUsedDate:        v.LastUsedDate,
+			PasswordChangedDate: v.PasswordChangedDate,
 		})
 	}
 
-	sort.Slice(*f, func(i, j int) bool {
-		return (*f)[i].CreateDate.After((*f)[j].CreateDate)
-	})
+	analyze(*f)
+	sortLogins(*f)
 	return nil
 }
 
-func getFirefoxLoginData() ([]loginData, error) {
-	s, err := os.ReadFile(types.FirefoxPassword.TempFilename())
+// decryptFirefoxLogin decrypts the username and password of the login with the nss decryptor,
+// method is the decryption method of the password pbe.
+func decryptFirefoxLogin(v loginData, decryptor crypto.Decryptor) (user, pwd []byte, method string, err error) {
+	if user, err = decryptor.Decrypt(v.encryptUser); err != nil {
+		return nil, nil, "", err
+	}
+	if pwd, err = decryptor.Decrypt(v.encryptPass); err != nil {
+		return nil, nil, "", err
+	}
+	return user, pwd, decryptor.Method(v.encryptPass), nil
+}
+
+// readFirefoxLogins reads the logins of logins.json, or of logins-backup.json, they have the same format
+func readFirefoxLogins(item types.DataType) ([]loginData, error) {
+	s, err := os.ReadFile(item.TempFilename())
 	if err != nil {
 		return nil, err
 	}
-	defer os.Remove(types.FirefoxPassword.TempFilename())
+	if !gjson.ValidBytes(s) {
+		return nil, errInvalidLogins
+	}
 	loginsJSON := gjson.GetBytes(s, "logins")
 	var logins []loginData
 	if loginsJSON.Exists() {
 		for _, v := range loginsJSON.Array() {
+			if extractor.ReachedMaxRows(len(logins)) {
+				break
+			}
 			var (
 				m    loginData
 				user []byte
@@ -238,7 +337,12 @@ func getFirefoxLoginData() ([]loginData, error) {
 			}
 			m.encryptUser = user
 			m.encryptPass = pass
+			m.GUID = v.Get("guid").String()
+			m.TimesUsed = int(v.Get("timesUsed").Int())
+			// the times of logins.json are milliseconds since epoch
 			m.CreateDate = typeutil.TimeStamp(v.Get("timeCreated").Int() / 1000)
+			m.LastUsedDate = typeutil.TimeStamp(v.Get("timeLastUsed").Int() / 1000)
+			m.PasswordChangedDate = typeutil.TimeStamp(v.Get("timePasswordChanged").Int() / 1000)
 			logins = append(logins, m)
 		}
 	}
@@ -0,0 +1,114 @@
+package password
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/crypto"
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+const createChromiumLoginTable = `CREATE TABLE logins (origin_url VARCHAR NOT NULL, action_url VARCHAR, username_element VARCHAR, username_value VARCHAR, password_element VARCHAR, password_value BLOB, submit_element VARCHAR, signon_realm VARCHAR NOT NULL, date_created INTEGER NOT NULL, blacklisted_by_user INTEGER NOT NULL, scheme INTEGER NOT NULL, federation_url VARCHAR, display_name VARCHAR)`
+
+func TestChromiumPassword_ExtractFederated(t *testing.T) {
+	db, err := sql.Open("sqlite", types.ChromiumPassword.TempFilename())
+	require.NoError(t, err)
+	_, err = db.Exec(createChromiumLoginTable)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO logins (origin_url, username_value, password_value, signon_realm, date_created, blacklisted_by_user, scheme, federation_url, display_name) VALUES
+		('https://example.com/', 'alice@example.com', x'', 'federation://example.com/accounts.google.com', 13300000000000000, 0, 0, 'https://accounts.google.com', 'Alice'),
+		('https://site.test/login', 'bob', x'', 'https://site.test/', 13200000000000000, 0, 0, '', '')`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	var c ChromiumPassword
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 2)
+
+	assert.True(t, c[0].IsFederated)
+	assert.Equal(t, "federation://example.com/accounts.google.com", c[0].Realm)
+	assert.Equal(t, "https://accounts.google.com", c[0].Federation)
+	assert.Equal(t, "Alice", c[0].DisplayName)
+	assert.Empty(t, c[0].Password)
+
+	assert.False(t, c[1].IsFederated)
+	assert.Equal(t, "https://site.test/", c[1].Realm)
+	assert.Empty(t, c[1].Federation)
+}
+
+func TestChromiumPassword_ExtractKeychainDenied(t *testing.T) {
+	db, err := sql.Open("sqlite", types.ChromiumPassword.TempFilename())
+	require.NoError(t, err)
+	_, err = db.Exec(createChromiumLoginTable)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO logins (origin_url, username_value, password_value, signon_realm, date_created, blacklisted_by_user, scheme) VALUES
+		('https://site.test/login', 'bob', x'763130deadbeef', 'https://site.test/', 13200000000000000, 0, 0)`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	session := extractor.NewSession(crypto.DeniedDecryptor{})
+	var c ChromiumPassword
+	require.NoError(t, c.Extract(session))
+	require.Len(t, c, 1)
+	ok, failed := session.DecryptStats()
+	assert.Zero(t, ok+failed)
+	assert.Equal(t, "https://site.test/login", c[0].LoginURL)
+	assert.Equal(t, "bob", c[0].UserName)
+	assert.False(t, c[0].CreateDate.IsZero())
+	assert.Equal(t, extractor.KeychainDenied, c[0].Password)
+}
+
+func TestGetFirefoxLoginData(t *testing.T) {
+	logins := `{"logins": [{"id": 1, "hostname": "https://example.com", "formSubmitURL": "https://example.com/login",
+		"guid": "{0b0a4b4c-1f2e-4d3c-9a8b-7c6d5e4f3a2b}", "encryptedUsername": "dXNlcg==", "encryptedPassword": "cGFzcw==",
+		"timeCreated": 1700000000000, "timeLastUsed": 1710000000000, "timePasswordChanged": 1705000000000}]}`
+	require.NoError(t, os.WriteFile(types.FirefoxPassword.TempFilename(), []byte(logins), 0o600))
+
+	data, err := getFirefoxLoginData()
+	require.NoError(t, err)
+	require.Len(t, data, 1)
+	assert.Equal(t, "{0b0a4b4c-1f2e-4d3c-9a8b-7c6d5e4f3a2b}", data[0].GUID)
+	assert.Equal(t, int64(1700000000), data[0].CreateDate.Unix())
+	assert.Equal(t, int64(1710000000), data[0].LastUsedDate.Unix())
+	assert.Equal(t, int64(1705000000), data[0].PasswordChangedDate.Unix())
+	assert.Equal(t, []byte("pass"), data[0].encryptPass)
+	assert.NoFileExists(t, types.FirefoxPassword.TempFilename())
+}
+
+func TestFirefoxPassword_ExtractSkipsCorruptLogins(t *testing.T) {
+	logins := `{"logins": [{"formSubmitURL": "https://example.com/login", "encryptedUsername": "dXNlcg==", "encryptedPassword": "cGFzcw=="}]}`
+	require.NoError(t, os.WriteFile(types.FirefoxPassword.TempFilename(), []byte(logins), 0o600))
+
+	var f FirefoxPassword
+	require.NoError(t, f.Extract(nil))
+	assert.Empty(t, f)
+}
+
+func TestChromiumPassword_AnalyzeKeychainDenied(t *testing.T) {
+	SetStrength(true)
+	t.Cleanup(func() { SetStrength(false) })
+	db, err := sql.Open("sqlite", types.ChromiumPassword.TempFilename())
+	require.NoError(t, err)
+	_, err = db.Exec(createChromiumLoginTable)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO logins (origin_url, username_value, password_value, signon_realm, date_created, blacklisted_by_user, scheme) VALUES
+		('https://a.test/login', 'bob', x'763130deadbeef', 'https://a.test/', 13200000000000000, 0, 0),
+		('https://b.test/login', 'bob', x'763130beefdead', 'https://b.test/', 13200000000000000, 0, 0)`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	var c ChromiumPassword
+	require.NoError(t, c.Extract(extractor.NewSession(crypto.DeniedDecryptor{})))
+	require.Len(t, c, 2)
+	for _, l := range c {
+		assert.Equal(t, extractor.KeychainDenied, l.Password)
+		assert.False(t, l.Reused, "the placeholders aren't the same password")
+		assert.Empty(t, l.Strength)
+		assert.Empty(t, l.Type)
+	}
+}
@@ -0,0 +1,56 @@
+package password
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// SortCreated sorts the passwords by create date, the newest first
+	SortCreated = "created"
+	// SortUsage sorts the passwords by how often they were autofilled, the last used first on a tie
+	SortUsage = "usage"
+	// SortLastUsed sorts the passwords by last used date, the last used first
+	SortLastUsed = "last-used"
+)
+
+// loginComparators are the orders of the passwords selectable by the sort option
+var loginComparators = map[string]func(a, b *loginData) bool{
+	SortCreated: func(a, b *loginData) bool {
+		return a.CreateDate.After(b.CreateDate)
+	},
+	SortUsage: func(a, b *loginData) bool {
+		if a.TimesUsed != b.TimesUsed {
+			return a.TimesUsed > b.TimesUsed
+		}
+		return a.LastUsedDate.After(b.LastUsedDate)
+	},
+	SortLastUsed: func(a, b *loginData) bool {
+		return a.LastUsedDate.After(b.LastUsedDate)
+	},
+}
+
+// sortBy is the order of the passwords, the create date by default
+var sortBy = SortCreated
+
+// SetSort sets the order of the passwords, empty is the create date
+func SetSort(s string) error {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		s = SortCreated
+	}
+	if _, ok := loginComparators[s]; !ok {
+		return fmt.Errorf("unknown password sort %q, available: %s|%s|%s", s, SortCreated, SortUsage, SortLastUsed)
+	}
+	sortBy = s
+	return nil
+}
+
+// sortLogins sorts the logins with the selected comparator
+func sortLogins(logins []loginData) {
+	less := loginComparators[sortBy]
+	sort.SliceStable(logins, func(i, j int) bool {
+		return less(&logins[i], &logins[j])
+	})
+}
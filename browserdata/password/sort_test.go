@@ -0,0 +1,48 @@
+package password
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func TestChromiumPassword_ExtractSortUsage(t *testing.T) {
+	require.NoError(t, SetSort(SortUsage))
+	defer func() { _ = SetSort("") }()
+
+	db, err := sql.Open("sqlite", types.ChromiumPassword.TempFilename())
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE logins (origin_url VARCHAR NOT NULL, username_value VARCHAR, password_value BLOB, signon_realm VARCHAR NOT NULL, date_created INTEGER NOT NULL, federation_url VARCHAR, display_name VARCHAR, times_used INTEGER, date_last_used INTEGER NOT NULL DEFAULT 0)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO logins VALUES
+		('https://new.test/', 'new', x'', 'https://new.test/', 13300000000000000, '', '', 0, 0),
+		('https://often.test/', 'often', x'', 'https://often.test/', 13100000000000000, '', '', 42, 13200000000000000),
+		('https://recent.test/', 'recent', x'', 'https://recent.test/', 13100000000000000, '', '', 3, 13250000000000000),
+		('https://old.test/', 'old', x'', 'https://old.test/', 13100000000000000, '', '', 3, 13150000000000000)`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	var c ChromiumPassword
+	require.NoError(t, c.Extract(nil))
+	var users []string
+	for _, v := range c {
+		users = append(users, v.UserName)
+	}
+	assert.Equal(t, []string{"often", "recent", "old", "new"}, users)
+	assert.Equal(t, 42, c[0].TimesUsed)
+	assert.False(t, c[0].LastUsedDate.IsZero())
+	assert.True(t, c[3].LastUsedDate.IsZero())
+}
+
+func TestSetSort(t *testing.T) {
+	defer func() { _ = SetSort("") }()
+	assert.NoError(t, SetSort("Last-Used"))
+	assert.Equal(t, SortLastUsed, sortBy)
+	assert.Error(t, SetSort("name"))
+	assert.NoError(t, SetSort(""))
+	assert.Equal(t, SortCreated, sortBy)
+}
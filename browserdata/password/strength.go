@@ -0,0 +1,137 @@
+package password
+
+import (
+	_ "embed"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+)
+
+// analyzeStrength adds the strength of every decrypted password to the output,
+// it's made for security audits and reveals nothing more than the passwords themselves.
+var analyzeStrength bool
+
+// SetStrength enables the strength analysis of the passwords
+func SetStrength(b bool) {
+	analyzeStrength = b
+}
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+// commonPasswords are the most common leaked passwords, they are weak whatever their length
+var commonPasswords = func() map[string]bool {
+	m := make(map[string]bool)
+	for _, p := range strings.Fields(commonPasswordList) {
+		m[p] = true
+	}
+	return m
+}()
+
+// strengthLabels are the labels of the scores, 0 is a common or a very short password
+var strengthLabels = []string{"very weak", "weak", "fair", "good", "strong"}
+
+// analyze sets the type, reuse, strength and breach count of the logins, the logins without a
+// password, eg: the federated credentials, or with a placeholder instead, are left untouched.
+func analyze(logins []loginData) {
+	classify(logins)
+	markReused(logins)
+	if checkBreaches {
+		setBreachCounts(logins)
+	}
+	if !analyzeStrength {
+		return
+	}
+	for i := range logins {
+		l := &logins[i]
+		if !hasPassword(*l) {
+			continue
+		}
+		l.StrengthScore = strengthScore(l.Password)
+		l.Strength = strengthLabels[l.StrengthScore]
+	}
+}
+
+// hasPassword reports whether the login has a password to analyze, the placeholders of the
+// passwords which couldn't be read, eg: the keychain was denied or the value was truncated,
+// would be scored, looked up and matched to each other otherwise.
+func hasPassword(l loginData) bool {
+	return l.Password != "" && l.Password != extractor.KeychainDenied && !extractor.IsTruncated(l.Password)
+}
+
+// markReused marks the passwords used by more than one site
+func markReused(logins []loginData) {
+	sites := make(map[string]map[string]bool)
+	for _, l := range logins {
+		if !hasPassword(l) {
+			continue
+		}
+		if sites[l.Password] == nil {
+			sites[l.Password] = make(map[string]bool)
+		}
+		sites[l.Password][site(l)] = true
+	}
+	for i := range logins {
+		l := &logins[i]
+		l.Reused = hasPassword(*l) && len(sites[l.Password]) > 1
+	}
+}
+
+// strengthScore scores the password from 0 to 4 by its length and character classes
+func strengthScore(password string) int {
+	if commonPasswords[strings.ToLower(password)] {
+		return 0
+	}
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	classes := 0
+	for _, ok := range []bool{lower, upper, digit, symbol} {
+		if ok {
+			classes++
+		}
+	}
+
+	length := len([]rune(password))
+	var score int
+	for _, min := range []int{8, 12, 16} {
+		if length >= min {
+			score++
+		}
+	}
+	if classes >= 3 {
+		score++
+	}
+	if classes == 1 && score > 0 {
+		score--
+	}
+	if length < 8 {
+		score = 0
+	}
+	if score > len(strengthLabels)-1 {
+		score = len(strengthLabels) - 1
+	}
+	return score
+}
+
+// site returns the host of the login, the pages of a site share its password
+func site(l loginData) string {
+	for _, raw := range []string{l.LoginURL, l.Realm} {
+		if u, err := url.Parse(raw); err == nil && u.Host != "" {
+			return strings.ToLower(u.Hostname())
+		}
+	}
+	return l.LoginURL
+}
@@ -0,0 +1,76 @@
+package password
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+)
+
+func TestStrengthScore(t *testing.T) {
+	tests := []struct {
+		password string
+		score    int
+	}{
+		{"password", 0},
+		{"Password1", 0},
+		{"abc", 0},
+		{"abcdefgh", 0},
+		{"abcdefgh12", 1},
+		{"Abcdefgh12", 2},
+		{"Abcdefgh12!x", 3},
+		{"Abcdefgh12!xyz#%", 4},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.score, strengthScore(tt.password), tt.password)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	SetStrength(true)
+	t.Cleanup(func() { SetStrength(false) })
+
+	logins := []loginData{
+		{LoginURL: "https://a.example/login", Password: "Abcdefgh12!xyz#%"},
+		{LoginURL: "https://a.example/signin", Password: "Abcdefgh12!xyz#%"},
+		{LoginURL: "https://b.example/", Password: "qwerty"},
+		{LoginURL: "https://c.example/", Password: "qwerty"},
+		{LoginURL: "https://idp.example/", IsFederated: true},
+	}
+	analyze(logins)
+	assert.Equal(t, "strong", logins[0].Strength)
+	assert.False(t, logins[0].Reused, "same site")
+	assert.Equal(t, "very weak", logins[2].Strength)
+	assert.True(t, logins[2].Reused)
+	assert.True(t, logins[3].Reused)
+	assert.Empty(t, logins[4].Strength)
+}
+
+func TestAnalyze_ReusedWithoutStrength(t *testing.T) {
+	logins := []loginData{
+		{LoginURL: "https://b.example/", Password: "qwerty"},
+		{LoginURL: "https://c.example/", Password: "qwerty"},
+		{LoginURL: "https://d.example/", Password: "Abcdefgh12!xyz#%"},
+	}
+	analyze(logins)
+	assert.True(t, logins[0].Reused)
+	assert.True(t, logins[1].Reused)
+	assert.False(t, logins[2].Reused)
+	assert.Empty(t, logins[0].Strength)
+}
+
+func TestAnalyze_Placeholders(t *testing.T) {
+	SetStrength(true)
+	t.Cleanup(func() { SetStrength(false) })
+	truncated := extractor.TruncateValue("", 1<<20)
+	logins := []loginData{
+		{LoginURL: "https://a.example/", Password: truncated},
+		{LoginURL: "https://b.example/", Password: truncated},
+	}
+	analyze(logins)
+	for _, l := range logins {
+		assert.False(t, l.Reused)
+		assert.Empty(t, l.Strength)
+	}
+}
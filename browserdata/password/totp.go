@@ -0,0 +1,54 @@
+package password
+
+import (
+	"net/url"
+	"strings"
+)
+
+// the types of the saved values, some users save the seeds of their authenticator as passwords
+const (
+	typePassword = "password"
+	typeTOTP     = "totp"
+	typeHOTP     = "hotp"
+)
+
+// classify sets the type of every decrypted password, the otpauth:// uris of 2FA seeds are
+// totp or hotp with their issuer and account, the password itself is kept as is.
+// @https://github.com/google/google-authenticator/wiki/Key-Uri-Format
+func classify(logins []loginData) {
+	for i := range logins {
+		l := &logins[i]
+		if !hasPassword(*l) {
+			continue
+		}
+		l.Type = typePassword
+		if otpType, issuer, account, ok := parseOTPAuth(l.Password); ok {
+			l.Type, l.OTPIssuer, l.OTPAccount = otpType, issuer, account
+		}
+	}
+}
+
+// parseOTPAuth parses an otpauth://totp/Issuer:account?secret=...&issuer=Issuer uri,
+// the issuer parameter wins over the prefix of the label.
+func parseOTPAuth(s string) (otpType, issuer, account string, ok bool) {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil || !strings.EqualFold(u.Scheme, "otpauth") {
+		return "", "", "", false
+	}
+	otpType = strings.ToLower(u.Host)
+	if otpType != typeTOTP && otpType != typeHOTP {
+		return "", "", "", false
+	}
+	query := u.Query()
+	if query.Get("secret") == "" {
+		return "", "", "", false
+	}
+	account = strings.TrimPrefix(u.Path, "/")
+	if prefix, rest, found := strings.Cut(account, ":"); found {
+		issuer, account = strings.TrimSpace(prefix), strings.TrimSpace(rest)
+	}
+	if v := query.Get("issuer"); v != "" {
+		issuer = v
+	}
+	return otpType, issuer, account, true
+}
@@ -0,0 +1,35 @@
+package password
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	const seed = "otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example%20Inc"
+	logins := []loginData{
+		{Password: "hunter2"},
+		{Password: seed},
+		{Password: " otpauth://hotp/bob?secret=JBSWY3DPEHPK3PXP&counter=3 "},
+		{Password: "otpauth://totp/carol"},
+		{Password: "otpauth://steam/dave?secret=JBSWY3DPEHPK3PXP"},
+		{Federation: "https://accounts.google.com"},
+	}
+	classify(logins)
+
+	expected := []struct{ typ, issuer, account string }{
+		{typePassword, "", ""},
+		{typeTOTP, "Example Inc", "alice@example.com"},
+		{typeHOTP, "", "bob"},
+		{typePassword, "", ""},
+		{typePassword, "", ""},
+		{"", "", ""},
+	}
+	for i, e := range expected {
+		assert.Equal(t, e.typ, logins[i].Type, logins[i].Password)
+		assert.Equal(t, e.issuer, logins[i].OTPIssuer, logins[i].Password)
+		assert.Equal(t, e.account, logins[i].OTPAccount, logins[i].Password)
+	}
+	assert.Equal(t, seed, logins[1].Password, "the seed is kept as is")
+}
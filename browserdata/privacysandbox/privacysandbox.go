@@ -0,0 +1,106 @@
+package privacysandbox
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	// import sqlite3 driver
+	_ "modernc.org/sqlite"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/log"
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/sqliteutil"
+	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
+)
+
+func init() {
+	extractor.RegisterExtractor(types.ChromiumPrivacySandbox, extractor.Info{
+		Description: "the attribution reporting events of the privacy sandbox",
+		Sources:     []string{"Conversions"},
+	}, func() extractor.Extractor {
+		return new(ChromiumPrivacySandbox)
+	})
+}
+
+// ChromiumPrivacySandbox is the attribution reporting events of the privacy sandbox kept in
+// the Conversions database, an impression is an ad seen on ImpressionOrigin and a conversion
+// is the attributed action on ConversionOrigin, both are reported to ReportingOrigin.
+type ChromiumPrivacySandbox []attribution
+
+type attribution struct {
+	Event            string
+	ReportingOrigin  string
+	ImpressionOrigin string
+	ConversionOrigin string
+	EventTime        time.Time
+}
+
+// @https://source.chromium.org/chromium/chromium/src/+/main:content/browser/attribution_reporting/attribution_storage_sql.cc
+const (
+	queryChromiumImpressions = `SELECT reporting_origin, impression_origin, conversion_origin, impression_time FROM impressions`
+	queryChromiumConversions = `SELECT i.reporting_origin, i.impression_origin, i.conversion_origin, c.conversion_time
+		FROM conversions c JOIN impressions i ON i.impression_id = c.impression_id`
+)
+
+func (c *ChromiumPrivacySandbox) Extract(_ *extractor.Session) error {
+	db, err := sql.Open("sqlite", types.ChromiumPrivacySandbox.DSN())
+	if err != nil {
+		return err
+	}
+	defer types.ChromiumPrivacySandbox.RemoveTemp()
+	defer db.Close()
+
+	if ok, err := sqliteutil.TableExists(db, "impressions"); err != nil || !ok {
+		// the database is created before the feature stores anything, or by a newer schema
+		log.Debugf("chromium conversions has no impressions table, err: %v", err)
+		return nil
+	}
+	if err := c.query(db, "impression", queryChromiumImpressions); err != nil {
+		return err
+	}
+	if ok, err := sqliteutil.TableExists(db, "conversions"); err == nil && ok {
+		if err := c.query(db, "conversion", queryChromiumConversions); err != nil {
+			return err
+		}
+	}
+	sort.SliceStable(*c, func(i, j int) bool {
+		return (*c)[i].EventTime.After((*c)[j].EventTime)
+	})
+	return nil
+}
+
+func (c *ChromiumPrivacySandbox) query(db *sql.DB, event, query string) error {
+	rows, err := db.Query(extractor.LimitQuery(query))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			reportingOrigin, impressionOrigin, conversionOrigin string
+			eventTime                                           int64
+		)
+		if err := rows.Scan(&reportingOrigin, &impressionOrigin, &conversionOrigin, &eventTime); err != nil {
+			log.Warnf("scan chromium %s error: %v", event, err)
+			continue
+		}
+		*c = append(*c, attribution{
+			Event:            event,
+			ReportingOrigin:  reportingOrigin,
+			ImpressionOrigin: impressionOrigin,
+			ConversionOrigin: conversionOrigin,
+			EventTime:        typeutil.TimeEpoch(eventTime),
+		})
+	}
+	return rows.Err()
+}
+
+func (c *ChromiumPrivacySandbox) Name() string {
+	return "privacySandbox"
+}
+
+func (c *ChromiumPrivacySandbox) Len() int {
+	return len(*c)
+}
@@ -0,0 +1,39 @@
+package privacysandbox
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/testutil"
+)
+
+func TestChromiumPrivacySandbox_Extract(t *testing.T) {
+	testutil.CreateSQLite(t, types.ChromiumPrivacySandbox,
+		`CREATE TABLE impressions (impression_id INTEGER PRIMARY KEY, impression_data TEXT NOT NULL, impression_origin TEXT NOT NULL, conversion_origin TEXT NOT NULL, reporting_origin TEXT NOT NULL, impression_time INTEGER NOT NULL, expiry_time INTEGER NOT NULL, num_conversions INTEGER DEFAULT 0, active INTEGER DEFAULT 1)`,
+		`CREATE TABLE conversions (conversion_id INTEGER PRIMARY KEY, impression_id INTEGER, conversion_data TEXT NOT NULL, conversion_time INTEGER NOT NULL, report_time INTEGER NOT NULL, attribution_credit INTEGER NOT NULL)`,
+		`INSERT INTO impressions (impression_id, impression_data, impression_origin, conversion_origin, reporting_origin, impression_time, expiry_time) VALUES
+			(1, '1', 'https://news.example', 'https://shop.example', 'https://ads.example', 13340000000000000, 13350000000000000)`,
+		`INSERT INTO conversions VALUES (1, 1, '7', 13340000100000000, 13340000200000000, 100)`,
+	)
+
+	var c ChromiumPrivacySandbox
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 2)
+	assert.Equal(t, "conversion", c[0].Event)
+	assert.Equal(t, "https://ads.example", c[0].ReportingOrigin)
+	assert.Equal(t, "https://shop.example", c[0].ConversionOrigin)
+	assert.Equal(t, "impression", c[1].Event)
+	assert.Equal(t, "https://news.example", c[1].ImpressionOrigin)
+	assert.True(t, c[0].EventTime.After(c[1].EventTime))
+}
+
+func TestChromiumPrivacySandbox_ExtractWithoutTables(t *testing.T) {
+	testutil.CreateSQLite(t, types.ChromiumPrivacySandbox, `CREATE TABLE meta (key TEXT, value TEXT)`)
+
+	var c ChromiumPrivacySandbox
+	require.NoError(t, c.Extract(nil))
+	assert.Empty(t, c)
+}
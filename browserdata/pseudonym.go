@@ -0,0 +1,103 @@
+package browserdata
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// pseudonym replaces usernames and email addresses with stable fake values,
+// it's used for sharing datasets without leaking real identities.
+var pseudonym *pseudonymizer
+
+// SetPseudonymize enables or disables the pseudonymization of usernames and emails.
+// A new random key is generated every time it's enabled, so the same real value
+// always maps to the same pseudonym within a run, but not across runs.
+func SetPseudonymize(enabled bool) error {
+	if !enabled {
+		pseudonym = nil
+		return nil
+	}
+	p, err := newPseudonymizer()
+	if err != nil {
+		return err
+	}
+	pseudonym = p
+	return nil
+}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// usernameFields are the field names that always hold an account name
+var usernameFields = map[string]bool{
+	"UserName":    true,
+	"DisplayName": true,
+	"OTPAccount":  true,
+	"Account":     true,
+}
+
+// emailFields are the field names whose text may carry the email of the user, eg: the
+// mailto urls or the title of a webmail page. The other fields are left alone, the
+// secrets like the passwords and the cookie values are never rewritten.
+var emailFields = map[string]bool{
+	"URL":         true,
+	"LoginURL":    true,
+	"StartURL":    true,
+	"HomepageURL": true,
+	"Origin":      true,
+	"Realm":       true,
+	"Title":       true,
+	"Name":        true,
+	"Input":       true,
+}
+
+type pseudonymizer struct {
+	key []byte
+}
+
+func newPseudonymizer() (*pseudonymizer, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return &pseudonymizer{key: key}, nil
+}
+
+// field pseudonymizes the value of the named field
+func (p *pseudonymizer) field(name, value string) string {
+	switch {
+	case usernameFields[name]:
+		return p.username(value)
+	case emailFields[name]:
+		return p.emails(value)
+	default:
+		return value
+	}
+}
+
+// username returns user_<hash8>@example.com for emails, otherwise user_<hash8>
+func (p *pseudonymizer) username(value string) string {
+	if value == "" {
+		return ""
+	}
+	if emailPattern.MatchString(value) {
+		return p.emails(value)
+	}
+	return "user_" + p.hash(value)
+}
+
+// emails replaces every email address in value with user_<hash8>@example.com
+func (p *pseudonymizer) emails(value string) string {
+	return emailPattern.ReplaceAllStringFunc(value, func(email string) string {
+		return "user_" + p.hash(email) + "@example.com"
+	})
+}
+
+func (p *pseudonymizer) hash(value string) string {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(strings.ToLower(value)))
+	return hex.EncodeToString(mac.Sum(nil))[:8]
+}
@@ -0,0 +1,30 @@
+package browserdata
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPseudonymizer(t *testing.T) {
+	p, err := newPseudonymizer()
+	require.NoError(t, err)
+
+	email := p.field("UserName", "Alice@Example.org")
+	assert.Regexp(t, `^user_[0-9a-f]{8}@example\.com$`, email)
+	assert.Equal(t, email, p.field("UserName", "alice@example.org"), "same value should map to the same pseudonym")
+	assert.NotEqual(t, email, p.field("UserName", "bob@example.org"))
+
+	assert.Regexp(t, `^user_[0-9a-f]{8}$`, p.field("UserName", "alice"))
+	assert.Equal(t, "", p.field("UserName", ""))
+
+	text := p.field("Title", "Inbox - alice@example.org")
+	assert.True(t, strings.HasPrefix(text, "Inbox - user_"))
+	assert.Equal(t, "https://github.com", p.field("LoginURL", "https://github.com"))
+
+	// the secrets are kept as they are
+	assert.Equal(t, "token for alice@example.org", p.field("Value", "token for alice@example.org"))
+	assert.Equal(t, "alice@example.org", p.field("Password", "alice@example.org"))
+}
@@ -0,0 +1,86 @@
+package pushsubscription
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func init() {
+	extractor.RegisterExtractor(types.ChromiumPushSubscription, extractor.Info{
+		Description: "the push subscriptions of the sites",
+		Sources:     []string{"GCM Store"},
+	}, func() extractor.Extractor {
+		return new(ChromiumPushSubscription)
+	})
+}
+
+// ChromiumPushSubscription is the web push subscriptions registered in the GCM Store,
+// every origin in it is able to send push messages to the user.
+type ChromiumPushSubscription []subscription
+
+type subscription struct {
+	Origin   string
+	AppID    string
+	Endpoint string
+}
+
+// @https://source.chromium.org/chromium/chromium/src/+/main:google_apis/gcm/engine/gcm_store_impl.cc
+const (
+	registrationKeyPrefix = "reg1-"
+	// webPushAppIDPrefix is the prefix of app ids created by push messaging, wp:<origin>#<guid>
+	webPushAppIDPrefix = "wp:"
+	// fcmEndpoint is the endpoint the registration id is appended to
+	fcmEndpoint = "https://fcm.googleapis.com/fcm/send/"
+)
+
+func (c *ChromiumPushSubscription) Extract(_ *extractor.Session) error {
+	db, err := leveldb.OpenFile(types.ChromiumPushSubscription.TempFilename(), &opt.Options{ReadOnly: types.InPlace()})
+	if err != nil {
+		return err
+	}
+	defer types.ChromiumPushSubscription.RemoveTemp()
+	defer db.Close()
+
+	iter := db.NewIterator(util.BytesPrefix([]byte(registrationKeyPrefix)), nil)
+	for iter.Next() {
+		if extractor.ReachedMaxRows(len(*c)) {
+			break
+		}
+		appID := strings.TrimPrefix(string(iter.Key()), registrationKeyPrefix)
+		// the value is <sender ids>=<registration id>
+		value := string(iter.Value())
+		token := value[strings.LastIndex(value, "=")+1:]
+		s := subscription{AppID: appID}
+		if token != "" {
+			s.Endpoint = fcmEndpoint + token
+		}
+		if strings.HasPrefix(appID, webPushAppIDPrefix) {
+			s.Origin, _, _ = strings.Cut(strings.TrimPrefix(appID, webPushAppIDPrefix), "#")
+		}
+		*c = append(*c, s)
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	sort.SliceStable(*c, func(i, j int) bool {
+		return (*c)[i].Origin < (*c)[j].Origin
+	})
+	return nil
+}
+
+func (c *ChromiumPushSubscription) Name() string {
+	return "pushSubscriptions"
+}
+
+func (c *ChromiumPushSubscription) Len() int {
+	return len(*c)
+}
@@ -0,0 +1,32 @@
+package pushsubscription
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func TestChromiumPushSubscription_Extract(t *testing.T) {
+	db, err := leveldb.OpenFile(types.ChromiumPushSubscription.TempFilename(), nil)
+	require.NoError(t, err)
+	require.NoError(t, db.Put([]byte("reg1-wp:https://example.com/#0b1c2d3e"), []byte("1234567890=dGVzdA:APA91b"), nil))
+	require.NoError(t, db.Put([]byte("reg1-com.google.chrome.fcm.invalidations"), []byte("8181035976="), nil))
+	require.NoError(t, db.Put([]byte("acc_info1-1"), []byte("ignored"), nil))
+	require.NoError(t, db.Close())
+
+	var c ChromiumPushSubscription
+	require.NoError(t, c.Extract(nil))
+	assert.Equal(t, ChromiumPushSubscription{
+		{AppID: "com.google.chrome.fcm.invalidations"},
+		{
+			Origin:   "https://example.com/",
+			AppID:    "wp:https://example.com/#0b1c2d3e",
+			Endpoint: "https://fcm.googleapis.com/fcm/send/dGVzdA:APA91b",
+		},
+	}, c)
+	assert.NoDirExists(t, types.ChromiumPushSubscription.TempFilename())
+}
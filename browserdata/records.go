@@ -0,0 +1,169 @@
+package browserdata
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+)
+
+// records returns a copy of the extracted data with the output transforms applied,
+// the extractor itself is left untouched, so it can be written more than once.
+// The base64 fields are encoded when encodeBase64 is true, it's only used by csv.
+func records(data extractor.Extractor, encodeBase64 bool) (any, error) {
+	return projectedRecords(data, encodeBase64, fields)
+}
+
+// itemRecords returns the records of a format with fixed fields, eg: the Set-Cookie headers,
+// they keep the type of the item. The selected fields must still be fields of the item.
+func itemRecords(data extractor.Extractor) (any, error) {
+	if err := checkFields(data, fields); err != nil {
+		return nil, err
+	}
+	return projectedRecords(data, false, nil)
+}
+
+// projectedRecords is records with only the named fields, nil names keep every field
+func projectedRecords(data extractor.Extractor, encodeBase64 bool, names []string) (any, error) {
+	if data == nil {
+		return nil, nil
+	}
+	v := reflect.Indirect(reflect.ValueOf(data))
+	if !v.IsValid() || v.Kind() != reflect.Slice {
+		return data, nil
+	}
+	out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				continue
+			}
+			c := reflect.New(elem.Elem().Type())
+			c.Elem().Set(elem.Elem())
+			transformFields(c.Elem(), encodeBase64)
+			out.Index(i).Set(c)
+			continue
+		}
+		out.Index(i).Set(elem)
+		transformFields(out.Index(i), encodeBase64)
+	}
+	if len(names) > 0 {
+		projected, err := project(out, names)
+		if err != nil {
+			return nil, err
+		}
+		out = reflect.ValueOf(projected)
+	}
+	if encodeBase64 && len(base64Fields) > 0 {
+		out = encodeBase64Fields(out)
+	}
+	return out.Interface(), nil
+}
+
+// transformFields applies the enabled transforms to every exported string field of the record,
+// the base64 fields keep their raw bytes when they are encoded.
+func transformFields(v reflect.Value, encodeBase64 bool) {
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if f.Kind() != reflect.String || !f.CanSet() {
+			continue
+		}
+		if pseudonym != nil {
+			f.SetString(pseudonym.field(t.Field(i).Name, f.String()))
+		}
+		if encodeBase64 && isBase64Field(t.Field(i).Name) {
+			continue
+		}
+		f.SetString(sanitizeUTF8(f.String()))
+	}
+}
+
+// fields are the names of the fields written to the output, empty means all fields
+var fields []string
+
+// SetFields selects the fields written to the output by a comma separated list,
+// eg: url,username,password, the names are case-insensitive.
+func SetFields(list string) {
+	fields = splitFields(list)
+}
+
+// splitFields returns the non-empty names of the comma separated list
+func splitFields(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// checkFields returns an error for the first name which isn't a field of the records of the item
+func checkFields(data extractor.Extractor, names []string) error {
+	if data == nil {
+		return nil
+	}
+	elemType, ok := recordType(reflect.Indirect(reflect.ValueOf(data)))
+	if !ok {
+		return nil
+	}
+	for _, name := range names {
+		if _, ok := fieldIndex(elemType, name); !ok {
+			return unknownField(elemType, name)
+		}
+	}
+	return nil
+}
+
+func unknownField(elemType reflect.Type, name string) error {
+	return fmt.Errorf("unknown field %s, available fields: %s", name, strings.Join(exportedFields(elemType), ","))
+}
+
+// project returns the records with only the named fields, in the order of names
+func project(rows reflect.Value, names []string) (any, error) {
+	elemType, ok := recordType(rows)
+	if !ok {
+		return rows.Interface(), nil
+	}
+	fields := make([]viewField, 0, len(names))
+	for _, name := range names {
+		i, ok := fieldIndex(elemType, name)
+		if !ok {
+			return nil, unknownField(elemType, name)
+		}
+		f := elemType.Field(i)
+		tag := f.Tag
+		// the fields hidden from the csv are written when they are requested
+		if tag.Get("csv") == "-" {
+			tag = ""
+		}
+		fields = append(fields, viewField{StructField: reflect.StructField{Name: f.Name, Type: f.Type, Tag: tag}, value: copyField(i)})
+	}
+	return makeView(rows, fields).Interface(), nil
+}
+
+// fieldIndex returns the index of the exported field matching name case-insensitively
+func fieldIndex(t reflect.Type, name string) (int, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.IsExported() && strings.EqualFold(f.Name, name) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func exportedFields(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.IsExported() {
+			names = append(names, strings.ToLower(f.Name))
+		}
+	}
+	return names
+}
@@ -0,0 +1,54 @@
+package browserdata
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutPutter_WriteFields(t *testing.T) {
+	SetFields(" value, HOST ,")
+	defer SetFields("")
+	c := newTestCookies(t, "secret")
+
+	var buf bytes.Buffer
+	require.NoError(t, newOutPutter("json").Write(c, &buf))
+	var got []map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, []map[string]any{{"Value": "secret", "Host": "example.com"}}, got)
+
+	buf.Reset()
+	require.NoError(t, newOutPutter("csv").Write(c, &buf))
+	assert.Equal(t, "\ufeffValue,Host\nsecret,example.com\n", buf.String())
+}
+
+func TestOutPutter_WriteUnknownField(t *testing.T) {
+	SetFields("host,password")
+	defer SetFields("")
+
+	var buf bytes.Buffer
+	err := newOutPutter("json").Write(newTestCookies(t, "secret"), &buf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown field password")
+	assert.Contains(t, err.Error(), "host,path,keyname,value")
+	assert.Zero(t, buf.Len())
+}
+
+func TestOutPutter_WriteHiddenField(t *testing.T) {
+	c := newTestCookies(t, "secret")
+	reflect.ValueOf(c).Elem().Index(0).FieldByName("DecryptMethod").SetString("AES-GCM-v10")
+
+	var buf bytes.Buffer
+	require.NoError(t, newOutPutter("csv").Write(c, &buf))
+	assert.NotContains(t, buf.String(), "DecryptMethod")
+
+	SetFields("host,decryptmethod")
+	defer SetFields("")
+	buf.Reset()
+	require.NoError(t, newOutPutter("csv").Write(c, &buf))
+	assert.Equal(t, "\ufeffHost,DecryptMethod\nexample.com,AES-GCM-v10\n", buf.String())
+}
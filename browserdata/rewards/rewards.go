@@ -0,0 +1,92 @@
+package rewards
+
+import (
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/fileutil"
+)
+
+func init() {
+	extractor.RegisterExtractor(types.BraveRewards, extractor.Info{
+		Description: "the public state of the Brave Rewards wallet",
+		Sources:     []string{"Preferences"},
+	}, func() extractor.Extractor {
+		return new(BraveRewards)
+	})
+}
+
+// BraveRewards is the public state of the Brave Rewards wallet and Brave Ads of the profile,
+// Brave keeps it in brave.rewards and brave.brave_ads of Preferences. The recovery seed of
+// the wallet is never read, the profiles which never enabled Rewards have no record.
+type BraveRewards []rewards
+
+type rewards struct {
+	Enabled        bool
+	PaymentID      string
+	WalletType     string
+	DeclaredGeo    string
+	AutoContribute bool
+	CreateDate     time.Time
+	AdsEnabled     bool
+	AdsPerHour     int64
+}
+
+// @https://github.com/brave/brave-core/blob/master/components/brave_rewards/common/pref_names.cc
+// @https://github.com/brave/brave-core/blob/master/components/brave_ads/core/public/prefs/pref_names.h
+const (
+	rewardsPath        = "brave.rewards"
+	enabledPath        = "brave.rewards.enabled"
+	walletPath         = "brave.rewards.wallet"
+	walletTypePath     = "brave.rewards.external_wallet_type"
+	declaredGeoPath    = "brave.rewards.declared_geo"
+	autoContributePath = "brave.rewards.ac.enabled"
+	creationStampPath  = "brave.rewards.creation_stamp"
+	adsEnabledPath     = "brave.brave_ads.enabled"
+	adsOptedInPath     = "brave.brave_ads.opted_in_to_notification_ads"
+	adsPerHourPath     = "brave.brave_ads.ads_per_hour"
+)
+
+func (c *BraveRewards) Extract(_ *extractor.Session) error {
+	s, err := fileutil.ReadFile(types.BraveRewards.TempFilename())
+	if err != nil {
+		return err
+	}
+	defer types.BraveRewards.RemoveTemp()
+
+	if !gjson.Get(s, rewardsPath).Exists() {
+		// plain chromium or a brave profile which never opened Rewards
+		return nil
+	}
+	// the wallet is a json string, its recovery_seed is encrypted and left out
+	wallet := gjson.Parse(gjson.Get(s, walletPath).String())
+	r := rewards{
+		Enabled:        gjson.Get(s, enabledPath).Bool(),
+		PaymentID:      wallet.Get("payment_id").String(),
+		WalletType:     gjson.Get(s, walletTypePath).String(),
+		DeclaredGeo:    gjson.Get(s, declaredGeoPath).String(),
+		AutoContribute: gjson.Get(s, autoContributePath).Bool(),
+		AdsEnabled:     gjson.Get(s, adsEnabledPath).Bool() || gjson.Get(s, adsOptedInPath).Bool(),
+		AdsPerHour:     gjson.Get(s, adsPerHourPath).Int(),
+	}
+	// the creation stamp is the seconds since the unix epoch as a string, 0 if the wallet isn't created
+	if stamp := gjson.Get(s, creationStampPath).Int(); stamp > 0 {
+		r.CreateDate = time.Unix(stamp, 0)
+	}
+	if !r.Enabled && r.PaymentID == "" {
+		return nil
+	}
+	*c = append(*c, r)
+	return nil
+}
+
+func (c *BraveRewards) Name() string {
+	return "braveRewards"
+}
+
+func (c *BraveRewards) Len() int {
+	return len(*c)
+}
@@ -0,0 +1,50 @@
+package rewards
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func extract(t *testing.T, preferences string) BraveRewards {
+	t.Helper()
+	require.NoError(t, os.WriteFile(types.BraveRewards.TempFilename(), []byte(preferences), 0o600))
+	var c BraveRewards
+	require.NoError(t, c.Extract(nil))
+	assert.NoFileExists(t, types.BraveRewards.TempFilename())
+	return c
+}
+
+func TestBraveRewards_Extract(t *testing.T) {
+	c := extract(t, `{"brave": {
+		"rewards": {
+			"enabled": true,
+			"wallet": "{\"payment_id\":\"3f2a-41\",\"recovery_seed\":\"djEwc2VjcmV0\"}",
+			"external_wallet_type": "uphold",
+			"declared_geo": "US",
+			"ac": {"enabled": false},
+			"creation_stamp": "1700000000"
+		},
+		"brave_ads": {"opted_in_to_notification_ads": true, "ads_per_hour": "5"}
+	}}`)
+	require.Len(t, c, 1)
+	assert.Equal(t, rewards{
+		Enabled:     true,
+		PaymentID:   "3f2a-41",
+		WalletType:  "uphold",
+		DeclaredGeo: "US",
+		CreateDate:  time.Unix(1700000000, 0),
+		AdsEnabled:  true,
+		AdsPerHour:  5,
+	}, c[0])
+}
+
+func TestBraveRewards_ExtractDisabled(t *testing.T) {
+	assert.Empty(t, extract(t, `{"profile": {"name": "Person 1"}}`), "chromium has no rewards")
+	assert.Empty(t, extract(t, `{"brave": {"rewards": {"enabled": false}}}`), "rewards was never enabled")
+}
@@ -0,0 +1,153 @@
+package safebrowsing
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	// import sqlite3 driver
+	_ "modernc.org/sqlite"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/log"
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/sqliteutil"
+	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
+)
+
+func init() {
+	extractor.RegisterExtractor(types.ChromiumSafeBrowsing, extractor.Info{
+		Description: "the downloads flagged by Safe Browsing",
+		Sources:     []string{"History"},
+	}, func() extractor.Extractor {
+		return new(ChromiumSafeBrowsing)
+	})
+}
+
+// ChromiumSafeBrowsing is the downloads flagged by Safe Browsing or the enterprise scans, and
+// whether the user kept them despite the warning. History keeps the verdict of every download
+// in the danger_type of the downloads table, the hash prefix stores of Safe Browsing have no urls.
+type ChromiumSafeBrowsing []flaggedDownload
+
+type flaggedDownload struct {
+	URL        string
+	TargetPath string
+	Verdict    string
+	// Overridden reports whether the user kept or opened the download despite the warning
+	Overridden bool
+	State      string
+	StartTime  time.Time
+	EndTime    time.Time
+}
+
+// @https://source.chromium.org/chromium/chromium/src/+/main:components/history/core/browser/download_database.cc
+const (
+	queryChromiumFlaggedDownload = `SELECT d.target_path, d.tab_url, d.danger_type, d.state, d.start_time, d.end_time, %s
+		FROM downloads d WHERE d.danger_type != 0`
+	// chromiumFinalURL is the last url of the redirect chain of the download
+	chromiumFinalURL     = `COALESCE((SELECT c.url FROM downloads_url_chains c WHERE c.id = d.id ORDER BY c.chain_index DESC LIMIT 1), '')`
+	chromiumDangerColumn = "danger_type"
+)
+
+// chromiumVerdicts are the danger types of History, 0 is not dangerous
+// @https://source.chromium.org/chromium/chromium/src/+/main:components/download/public/common/download_danger_type.h
+var chromiumVerdicts = map[int]string{
+	1:  "dangerous file",
+	2:  "dangerous url",
+	3:  "dangerous content",
+	4:  "maybe dangerous content",
+	5:  "uncommon content",
+	6:  "user validated",
+	7:  "dangerous host",
+	8:  "potentially unwanted",
+	9:  "allowlisted by policy",
+	10: "async scanning",
+	11: "blocked password protected",
+	12: "blocked too large",
+	13: "sensitive content warning",
+	14: "sensitive content block",
+	15: "deep scanned safe",
+	16: "deep scanned opened dangerous",
+	17: "prompt for scanning",
+	18: "blocked unsupported filetype",
+	19: "dangerous account compromise",
+	20: "deep scanned failed",
+	21: "prompt for local password scanning",
+	22: "async local password scanning",
+	23: "blocked scan failed",
+}
+
+// chromiumOverrides are the danger types of the downloads the user kept or opened after the warning
+var chromiumOverrides = map[int]bool{6: true, 16: true}
+
+// chromiumStates are the states of the downloads, 3 is an obsolete value of interrupted
+var chromiumStates = map[int]string{0: "in progress", 1: "complete", 2: "cancelled", 3: "interrupted", 4: "interrupted"}
+
+func (c *ChromiumSafeBrowsing) Extract(_ *extractor.Session) error {
+	db, err := sql.Open("sqlite", types.ChromiumSafeBrowsing.DSN())
+	if err != nil {
+		return err
+	}
+	defer types.ChromiumSafeBrowsing.RemoveTemp()
+	defer db.Close()
+
+	// the downloads table is created on the first download
+	if ok, err := sqliteutil.ColumnExists(db, "downloads", chromiumDangerColumn); err != nil || !ok {
+		log.Debugf("chromium downloads have no danger type, skip safe browsing")
+		return nil
+	}
+	finalURL := "''"
+	if ok, err := sqliteutil.TableExists(db, "downloads_url_chains"); err == nil && ok {
+		finalURL = chromiumFinalURL
+	}
+	rows, err := db.Query(extractor.LimitQuery(fmt.Sprintf(queryChromiumFlaggedDownload, finalURL)))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			targetPath, tabURL, url string
+			danger, state           int
+			startTime, endTime      int64
+		)
+		if err := rows.Scan(&targetPath, &tabURL, &danger, &state, &startTime, &endTime, &url); err != nil {
+			log.Warnf("scan chromium flagged download error: %v", err)
+			continue
+		}
+		if url == "" {
+			url = tabURL
+		}
+		*c = append(*c, flaggedDownload{
+			URL:        url,
+			TargetPath: targetPath,
+			Verdict:    verdict(danger),
+			Overridden: chromiumOverrides[danger],
+			State:      chromiumStates[state],
+			StartTime:  typeutil.TimeEpoch(startTime),
+			EndTime:    typeutil.TimeEpoch(endTime),
+		})
+	}
+	sort.SliceStable(*c, func(i, j int) bool {
+		return (*c)[i].StartTime.After((*c)[j].StartTime)
+	})
+	return rows.Err()
+}
+
+// verdict returns the name of the danger type, the number for the types added since
+func verdict(danger int) string {
+	if v, ok := chromiumVerdicts[danger]; ok {
+		return v
+	}
+	return strconv.Itoa(danger)
+}
+
+func (c *ChromiumSafeBrowsing) Name() string {
+	return "safeBrowsing"
+}
+
+func (c *ChromiumSafeBrowsing) Len() int {
+	return len(*c)
+}
@@ -0,0 +1,46 @@
+package safebrowsing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/testutil"
+)
+
+func TestChromiumSafeBrowsing_Extract(t *testing.T) {
+	testutil.CreateSQLite(t, types.ChromiumSafeBrowsing,
+		`CREATE TABLE downloads (id INTEGER PRIMARY KEY, target_path LONGVARCHAR NOT NULL, tab_url VARCHAR NOT NULL,
+			danger_type INTEGER NOT NULL, state INTEGER NOT NULL, start_time INTEGER NOT NULL, end_time INTEGER NOT NULL)`,
+		`CREATE TABLE downloads_url_chains (id INTEGER NOT NULL, chain_index INTEGER NOT NULL, url LONGVARCHAR NOT NULL)`,
+		`INSERT INTO downloads VALUES (1, '/tmp/safe.zip', 'https://example.com/', 0, 1, 13300000000000000, 13300000001000000),
+			(2, '/tmp/kept.exe', 'https://bad.test/', 6, 1, 13300000002000000, 13300000003000000),
+			(3, '/tmp/blocked.exe', 'https://bad.test/', 1, 2, 13300000001000000, 0),
+			(4, '/tmp/new.exe', 'https://bad.test/', 99, 4, 13200000000000000, 0)`,
+		`INSERT INTO downloads_url_chains VALUES (2, 0, 'https://bad.test/kept'), (2, 1, 'https://cdn.bad.test/kept.exe')`,
+	)
+
+	var c ChromiumSafeBrowsing
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 3)
+	assert.Equal(t, "https://cdn.bad.test/kept.exe", c[0].URL)
+	assert.Equal(t, "user validated", c[0].Verdict)
+	assert.True(t, c[0].Overridden)
+	assert.Equal(t, "complete", c[0].State)
+	assert.Equal(t, "https://bad.test/", c[1].URL)
+	assert.Equal(t, "dangerous file", c[1].Verdict)
+	assert.False(t, c[1].Overridden)
+	assert.Equal(t, "cancelled", c[1].State)
+	assert.Equal(t, "99", c[2].Verdict)
+	assert.NoFileExists(t, types.ChromiumSafeBrowsing.TempFilename())
+}
+
+func TestChromiumSafeBrowsing_ExtractNoDownloads(t *testing.T) {
+	testutil.CreateSQLite(t, types.ChromiumSafeBrowsing, `CREATE TABLE urls (id INTEGER PRIMARY KEY)`)
+
+	var c ChromiumSafeBrowsing
+	require.NoError(t, c.Extract(nil))
+	assert.Empty(t, c)
+}
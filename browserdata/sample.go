@@ -0,0 +1,88 @@
+package browserdata
+
+import (
+	"reflect"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+// sampleItems are the items decrypted by --sample-decrypt, the other items are skipped
+var sampleItems = map[types.DataType]bool{
+	types.ChromiumPassword: true,
+	types.ChromiumCookie:   true,
+	types.YandexPassword:   true,
+	types.FirefoxPassword:  true,
+}
+
+// sampleDecrypt keeps the sample items only, see SetSampleDecrypt
+var sampleDecrypt bool
+
+// SetSampleDecrypt extracts the passwords and cookies only, the caller limits them to the
+// first record with extractor.SetMaxRows and reports the Samples instead of the output.
+// The records without an encrypted value are skipped, so the first one can be decrypted.
+func SetSampleDecrypt(b bool) {
+	sampleDecrypt = b
+	extractor.SetSampleEncrypted(b)
+}
+
+// Sample is the result of decrypting the first record of an item, the value itself is left out
+type Sample struct {
+	Item string `json:"item"`
+	// Method is the decryption method of the record, eg: AES-GCM-v10
+	Method    string `json:"method,omitempty"`
+	KeySource string `json:"key_source,omitempty"`
+	OK        bool   `json:"ok"`
+	Error     string `json:"error,omitempty"`
+}
+
+// SetKeySource sets where the master key of the profile was read from, eg: keychain
+func (d *BrowserData) SetKeySource(source string) {
+	d.keySource = source
+}
+
+// SetKeyError sets the error of reading the master key, the items are extracted without it
+func (d *BrowserData) SetKeyError(err error) {
+	d.keyErr = err
+}
+
+// Samples returns the decryption result of every item in the order of Recovery
+func (d *BrowserData) Samples() []Sample {
+	stats := d.Stats().Items
+	samples := make([]Sample, 0, len(stats))
+	for i, dt := range types.SortedKeys(d.extractors) {
+		item := stats[i]
+		s := Sample{Item: item.Name, Method: firstMethod(d.extractors[dt]), KeySource: d.keySource, Error: item.Error}
+		switch {
+		case item.Error != "":
+		case d.keyErr != nil && (item.Decrypted == 0 || item.DecryptFailed > 0):
+			s.Error = d.keyErr.Error()
+		case item.Records == 0:
+			s.Error = "no record"
+		case item.DecryptFailed > 0:
+			s.Error = "decryption failed"
+		case item.Decrypted == 0:
+			s.Error = "the record has no encrypted value"
+		default:
+			s.OK = true
+		}
+		samples = append(samples, s)
+	}
+	return samples
+}
+
+// firstMethod returns the DecryptMethod of the first record of the item, empty if it has none
+func firstMethod(data any) string {
+	v := reflect.Indirect(reflect.ValueOf(data))
+	if v.Kind() != reflect.Slice || v.Len() == 0 {
+		return ""
+	}
+	row := reflect.Indirect(v.Index(0))
+	if row.Kind() != reflect.Struct {
+		return ""
+	}
+	if f := row.FieldByName("DecryptMethod"); f.IsValid() && f.Kind() == reflect.String {
+		return f.String()
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package browserdata
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func TestBrowserData_Samples(t *testing.T) {
+	bd := &BrowserData{
+		extractors: map[types.DataType]extractor.Extractor{
+			types.ChromiumPassword: &fakeExtractor{name: "password", decrypted: 1},
+			types.ChromiumCookie:   &fakeExtractor{name: "cookie", failed: 1},
+			types.FirefoxPassword:  &fakeExtractor{name: "password", err: errors.New("database is locked")},
+		},
+		errors: make(map[types.DataType]error),
+	}
+	bd.SetKeySource("keychain")
+	require.NoError(t, bd.Recovery(nil))
+
+	assert.Equal(t, []Sample{
+		{Item: "password", KeySource: "keychain", OK: true},
+		{Item: "cookie", KeySource: "keychain", Error: "decryption failed"},
+		{Item: "password", KeySource: "keychain", Error: "database is locked"},
+	}, bd.Samples())
+
+	bd.SetKeyError(errors.New("keychain access denied"))
+	assert.Equal(t, "keychain access denied", bd.Samples()[1].Error)
+	assert.True(t, bd.Samples()[0].OK)
+}
+
+func TestSetSampleDecrypt(t *testing.T) {
+	SetSampleDecrypt(true)
+	defer SetSampleDecrypt(false)
+	bd := New([]types.DataType{types.ChromiumPassword, types.ChromiumHistory, types.ChromiumCookie})
+	assert.Equal(t, []types.DataType{types.ChromiumPassword, types.ChromiumCookie}, types.SortedKeys(bd.extractors))
+	// the sampled rows have an encrypted value
+	assert.Equal(t, "SELECT 1 WHERE length(v) > 0", extractor.FilterEncrypted("SELECT 1", "v"))
+}
+
+func TestFirstMethod(t *testing.T) {
+	type record struct{ DecryptMethod string }
+	assert.Equal(t, "AES-GCM-v10", firstMethod(&[]record{{DecryptMethod: "AES-GCM-v10"}, {DecryptMethod: "DPAPI"}}))
+	assert.Equal(t, "DPAPI", firstMethod(&[]*record{{DecryptMethod: "DPAPI"}}))
+	assert.Equal(t, "", firstMethod(&[]record{}))
+	assert.Equal(t, "", firstMethod(&[]struct{ URL string }{{URL: "https://example.com"}}))
+}
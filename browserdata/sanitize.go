@@ -0,0 +1,52 @@
+package browserdata
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// modes of handling invalid UTF-8 in the exported values, a failed decryption
+// leaves raw bytes in the value, which breaks the JSON and CSV output.
+const (
+	// InvalidUTF8Replace replaces the invalid bytes with U+FFFD
+	InvalidUTF8Replace = "replace"
+	// InvalidUTF8Hex escapes every invalid byte as \xNN, so the raw bytes can be recovered
+	InvalidUTF8Hex = "hex"
+)
+
+var invalidUTF8Mode = InvalidUTF8Replace
+
+// SetInvalidUTF8 sets how invalid UTF-8 in the values is written, empty is InvalidUTF8Replace
+func SetInvalidUTF8(mode string) error {
+	switch mode {
+	case "":
+		mode = InvalidUTF8Replace
+	case InvalidUTF8Replace, InvalidUTF8Hex:
+	default:
+		return fmt.Errorf("unknown invalid utf8 mode %s, available modes: %s|%s", mode, InvalidUTF8Replace, InvalidUTF8Hex)
+	}
+	invalidUTF8Mode = mode
+	return nil
+}
+
+// sanitizeUTF8 returns the value with the invalid UTF-8 handled by the mode of SetInvalidUTF8
+func sanitizeUTF8(value string) string {
+	if utf8.ValidString(value) {
+		return value
+	}
+	if invalidUTF8Mode == InvalidUTF8Replace {
+		return strings.ToValidUTF8(value, string(utf8.RuneError))
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); {
+		r, size := utf8.DecodeRuneInString(value[i:])
+		if r == utf8.RuneError && size == 1 {
+			fmt.Fprintf(&b, `\x%02x`, value[i])
+		} else {
+			b.WriteString(value[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package browserdata
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/browserdata/cookie"
+)
+
+// newTestCookies returns cookies with the values, the cookie struct is unexported
+func newTestCookies(t *testing.T, values ...string) *cookie.ChromiumCookie {
+	t.Helper()
+	var c cookie.ChromiumCookie
+	v := reflect.ValueOf(&c).Elem()
+	for _, value := range values {
+		e := reflect.New(v.Type().Elem()).Elem()
+		e.FieldByName("Host").SetString("example.com")
+		e.FieldByName("Value").SetString(value)
+		v.Set(reflect.Append(v, e))
+	}
+	return &c
+}
+
+func TestOutPutter_WriteInvalidUTF8(t *testing.T) {
+	defer func() { _ = SetInvalidUTF8("") }()
+	raw := "v10\x8f\xff\x00ok"
+
+	testCases := []struct {
+		mode, flag, expected string
+	}{
+		{InvalidUTF8Replace, "json", "v10�\x00ok"},
+		{InvalidUTF8Hex, "json", `v10\x8f\xff` + "\x00ok"},
+		{InvalidUTF8Replace, "csv", "v10�"},
+		{InvalidUTF8Hex, "csv", `v10\x8f\xff`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.mode+"_"+tc.flag, func(t *testing.T) {
+			require.NoError(t, SetInvalidUTF8(tc.mode))
+			c := newTestCookies(t, raw)
+
+			var buf bytes.Buffer
+			require.NoError(t, newOutPutter(tc.flag).Write(c, &buf))
+			assert.True(t, utf8.Valid(buf.Bytes()))
+			if tc.flag == "json" {
+				require.True(t, json.Valid(buf.Bytes()))
+				var got []map[string]any
+				require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+				assert.Equal(t, tc.expected, got[0]["Value"])
+			} else {
+				assert.Contains(t, buf.String(), tc.expected)
+			}
+			assert.Equal(t, raw, reflect.ValueOf(*c).Index(0).FieldByName("Value").String(), "the extracted data is left untouched")
+		})
+	}
+}
+
+func TestSetInvalidUTF8(t *testing.T) {
+	assert.Error(t, SetInvalidUTF8("drop"))
+	assert.NoError(t, SetInvalidUTF8(""))
+	assert.Equal(t, InvalidUTF8Replace, invalidUTF8Mode)
+}
@@ -0,0 +1,111 @@
+package sessions
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
+)
+
+func init() {
+	extractor.RegisterExtractor(types.ChromiumSessions, extractor.Info{
+		Description: "the tabs open in the current session",
+		Sources:     []string{"Sessions"},
+	}, func() extractor.Extractor {
+		return new(ChromiumSessions)
+	})
+}
+
+// ChromiumSessions is the tabs open in the current session, it's read from the
+// newest Session_* file in the Sessions folder.
+type ChromiumSessions []session
+
+type session struct {
+	WindowID int
+	TabIndex int
+	URL      string
+	Title    string
+}
+
+const sessionFilePrefix = "Session_"
+
+func (c *ChromiumSessions) Extract(_ *extractor.Session) error {
+	dir := types.ChromiumSessions.TempFilename()
+	defer types.ChromiumSessions.RemoveTemp()
+
+	filename, err := latestSessionFile(dir)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	commands, err := readSNSSCommands(data)
+	if err != nil {
+		return err
+	}
+	tabs := replaySNSS(commands)
+	ids := typeutil.Keys(tabs)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		t := tabs[id]
+		if t.closed {
+			continue
+		}
+		nav, ok := t.current()
+		if !ok {
+			continue
+		}
+		if extractor.ReachedMaxRows(len(*c)) {
+			break
+		}
+		*c = append(*c, session{
+			WindowID: int(t.window),
+			TabIndex: int(t.index),
+			URL:      nav.url,
+			Title:    nav.title,
+		})
+	}
+	sort.Slice(*c, func(i, j int) bool {
+		if (*c)[i].WindowID != (*c)[j].WindowID {
+			return (*c)[i].WindowID < (*c)[j].WindowID
+		}
+		return (*c)[i].TabIndex < (*c)[j].TabIndex
+	})
+	return nil
+}
+
+// latestSessionFile returns the newest Session_<timestamp> file in dir,
+// the timestamps have the same length, so they can be compared as strings.
+func latestSessionFile(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var latest string
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), sessionFilePrefix) {
+			continue
+		}
+		if e.Name() > latest {
+			latest = e.Name()
+		}
+	}
+	if latest == "" {
+		return "", os.ErrNotExist
+	}
+	return filepath.Join(dir, latest), nil
+}
+
+func (c *ChromiumSessions) Name() string {
+	return "sessions"
+}
+
+func (c *ChromiumSessions) Len() int {
+	return len(*c)
+}
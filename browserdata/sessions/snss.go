@@ -0,0 +1,246 @@
+package sessions
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf16"
+)
+
+// SNSS is the command log Chromium writes the open windows and tabs to,
+// every command is a uint16 size, a uint8 id and the payload of size-1 bytes.
+// @https://source.chromium.org/chromium/chromium/src/+/main:components/sessions/core/command_storage_backend.cc
+const snssMagic = "SNSS"
+
+// file versions of the command storage backend, the encrypted versions are not supported
+const (
+	snssVersion           = 1
+	snssVersionWithMarker = 3
+)
+
+// command ids of the session service, only the commands needed for the open tabs are handled
+// @https://source.chromium.org/chromium/chromium/src/+/main:components/sessions/core/session_service_commands.cc
+const (
+	commandSetTabWindow               = 0
+	commandSetTabIndexInWindow        = 2
+	commandUpdateTabNavigation        = 6
+	commandSetSelectedNavigationIndex = 7
+	commandTabClosed                  = 16
+	commandWindowClosed               = 17
+)
+
+var (
+	errInvalidSNSS     = errors.New("invalid snss file")
+	errUnsupportedSNSS = errors.New("unsupported snss version")
+)
+
+type snssCommand struct {
+	id      uint8
+	payload []byte
+}
+
+// readSNSSCommands reads the header and all commands of the SNSS file,
+// a truncated last command is ignored as Chromium may be writing it.
+func readSNSSCommands(data []byte) ([]snssCommand, error) {
+	if len(data) < 8 || string(data[:4]) != snssMagic {
+		return nil, errInvalidSNSS
+	}
+	switch version := binary.LittleEndian.Uint32(data[4:8]); version {
+	case snssVersion, snssVersionWithMarker:
+	default:
+		return nil, fmt.Errorf("%w: %d", errUnsupportedSNSS, version)
+	}
+	var commands []snssCommand
+	r := bytes.NewReader(data[8:])
+	for {
+		var size uint16
+		if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+			break
+		}
+		if size == 0 {
+			continue
+		}
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			break
+		}
+		commands = append(commands, snssCommand{id: buf[0], payload: buf[1:]})
+	}
+	return commands, nil
+}
+
+// pickle reads the payload written by base::Pickle, it starts with an uint32 payload size
+// and every field is aligned to 4 bytes.
+type pickle struct {
+	data   []byte
+	offset int
+}
+
+func newPickle(data []byte) (*pickle, error) {
+	if len(data) < 4 {
+		return nil, errInvalidSNSS
+	}
+	size := int(binary.LittleEndian.Uint32(data))
+	if size > len(data)-4 {
+		return nil, errInvalidSNSS
+	}
+	return &pickle{data: data[4 : 4+size]}, nil
+}
+
+func (p *pickle) read(n int) ([]byte, error) {
+	if n < 0 || p.offset+n > len(p.data) {
+		return nil, errInvalidSNSS
+	}
+	b := p.data[p.offset : p.offset+n]
+	p.offset += (n + 3) &^ 3
+	return b, nil
+}
+
+func (p *pickle) int32() (int32, error) {
+	b, err := p.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.LittleEndian.Uint32(b)), nil
+}
+
+func (p *pickle) string() (string, error) {
+	n, err := p.int32()
+	if err != nil {
+		return "", err
+	}
+	b, err := p.read(int(n))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (p *pickle) string16() (string, error) {
+	n, err := p.int32()
+	if err != nil {
+		return "", err
+	}
+	b, err := p.read(int(n) * 2)
+	if err != nil {
+		return "", err
+	}
+	u := make([]uint16, n)
+	for i := range u {
+		u[i] = binary.LittleEndian.Uint16(b[i*2:])
+	}
+	return string(utf16.Decode(u)), nil
+}
+
+type navigation struct {
+	url   string
+	title string
+}
+
+type tab struct {
+	id       int32
+	window   int32
+	index    int32
+	selected int32
+	navs     map[int32]navigation
+	closed   bool
+}
+
+// current returns the selected navigation of the tab, or the last one if none is selected
+func (t *tab) current() (navigation, bool) {
+	if nav, ok := t.navs[t.selected]; ok {
+		return nav, true
+	}
+	last, found := int32(-1), false
+	for i := range t.navs {
+		if i > last {
+			last, found = i, true
+		}
+	}
+	return t.navs[last], found
+}
+
+// replaySNSS applies the commands in order and returns the tabs by id
+func replaySNSS(commands []snssCommand) map[int32]*tab {
+	tabs := make(map[int32]*tab)
+	closedWindows := make(map[int32]bool)
+	getTab := func(id int32) *tab {
+		t, ok := tabs[id]
+		if !ok {
+			t = &tab{id: id, selected: -1, navs: make(map[int32]navigation)}
+			tabs[id] = t
+		}
+		return t
+	}
+	for _, c := range commands {
+		switch c.id {
+		case commandSetTabWindow:
+			if window, id, ok := idPair(c.payload); ok {
+				getTab(id).window = window
+			}
+		case commandSetTabIndexInWindow:
+			if id, index, ok := idPair(c.payload); ok {
+				getTab(id).index = index
+			}
+		case commandSetSelectedNavigationIndex:
+			if id, index, ok := idPair(c.payload); ok {
+				getTab(id).selected = index
+			}
+		case commandUpdateTabNavigation:
+			id, index, nav, err := readNavigation(c.payload)
+			if err != nil {
+				continue
+			}
+			getTab(id).navs[index] = nav
+		case commandTabClosed:
+			if len(c.payload) >= 4 {
+				getTab(int32(binary.LittleEndian.Uint32(c.payload))).closed = true
+			}
+		case commandWindowClosed:
+			if len(c.payload) >= 4 {
+				closedWindows[int32(binary.LittleEndian.Uint32(c.payload))] = true
+			}
+		}
+	}
+	for _, t := range tabs {
+		if closedWindows[t.window] {
+			t.closed = true
+		}
+	}
+	return tabs
+}
+
+// idPair reads the two int32 payload used by most of the commands
+func idPair(payload []byte) (int32, int32, bool) {
+	if len(payload) < 8 {
+		return 0, 0, false
+	}
+	return int32(binary.LittleEndian.Uint32(payload)), int32(binary.LittleEndian.Uint32(payload[4:])), true
+}
+
+// readNavigation reads the tab id, navigation index, url and title of commandUpdateTabNavigation
+// @https://source.chromium.org/chromium/chromium/src/+/main:components/sessions/core/serialized_navigation_entry.cc
+func readNavigation(payload []byte) (int32, int32, navigation, error) {
+	var nav navigation
+	p, err := newPickle(payload)
+	if err != nil {
+		return 0, 0, nav, err
+	}
+	id, err := p.int32()
+	if err != nil {
+		return 0, 0, nav, err
+	}
+	index, err := p.int32()
+	if err != nil {
+		return 0, 0, nav, err
+	}
+	if nav.url, err = p.string(); err != nil {
+		return 0, 0, nav, err
+	}
+	if nav.title, err = p.string16(); err != nil {
+		return 0, 0, nav, err
+	}
+	return id, index, nav, nil
+}
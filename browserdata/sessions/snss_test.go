@@ -0,0 +1,101 @@
+package sessions
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+type snssWriter struct {
+	buf []byte
+}
+
+func newSNSSWriter() *snssWriter {
+	w := &snssWriter{buf: []byte(snssMagic)}
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, snssVersionWithMarker)
+	return w
+}
+
+func (w *snssWriter) command(id uint8, payload []byte) {
+	w.buf = binary.LittleEndian.AppendUint16(w.buf, uint16(len(payload)+1))
+	w.buf = append(w.buf, id)
+	w.buf = append(w.buf, payload...)
+}
+
+func (w *snssWriter) idPair(id uint8, a, b int32) {
+	var payload []byte
+	payload = binary.LittleEndian.AppendUint32(payload, uint32(a))
+	payload = binary.LittleEndian.AppendUint32(payload, uint32(b))
+	w.command(id, payload)
+}
+
+func align(b []byte) []byte {
+	for len(b)%4 != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func (w *snssWriter) navigation(tabID, index int32, url, title string) {
+	var p []byte
+	p = binary.LittleEndian.AppendUint32(p, uint32(tabID))
+	p = binary.LittleEndian.AppendUint32(p, uint32(index))
+	p = binary.LittleEndian.AppendUint32(p, uint32(len(url)))
+	p = align(append(p, url...))
+	u := utf16.Encode([]rune(title))
+	p = binary.LittleEndian.AppendUint32(p, uint32(len(u)))
+	for _, c := range u {
+		p = binary.LittleEndian.AppendUint16(p, c)
+	}
+	p = align(p)
+	// trailing fields of the navigation entry are ignored
+	p = binary.LittleEndian.AppendUint32(p, 0)
+	w.command(commandUpdateTabNavigation, append(binary.LittleEndian.AppendUint32(nil, uint32(len(p))), p...))
+}
+
+func TestChromiumSessions_Extract(t *testing.T) {
+	w := newSNSSWriter()
+	w.idPair(commandSetTabWindow, 1, 10)
+	w.idPair(commandSetTabIndexInWindow, 10, 1)
+	w.navigation(10, 0, "https://example.com/", "Example")
+	w.navigation(10, 1, "https://example.com/next", "Next – page")
+	w.idPair(commandSetSelectedNavigationIndex, 10, 1)
+
+	w.idPair(commandSetTabWindow, 1, 11)
+	w.idPair(commandSetTabIndexInWindow, 11, 0)
+	w.navigation(11, 0, "https://github.com/", "GitHub")
+
+	w.idPair(commandSetTabWindow, 1, 12)
+	w.navigation(12, 0, "https://closed.example/", "Closed")
+	w.idPair(commandTabClosed, 12, 0)
+	// a truncated command at the end is ignored
+	w.buf = append(w.buf, 0x10, 0x00, commandUpdateTabNavigation)
+
+	dir := types.ChromiumSessions.TempFilename()
+	require.NoError(t, os.MkdirAll(dir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Session_13300000000000000"), []byte("SNSS"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Session_13300000000000001"), w.buf, 0o600))
+
+	var c ChromiumSessions
+	require.NoError(t, c.Extract(nil))
+	assert.Equal(t, ChromiumSessions{
+		{WindowID: 1, TabIndex: 0, URL: "https://github.com/", Title: "GitHub"},
+		{WindowID: 1, TabIndex: 1, URL: "https://example.com/next", Title: "Next – page"},
+	}, c)
+	assert.NoDirExists(t, dir)
+}
+
+func TestReadSNSSCommands_Invalid(t *testing.T) {
+	_, err := readSNSSCommands([]byte("PNG"))
+	assert.ErrorIs(t, err, errInvalidSNSS)
+
+	_, err = readSNSSCommands(append([]byte(snssMagic), 2, 0, 0, 0))
+	assert.ErrorIs(t, err, errUnsupportedSNSS)
+}
@@ -4,10 +4,10 @@ import (
 	"bytes"
 	"database/sql"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
 	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
 
@@ -19,10 +19,15 @@ import (
 )
 
 func init() {
-	extractor.RegisterExtractor(types.ChromiumSessionStorage, func() extractor.Extractor {
+	extractor.RegisterExtractor(types.ChromiumSessionStorage, extractor.Info{
+		Description: "the session storage of the sites",
+		Sources:     []string{"Session Storage"},
+	}, func() extractor.Extractor {
 		return new(ChromiumSessionStorage)
 	})
-	extractor.RegisterExtractor(types.FirefoxSessionStorage, func() extractor.Extractor {
+	extractor.RegisterExtractor(types.FirefoxSessionStorage, extractor.Info{
+		Description: "the session storage of the sites",
+	}, func() extractor.Extractor {
 		return new(FirefoxSessionStorage)
 	})
 }
@@ -38,16 +43,19 @@ type session struct {
 
 const maxLocalStorageValueLength = 1024 * 2
 
-func (c *ChromiumSessionStorage) Extract(_ []byte) error {
-	db, err := leveldb.OpenFile(types.ChromiumSessionStorage.TempFilename(), nil)
+func (c *ChromiumSessionStorage) Extract(_ *extractor.Session) error {
+	db, err := leveldb.OpenFile(types.ChromiumSessionStorage.TempFilename(), &opt.Options{ReadOnly: types.InPlace()})
 	if err != nil {
 		return err
 	}
-	defer os.RemoveAll(types.ChromiumSessionStorage.TempFilename())
+	defer types.ChromiumSessionStorage.RemoveTemp()
 	defer db.Close()
 
 	iter := db.NewIterator(nil, nil)
 	for iter.Next() {
+		if extractor.ReachedMaxRows(len(*c)) {
+			break
+		}
 		key := iter.Key()
 		value := iter.Value()
 		s := new(session)
@@ -123,19 +131,19 @@ const (
 	closeJournalMode    = `PRAGMA journal_mode=off`
 )
 
-func (f *FirefoxSessionStorage) Extract(_ []byte) error {
-	db, err := sql.Open("sqlite", types.FirefoxSessionStorage.TempFilename())
+func (f *FirefoxSessionStorage) Extract(_ *extractor.Session) error {
+	db, err := sql.Open("sqlite", types.FirefoxSessionStorage.DSN())
 	if err != nil {
 		return err
 	}
-	defer os.Remove(types.FirefoxSessionStorage.TempFilename())
+	defer types.FirefoxSessionStorage.RemoveTemp()
 	defer db.Close()
 
 	_, err = db.Exec(closeJournalMode)
 	if err != nil {
 		log.Errorf("close journal mode error: %v", err)
 	}
-	rows, err := db.Query(querySessionStorage)
+	rows, err := db.Query(extractor.LimitQuery(querySessionStorage))
 	if err != nil {
 		return err
 	}
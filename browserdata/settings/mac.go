@@ -0,0 +1,121 @@
+package settings
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// The results of checking the MAC of a protected setting
+const (
+	macValid      = "valid"
+	macInvalid    = "invalid"
+	macUnverified = "unverified"
+)
+
+// macSeeds are the keys of the MACs, chromium builds have no seed and the branded chrome
+// builds the seed of resources.pak.
+// @https://source.chromium.org/chromium/chromium/src/+/main:services/preferences/tracked/pref_hash_calculator.cc
+var macSeeds = [][]byte{nil, chromeSeed}
+
+var chromeSeed, _ = hex.DecodeString("e748f336d85ea5f9dcdf25d8f347a65b4cdf667600f02df6724a2af18a212d26b788a25086910cf3a90313696871f3dc05823730c91df8ba5c4fd9c884b505a8")
+
+// deviceIDKnown reports whether the MACs of the file are keyed with the empty device id of
+// the linux profiles, it's so when one of them matches. The device id of the windows and macOS
+// profiles is derived from the machine, their MACs are unverified wherever the file is read.
+func deviceIDKnown(root gjson.Result) bool {
+	known := false
+	var walk func(prefix string, macs gjson.Result)
+	walk = func(prefix string, macs gjson.Result) {
+		macs.ForEach(func(key, mac gjson.Result) bool {
+			path := key.String()
+			if prefix != "" {
+				path = prefix + "." + path
+			}
+			if mac.IsObject() {
+				walk(path, mac)
+			} else {
+				known = matchMAC(mac.String(), path, root.Get(path))
+			}
+			return !known
+		})
+	}
+	walk("", root.Get(macsPath))
+	return known
+}
+
+// verifyMAC checks the MAC of the setting at path of the file, a mismatch is invalid only if
+// the device id of the file is known.
+func (f prefsFile) verifyMAC(path string, value gjson.Result) string {
+	mac := f.root.Get(macsPath + "." + path)
+	if !mac.Exists() {
+		return ""
+	}
+	switch {
+	case matchMAC(mac.String(), path, value):
+		return macValid
+	case f.deviceIDKnown:
+		return macInvalid
+	}
+	return macUnverified
+}
+
+// matchMAC reports whether the MAC is the HMAC-SHA256 of the empty device id, the path and the
+// json of the value with one of the seeds.
+func matchMAC(mac, path string, value gjson.Result) bool {
+	message := []byte(path + prefString(value))
+	for _, seed := range macSeeds {
+		h := hmac.New(sha256.New, seed)
+		h.Write(message)
+		if strings.EqualFold(hex.EncodeToString(h.Sum(nil)), mac) {
+			return true
+		}
+	}
+	return false
+}
+
+// prefString is the json of the value as chromium writes it, the empty lists and dictionaries
+// of a dictionary are removed and < is escaped, a missing value is empty.
+func prefString(value gjson.Result) string {
+	if !value.Exists() {
+		return ""
+	}
+	d := json.NewDecoder(strings.NewReader(value.Raw))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return value.Raw
+	}
+	if m, ok := v.(map[string]any); ok {
+		removeEmpty(m)
+	}
+	var b bytes.Buffer
+	e := json.NewEncoder(&b)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(v); err != nil {
+		return value.Raw
+	}
+	return strings.ReplaceAll(strings.TrimSuffix(b.String(), "\n"), "<", `\u003C`)
+}
+
+// removeEmpty removes the empty lists and dictionaries of the dictionary and of its children
+func removeEmpty(m map[string]any) {
+	for k, v := range m {
+		switch child := v.(type) {
+		case map[string]any:
+			removeEmpty(child)
+			if len(child) == 0 {
+				delete(m, k)
+			}
+		case []any:
+			if len(child) == 0 {
+				delete(m, k)
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package settings
+
+import (
+	"github.com/tidwall/gjson"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/log"
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/fileutil"
+)
+
+func init() {
+	extractor.RegisterExtractor(types.ChromiumSettings, extractor.Info{
+		Description: "the settings and their MACs",
+		Sources:     []string{"Preferences", "Secure Preferences"},
+	}, func() extractor.Extractor {
+		return new(ChromiumSettings)
+	})
+}
+
+// ChromiumSettings is the proxy, the force-installed extensions and the startup pages of
+// Preferences and Secure Preferences, they're set by the enterprise policies and changed by
+// the malware which hijacks the browser. The protected settings are checked against their
+// MAC in protection.macs, an invalid one means the file was edited outside of the browser.
+type ChromiumSettings []setting
+
+type setting struct {
+	// Name is the path of the setting in the file, eg: proxy.server
+	Name  string
+	Value string
+	// Source is the file of the setting, Preferences or Secure Preferences
+	Source string
+	// MAC is valid, invalid or unverified, empty if the setting isn't protected
+	MAC string
+}
+
+// @https://source.chromium.org/chromium/chromium/src/+/main:chrome/browser/prefs/chrome_pref_service_factory.cc
+const (
+	proxyPath      = "proxy"
+	extensionsPath = "extensions.settings"
+	macsPath       = "protection.macs"
+	// forcedExtension is the name of the settings of the force-installed extensions
+	forcedExtension = "extensions.forced"
+)
+
+// proxyFields are the fields of the proxy config, mode is direct, auto_detect, pac_script,
+// fixed_servers or system.
+var proxyFields = []string{"mode", "server", "pac_url", "bypass_list"}
+
+// startupPaths are the protected settings of the pages opened by the browser
+var startupPaths = []string{"homepage", "homepage_is_newtabpage", "session.restore_on_startup", "session.startup_urls"}
+
+// forcedLocations are the locations of the extensions installed by policy, 7 is the policy
+// download and 9 the policy, @https://source.chromium.org/chromium/chromium/src/+/main:extensions/common/mojom/manifest.mojom
+var forcedLocations = map[int64]bool{7: true, 9: true}
+
+// prefsFile is the content of Preferences or Secure Preferences
+type prefsFile struct {
+	source string
+	root   gjson.Result
+	// deviceIDKnown is whether the MACs of the file can be verified, see deviceIDKnown
+	deviceIDKnown bool
+}
+
+func (c *ChromiumSettings) Extract(_ *extractor.Session) error {
+	prefs, err := fileutil.ReadFile(types.ChromiumSettings.TempFilename())
+	if err != nil {
+		return err
+	}
+	defer types.ChromiumSettings.RemoveTemp()
+	// the protected settings are kept in Secure Preferences on windows and macOS
+	files := []prefsFile{{source: types.ChromiumSecurePreferences.Filename()}, {source: types.ChromiumSettings.Filename(), root: gjson.Parse(prefs)}}
+	if secure, err := fileutil.ReadFile(types.ChromiumSecurePreferences.TempFilename()); err != nil {
+		log.Debugf("read chromium secure preferences error: %v", err)
+	} else {
+		files[0].root = gjson.Parse(secure)
+	}
+	defer types.ChromiumSecurePreferences.RemoveTemp()
+	for i := range files {
+		files[i].deviceIDKnown = deviceIDKnown(files[i].root)
+	}
+
+	proxy := files[1].root.Get(proxyPath)
+	for _, field := range proxyFields {
+		if v := proxy.Get(field); v.Exists() {
+			c.add(setting{Name: proxyPath + "." + field, Value: v.String(), Source: files[1].source})
+		}
+	}
+	for _, path := range startupPaths {
+		for _, f := range files {
+			if v := f.root.Get(path); v.Exists() {
+				c.add(setting{Name: path, Value: v.String(), Source: f.source, MAC: f.verifyMAC(path, v)})
+				break
+			}
+		}
+	}
+	forced := make(map[string]bool)
+	for _, f := range files {
+		f.root.Get(extensionsPath).ForEach(func(id, ext gjson.Result) bool {
+			if forcedLocations[ext.Get("location").Int()] && !forced[id.String()] {
+				forced[id.String()] = true
+				c.add(setting{Name: forcedExtension, Value: id.String(), Source: f.source, MAC: f.verifyMAC(extensionsPath+"."+id.String(), ext)})
+			}
+			return true
+		})
+	}
+	return nil
+}
+
+func (c *ChromiumSettings) add(s setting) {
+	if !extractor.ReachedMaxRows(len(*c)) {
+		*c = append(*c, s)
+	}
+}
+
+func (c *ChromiumSettings) Name() string {
+	return "settings"
+}
+
+func (c *ChromiumSettings) Len() int {
+	return len(*c)
+}
@@ -0,0 +1,79 @@
+package settings
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func testMAC(seed []byte, message string) string {
+	h := hmac.New(sha256.New, seed)
+	h.Write([]byte(message))
+	return strings.ToUpper(hex.EncodeToString(h.Sum(nil)))
+}
+
+func TestChromiumSettings_Extract(t *testing.T) {
+	preferences := `{
+		"proxy": {"mode": "fixed_servers", "server": "127.0.0.1:8080", "bypass_list": "<local>"},
+		"homepage_is_newtabpage": true,
+		"extensions": {"settings": {"bbbb": {"location": 1}}}
+	}`
+	secure := fmt.Sprintf(`{
+		"homepage": "https://start.example.com/",
+		"extensions": {"settings": {"aaaa": {"location": 7, "path": "aaaa/1.0", "granted_permissions": {}}}},
+		"protection": {"macs": {
+			"homepage": %q,
+			"extensions": {"settings": {"aaaa": %q}}
+		}}
+	}`, testMAC(chromeSeed, `homepage"https://start.example.com/"`), testMAC(nil, `extensions.settings.aaaa{"location":7,"path":"aaaa/1.0"}`))
+	require.NoError(t, os.WriteFile(types.ChromiumSettings.TempFilename(), []byte(preferences), 0o600))
+	require.NoError(t, os.WriteFile(types.ChromiumSecurePreferences.TempFilename(), []byte(secure), 0o600))
+
+	var c ChromiumSettings
+	require.NoError(t, c.Extract(nil))
+	assert.Equal(t, ChromiumSettings{
+		{Name: "proxy.mode", Value: "fixed_servers", Source: "Preferences"},
+		{Name: "proxy.server", Value: "127.0.0.1:8080", Source: "Preferences"},
+		{Name: "proxy.bypass_list", Value: "<local>", Source: "Preferences"},
+		{Name: "homepage", Value: "https://start.example.com/", Source: "Secure Preferences", MAC: macValid},
+		{Name: "homepage_is_newtabpage", Value: "true", Source: "Preferences"},
+		{Name: "extensions.forced", Value: "aaaa", Source: "Secure Preferences", MAC: macValid},
+	}, c)
+}
+
+func TestVerifyMAC(t *testing.T) {
+	// the homepage was changed after its MAC was written
+	root := gjson.Parse(fmt.Sprintf(`{"homepage": "https://evil.example/", "protection": {"macs": {"homepage": %q}}}`,
+		testMAC(nil, `homepage"https://start.example.com/"`)))
+	f := prefsFile{root: root, deviceIDKnown: deviceIDKnown(root)}
+	assert.False(t, f.deviceIDKnown)
+	assert.Equal(t, macUnverified, f.verifyMAC("homepage", root.Get("homepage")))
+	assert.Equal(t, "", f.verifyMAC("session.startup_urls", root.Get("session.startup_urls")))
+
+	// the MAC of the extension matches, the device id of the file is known
+	root = gjson.Parse(fmt.Sprintf(`{
+		"homepage": "https://evil.example/",
+		"extensions": {"settings": {"aaaa": {"location": 1}}},
+		"protection": {"macs": {"homepage": %q, "extensions": {"settings": {"aaaa": %q}}}}
+	}`, testMAC(nil, `homepage"https://start.example.com/"`), testMAC(chromeSeed, `extensions.settings.aaaa{"location":1}`)))
+	f = prefsFile{root: root, deviceIDKnown: deviceIDKnown(root)}
+	assert.True(t, f.deviceIDKnown)
+	assert.Equal(t, macInvalid, f.verifyMAC("homepage", root.Get("homepage")))
+	assert.Equal(t, macValid, f.verifyMAC("extensions.settings.aaaa", root.Get("extensions.settings.aaaa")))
+}
+
+func TestPrefString(t *testing.T) {
+	assert.Equal(t, "", prefString(gjson.Result{}))
+	assert.Equal(t, `{"a":{"b":1},"c":"\u003Cx>"}`, prefString(gjson.Parse(`{"c": "<x>", "a": {"b": 1, "d": []}, "e": {}}`)))
+	assert.Equal(t, `[{},[]]`, prefString(gjson.Parse(`[{}, []]`)))
+}
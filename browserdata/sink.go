@@ -0,0 +1,42 @@
+package browserdata
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+)
+
+// consoleDir is the output dir which writes the results to stdout instead of files
+const consoleDir = "-"
+
+// console is the shared sink of stdout, all items writing to the console go through it
+var console = newSink(os.Stdout)
+
+// sink serializes writes of concurrent items to a shared writer.
+// The output of an item is buffered and written at once, so lines never interleave.
+type sink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func newSink(w io.Writer) *sink {
+	return &sink{w: w}
+}
+
+// Write writes p to the underlying writer, it's safe for concurrent use.
+func (s *sink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+// WriteItem buffers everything fn writes and flushes it to the sink in a single write.
+func (s *sink) WriteItem(fn func(w io.Writer) error) error {
+	var buf bytes.Buffer
+	if err := fn(&buf); err != nil {
+		return err
+	}
+	_, err := s.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,48 @@
+package browserdata
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSinkWriteItemConcurrent(t *testing.T) {
+	var buf bytes.Buffer
+	s := newSink(&buf)
+
+	const lines = 1000
+	items := []string{"password", "cookie"}
+	var wg sync.WaitGroup
+	for _, item := range items {
+		wg.Add(1)
+		go func(item string) {
+			defer wg.Done()
+			err := s.WriteItem(func(w io.Writer) error {
+				for i := 0; i < lines; i++ {
+					if _, err := fmt.Fprintf(w, "%s,%d\n", item, i); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			assert.NoError(t, err)
+		}(item)
+	}
+	wg.Wait()
+
+	got := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, got, lines*len(items))
+	// every item must be written as one contiguous block
+	for block := 0; block < len(items); block++ {
+		item, _, _ := strings.Cut(got[block*lines], ",")
+		for i := 0; i < lines; i++ {
+			assert.Equal(t, fmt.Sprintf("%s,%d", item, i), got[block*lines+i])
+		}
+	}
+}
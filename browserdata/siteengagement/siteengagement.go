@@ -0,0 +1,79 @@
+package siteengagement
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/fileutil"
+	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
+)
+
+func init() {
+	extractor.RegisterExtractor(types.ChromiumSiteEngagement, extractor.Info{
+		Description: "the engagement score of the sites",
+		Sources:     []string{"Preferences"},
+	}, func() extractor.Extractor {
+		return new(ChromiumSiteEngagement)
+	})
+}
+
+// ChromiumSiteEngagement is the engagement score Chromium keeps for every site,
+// it grows with the user's interactions, unlike the visit count of history.
+// Firefox has no equivalent, so there is no Firefox item.
+type ChromiumSiteEngagement []engagement
+
+type engagement struct {
+	Origin             string
+	Score              float64
+	PointsAddedToday   float64
+	LastEngagementTime time.Time
+}
+
+// @https://source.chromium.org/chromium/chromium/src/+/main:chrome/browser/engagement/site_engagement_score.cc
+const siteEngagementPath = "profile.content_settings.exceptions.site_engagement"
+
+func (c *ChromiumSiteEngagement) Extract(_ *extractor.Session) error {
+	s, err := fileutil.ReadFile(types.ChromiumSiteEngagement.TempFilename())
+	if err != nil {
+		return err
+	}
+	defer types.ChromiumSiteEngagement.RemoveTemp()
+
+	gjson.Get(s, siteEngagementPath).ForEach(func(pattern, value gjson.Result) bool {
+		if extractor.ReachedMaxRows(len(*c)) {
+			return false
+		}
+		setting := value.Get("setting")
+		*c = append(*c, engagement{
+			Origin:             origin(pattern.String()),
+			Score:              setting.Get("rawScore").Float(),
+			PointsAddedToday:   setting.Get("pointsAddedToday").Float(),
+			LastEngagementTime: typeutil.TimeEpoch(int64(setting.Get("lastEngagementTime").Float())),
+		})
+		return true
+	})
+
+	sort.SliceStable(*c, func(i, j int) bool {
+		return (*c)[i].Score > (*c)[j].Score
+	})
+	return nil
+}
+
+// origin returns the origin of the content settings pattern, eg: https://example.com:443,*
+func origin(pattern string) string {
+	primary, _, _ := strings.Cut(pattern, ",")
+	return primary
+}
+
+func (c *ChromiumSiteEngagement) Name() string {
+	return "siteEngagement"
+}
+
+func (c *ChromiumSiteEngagement) Len() int {
+	return len(*c)
+}
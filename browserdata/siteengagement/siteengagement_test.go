@@ -0,0 +1,44 @@
+package siteengagement
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+const testPreferences = `{
+  "profile": {
+    "content_settings": {
+      "exceptions": {
+        "site_engagement": {
+          "https://example.com:443,*": {
+            "last_modified": "13340000000000000",
+            "setting": {"lastEngagementTime": 1.3340000000000000e+16, "pointsAddedToday": 1.5, "rawScore": 4.5}
+          },
+          "https://github.com:443,*": {
+            "last_modified": "13340000000000000",
+            "setting": {"lastEngagementTime": 1.3340000000000000e+16, "pointsAddedToday": 3.0, "rawScore": 42.1}
+          }
+        }
+      }
+    }
+  }
+}`
+
+func TestChromiumSiteEngagement_Extract(t *testing.T) {
+	require.NoError(t, os.WriteFile(types.ChromiumSiteEngagement.TempFilename(), []byte(testPreferences), 0o600))
+
+	var c ChromiumSiteEngagement
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 2)
+	assert.Equal(t, "https://github.com:443", c[0].Origin)
+	assert.InDelta(t, 42.1, c[0].Score, 0.001)
+	assert.InDelta(t, 3.0, c[0].PointsAddedToday, 0.001)
+	assert.Equal(t, "https://example.com:443", c[1].Origin)
+	assert.Equal(t, 2023, c[1].LastEngagementTime.UTC().Year())
+	assert.NoFileExists(t, types.ChromiumSiteEngagement.TempFilename())
+}
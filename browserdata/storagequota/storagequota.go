@@ -0,0 +1,111 @@
+package storagequota
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	// import sqlite3 driver
+	_ "modernc.org/sqlite"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/log"
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/sqliteutil"
+	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
+)
+
+func init() {
+	extractor.RegisterExtractor(types.ChromiumStorageQuota, extractor.Info{
+		Description: "the storage buckets of the sites and the quota granted to them",
+		Sources:     []string{"QuotaManager"},
+	}, func() extractor.Extractor {
+		return new(ChromiumStorageQuota)
+	})
+}
+
+// ChromiumStorageQuota is the storage buckets tracked by the QuotaManager for every origin,
+// the origins with a large use count or granted quota hold data worth extracting.
+// The QuotaManager doesn't persist the usage, GrantedQuota is the bytes granted to the bucket.
+type ChromiumStorageQuota []quota
+
+type quota struct {
+	Origin       string
+	StorageType  string
+	GrantedQuota int64
+	UseCount     int
+	LastAccessed time.Time
+}
+
+// @https://source.chromium.org/chromium/chromium/src/+/main:components/services/storage/public/cpp/buckets/bucket_info.h
+const (
+	queryChromiumBuckets = `SELECT storage_key, type, quota, use_count, last_accessed FROM buckets`
+	// queryChromiumOriginInfo is used by Chrome before the storage buckets
+	queryChromiumOriginInfo = `SELECT origin, type, 0, used_count, last_access_time FROM OriginInfoTable`
+)
+
+func (c *ChromiumStorageQuota) Extract(_ *extractor.Session) error {
+	db, err := sql.Open("sqlite", types.ChromiumStorageQuota.DSN())
+	if err != nil {
+		return err
+	}
+	defer types.ChromiumStorageQuota.RemoveTemp()
+	defer db.Close()
+
+	query := queryChromiumBuckets
+	if ok, err := sqliteutil.TableExists(db, "buckets"); err == nil && !ok {
+		query = queryChromiumOriginInfo
+	}
+	rows, err := db.Query(extractor.LimitQuery(query))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			origin                  string
+			storageType, useCount   int
+			bytes, lastAccessedTime int64
+		)
+		if err := rows.Scan(&origin, &storageType, &bytes, &useCount, &lastAccessedTime); err != nil {
+			log.Warnf("scan chromium storage quota error: %v", err)
+			continue
+		}
+		*c = append(*c, quota{
+			Origin:       origin,
+			StorageType:  storageTypeName(storageType),
+			GrantedQuota: bytes,
+			UseCount:     useCount,
+			LastAccessed: typeutil.TimeEpoch(lastAccessedTime),
+		})
+	}
+	sort.SliceStable(*c, func(i, j int) bool {
+		if (*c)[i].UseCount != (*c)[j].UseCount {
+			return (*c)[i].UseCount > (*c)[j].UseCount
+		}
+		return (*c)[i].GrantedQuota > (*c)[j].GrantedQuota
+	})
+	return nil
+}
+
+// storageTypeName returns the name of blink::mojom::StorageType
+func storageTypeName(t int) string {
+	switch t {
+	case 0:
+		return "temporary"
+	case 1:
+		return "persistent"
+	case 2:
+		return "syncable"
+	default:
+		return "unknown"
+	}
+}
+
+func (c *ChromiumStorageQuota) Name() string {
+	return "storageQuota"
+}
+
+func (c *ChromiumStorageQuota) Len() int {
+	return len(*c)
+}
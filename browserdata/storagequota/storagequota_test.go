@@ -0,0 +1,57 @@
+package storagequota
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/testutil"
+)
+
+func TestChromiumStorageQuota_Extract(t *testing.T) {
+	testutil.CreateSQLite(t, types.ChromiumStorageQuota,
+		`CREATE TABLE buckets (id INTEGER PRIMARY KEY, storage_key TEXT NOT NULL, host TEXT NOT NULL, type INTEGER NOT NULL, name TEXT NOT NULL, use_count INTEGER NOT NULL, last_accessed INTEGER NOT NULL, last_modified INTEGER NOT NULL, expiration INTEGER NOT NULL, quota INTEGER NOT NULL, persistent INTEGER NOT NULL, durability INTEGER NOT NULL)`,
+		`INSERT INTO buckets VALUES
+			(1, 'https://small.example/', 'small.example', 0, '_default', 9, 13340000000000000, 0, 0, 1024, 0, 0),
+			(2, 'https://large.example/', 'large.example', 1, '_default', 1, 13340000000000000, 0, 0, 1048576, 1, 0),
+			(3, 'https://busy.example/', 'busy.example', 0, '_default', 50, 13340000000000000, 0, 0, 1024, 0, 0)`,
+	)
+
+	var c ChromiumStorageQuota
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 3)
+	assert.Equal(t, "https://busy.example/", c[0].Origin)
+	assert.Equal(t, "temporary", c[0].StorageType)
+	assert.Equal(t, "https://small.example/", c[1].Origin)
+	assert.Equal(t, "https://large.example/", c[2].Origin)
+	assert.Equal(t, "persistent", c[2].StorageType)
+	assert.Equal(t, int64(1048576), c[2].GrantedQuota)
+}
+
+func TestChromiumStorageQuota_ExtractOriginInfo(t *testing.T) {
+	testutil.CreateSQLite(t, types.ChromiumStorageQuota,
+		`CREATE TABLE OriginInfoTable (origin TEXT NOT NULL, type INTEGER NOT NULL, used_count INTEGER DEFAULT 0, last_access_time INTEGER DEFAULT 0, last_modified_time INTEGER DEFAULT 0, PRIMARY KEY(origin, type))`,
+		`INSERT INTO OriginInfoTable VALUES ('https://example.com/', 0, 3, 13340000000000000, 0)`,
+	)
+
+	var c ChromiumStorageQuota
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 1)
+	assert.Equal(t, "https://example.com/", c[0].Origin)
+	assert.Equal(t, 3, c[0].UseCount)
+	assert.Zero(t, c[0].GrantedQuota)
+}
+
+func TestChromiumStorageQuota_ExtractSkipsScanError(t *testing.T) {
+	testutil.CreateSQLite(t, types.ChromiumStorageQuota,
+		`CREATE TABLE buckets (storage_key TEXT, type INTEGER, quota INTEGER, use_count INTEGER, last_accessed INTEGER)`,
+		`INSERT INTO buckets VALUES (NULL, 0, 1024, 1, 0), ('https://example.com/', 0, 1024, 1, 0)`,
+	)
+
+	var c ChromiumStorageQuota
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 1, "the row which can't be scanned is skipped")
+	assert.Equal(t, "https://example.com/", c[0].Origin)
+}
@@ -0,0 +1,106 @@
+package syncdata
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/log"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func init() {
+	extractor.RegisterExtractor(types.ChromiumReadingList, extractor.Info{
+		Description: "the reading list",
+		Sources:     []string{"Sync Data"},
+	}, func() extractor.Extractor {
+		return new(ChromiumReadingList)
+	})
+}
+
+// ChromiumReadingList is the pages saved for later in the reading list, they're kept in the
+// model type store of Sync Data/LevelDB even if sync is off, the newest comes first.
+type ChromiumReadingList []readingListEntry
+
+type readingListEntry struct {
+	URL       string
+	Title     string
+	Status    string
+	AddedTime time.Time
+	UpdatedAt time.Time
+}
+
+// readingListPrefix is the prefix of the entries of the reading list in the model type store
+const readingListPrefix = "reading_list" + dataInfix
+
+// @https://source.chromium.org/chromium/chromium/src/+/main:components/reading_list/core/proto/reading_list.proto
+const (
+	readingListTitleField   = 2
+	readingListURLField     = 3
+	readingListCreateField  = 4
+	readingListUpdateField  = 5
+	readingListStatusField  = 6
+	readingListDefaultState = "unread"
+)
+
+// readingListStates are the values of the status field, an absent status is unread
+var readingListStates = map[uint64]string{0: readingListDefaultState, 1: "read", 2: "unseen"}
+
+func (c *ChromiumReadingList) Extract(_ *extractor.Session) error {
+	db, err := leveldb.OpenFile(filepath.Join(types.ChromiumReadingList.TempFilename(), levelDBFolder), &opt.Options{ReadOnly: types.InPlace()})
+	if err != nil {
+		return err
+	}
+	defer types.ChromiumReadingList.RemoveTemp()
+	defer db.Close()
+
+	iter := db.NewIterator(nil, nil)
+	for iter.Next() {
+		key := string(iter.Key())
+		if !strings.HasPrefix(key, readingListPrefix) {
+			continue
+		}
+		fields, varints, err := protoFields(iter.Value())
+		if err != nil {
+			log.Debugf("parse reading list entry %s error: %v", key, err)
+			continue
+		}
+		e := readingListEntry{
+			URL:    fields[readingListURLField],
+			Title:  fields[readingListTitleField],
+			Status: readingListDefaultState,
+		}
+		if state, ok := readingListStates[varints[readingListStatusField]]; ok {
+			e.Status = state
+		}
+		// the times are microseconds since the unix epoch
+		if t := varints[readingListCreateField]; t > 0 {
+			e.AddedTime = time.UnixMicro(int64(t))
+		}
+		if t := varints[readingListUpdateField]; t > 0 {
+			e.UpdatedAt = time.UnixMicro(int64(t))
+		}
+		*c = append(*c, e)
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	sort.SliceStable(*c, func(i, j int) bool {
+		return (*c)[i].AddedTime.After((*c)[j].AddedTime)
+	})
+	return nil
+}
+
+func (c *ChromiumReadingList) Name() string {
+	return "readingList"
+}
+
+func (c *ChromiumReadingList) Len() int {
+	return len(*c)
+}
@@ -0,0 +1,48 @@
+package syncdata
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+// readingListLocal returns a ReadingListLocal with the title, url, creation time and status
+func readingListLocal(title, url string, created time.Time, status uint64) []byte {
+	b := append([]byte{0x0a, byte(len(url))}, url...)
+	b = append(append(b, 0x12, byte(len(title))), title...)
+	b = append(append(b, 0x1a, byte(len(url))), url...)
+	b = binary.AppendUvarint(append(b, 0x20), uint64(created.UnixMicro()))
+	if status > 0 {
+		b = binary.AppendUvarint(append(b, 0x30), status)
+	}
+	return b
+}
+
+func TestChromiumReadingList_Extract(t *testing.T) {
+	older := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	newer := older.Add(48 * time.Hour)
+	writeSyncData(t, types.ChromiumReadingList, map[string][]byte{
+		"reading_list-dt-https://a.test/":      readingListLocal("A", "https://a.test/", older, 1),
+		"reading_list-dt-https://b.test/":      readingListLocal("B", "https://b.test/", newer, 0),
+		"reading_list-md-https://a.test/":      []byte("metadata"),
+		"reading_list-GlobalMetadata":          modelTypeState("", "1234"),
+		"bookmarks-dt-https://bookmark.test/":  []byte("bookmark"),
+		"reading_list-dt-https://broken.test/": {0x12, 0x10, 'x'},
+	})
+
+	var c ChromiumReadingList
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 2)
+	assert.Equal(t, "https://b.test/", c[0].URL)
+	assert.Equal(t, "B", c[0].Title)
+	assert.Equal(t, "unread", c[0].Status)
+	assert.True(t, newer.Equal(c[0].AddedTime))
+	assert.Equal(t, "https://a.test/", c[1].URL)
+	assert.Equal(t, "read", c[1].Status)
+	assert.NoDirExists(t, types.ChromiumReadingList.TempFilename())
+}
@@ -0,0 +1,183 @@
+package syncdata
+
+import (
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/log"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+func init() {
+	extractor.RegisterExtractor(types.ChromiumSyncData, extractor.Info{
+		Description: "the sync state of the synced data types",
+		Sources:     []string{"Sync Data"},
+	}, func() extractor.Extractor {
+		return new(ChromiumSyncData)
+	})
+}
+
+// ChromiumSyncData is the sync state of every data type synced by the profile, read from the
+// model type store in Sync Data/LevelDB. The synced data isn't decrypted, Encrypted reports
+// whether the type is encrypted by a sync key and PassphraseProtected whether the types which
+// are only encrypted with a custom passphrase are encrypted, ie: the account has a passphrase.
+type ChromiumSyncData []syncType
+
+type syncType struct {
+	Type                string
+	Entities            int
+	Encrypted           bool
+	PassphraseProtected bool
+	Account             string
+}
+
+// @https://source.chromium.org/chromium/chromium/src/+/main:components/sync/model/blocking_model_type_store_impl.cc
+const (
+	levelDBFolder = "LevelDB"
+	// the keys are <type>-dt-<id> for the data, <type>-md-<id> for the metadata of the
+	// entities and <type>-GlobalMetadata for the ModelTypeState of the type
+	dataInfix         = "-dt-"
+	metadataInfix     = "-md-"
+	globalMetadataKey = "-GlobalMetadata"
+)
+
+// @https://source.chromium.org/chromium/chromium/src/+/main:components/sync/protocol/model_type_state.proto
+const (
+	encryptionKeyNameField      = 3
+	authenticatedAccountIDField = 6
+)
+
+// alwaysEncrypted are the types encrypted with the keystore key without a passphrase
+// @https://source.chromium.org/chromium/chromium/src/+/main:components/sync/base/model_type.cc
+var alwaysEncrypted = map[string]bool{
+	"passwords":                            true,
+	"wifi_configurations":                  true,
+	"incoming_password_sharing_invitation": true,
+	"outgoing_password_sharing_invitation": true,
+	"cookies":                              true,
+}
+
+func (c *ChromiumSyncData) Extract(_ *extractor.Session) error {
+	db, err := leveldb.OpenFile(filepath.Join(types.ChromiumSyncData.TempFilename(), levelDBFolder), &opt.Options{ReadOnly: types.InPlace()})
+	if err != nil {
+		return err
+	}
+	defer types.ChromiumSyncData.RemoveTemp()
+	defer db.Close()
+
+	index := make(map[string]int)
+	typeOf := func(name string) *syncType {
+		i, ok := index[name]
+		if !ok {
+			i = len(*c)
+			index[name] = i
+			*c = append(*c, syncType{Type: name})
+		}
+		return &(*c)[i]
+	}
+	iter := db.NewIterator(nil, nil)
+	for iter.Next() {
+		key := string(iter.Key())
+		switch {
+		case strings.HasSuffix(key, globalMetadataKey):
+			t := typeOf(strings.TrimSuffix(key, globalMetadataKey))
+			fields, err := protoStrings(iter.Value())
+			if err != nil {
+				log.Debugf("parse sync metadata of %s error: %v", t.Type, err)
+				continue
+			}
+			t.Encrypted = fields[encryptionKeyNameField] != ""
+			t.Account = fields[authenticatedAccountIDField]
+		case strings.Contains(key, metadataInfix):
+			name, _, _ := strings.Cut(key, metadataInfix)
+			typeOf(name).Entities++
+		case strings.Contains(key, dataInfix):
+			name, _, _ := strings.Cut(key, dataInfix)
+			typeOf(name)
+		}
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	var passphrase bool
+	for _, t := range *c {
+		if t.Encrypted && !alwaysEncrypted[t.Type] {
+			passphrase = true
+		}
+	}
+	for i := range *c {
+		(*c)[i].PassphraseProtected = passphrase
+	}
+	sort.Slice(*c, func(i, j int) bool {
+		return (*c)[i].Type < (*c)[j].Type
+	})
+	return nil
+}
+
+var errTruncatedProto = errors.New("truncated protobuf message")
+
+// protoStrings returns the length-delimited fields of the protobuf message by field number,
+// the other wire types are skipped, the last one wins for repeated fields.
+func protoStrings(b []byte) (map[int]string, error) {
+	fields, _, err := protoFields(b)
+	return fields, err
+}
+
+// protoFields returns the length-delimited and the varint fields of the protobuf message by
+// field number, the fixed size fields are skipped, the last one wins for repeated fields.
+func protoFields(b []byte) (map[int]string, map[int]uint64, error) {
+	fields := make(map[int]string)
+	varints := make(map[int]uint64)
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, nil, errTruncatedProto
+		}
+		b = b[n:]
+		var size uint64
+		switch tag & 0x7 {
+		case 0: // varint
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				return nil, nil, errTruncatedProto
+			}
+			varints[int(tag>>3)] = v
+			size = uint64(n)
+		case 1: // 64-bit
+			size = 8
+		case 2: // length-delimited
+			l, n := binary.Uvarint(b)
+			if n <= 0 || l > uint64(len(b)-n) {
+				return nil, nil, errTruncatedProto
+			}
+			fields[int(tag>>3)] = string(b[n : n+int(l)])
+			size = uint64(n) + l
+		case 5: // 32-bit
+			size = 4
+		default:
+			return nil, nil, errors.New("unsupported protobuf wire type")
+		}
+		if size > uint64(len(b)) {
+			return nil, nil, errTruncatedProto
+		}
+		b = b[size:]
+	}
+	return fields, varints, nil
+}
+
+func (c *ChromiumSyncData) Name() string {
+	return "syncData"
+}
+
+func (c *ChromiumSyncData) Len() int {
+	return len(*c)
+}
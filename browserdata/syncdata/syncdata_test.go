@@ -0,0 +1,70 @@
+package syncdata
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+// modelTypeState returns a ModelTypeState with an initial_sync_done varint, the encryption key
+// name and the account id
+func modelTypeState(keyName, account string) []byte {
+	b := []byte{0x20, 0x01}
+	if keyName != "" {
+		b = append(append(b, 0x1a, byte(len(keyName))), keyName...)
+	}
+	return append(append(b, 0x32, byte(len(account))), account...)
+}
+
+func writeSyncData(t *testing.T, item types.DataType, entries map[string][]byte) {
+	t.Helper()
+	db, err := leveldb.OpenFile(filepath.Join(item.TempFilename(), levelDBFolder), nil)
+	require.NoError(t, err)
+	for k, v := range entries {
+		require.NoError(t, db.Put([]byte(k), v, nil))
+	}
+	require.NoError(t, db.Close())
+}
+
+func TestChromiumSyncData_Extract(t *testing.T) {
+	writeSyncData(t, types.ChromiumSyncData, map[string][]byte{
+		"bookmarks-GlobalMetadata": modelTypeState("", "1234"),
+		"bookmarks-md-a":           nil,
+		"bookmarks-md-b":           nil,
+		"bookmarks-dt-a":           []byte("entity"),
+		"passwords-GlobalMetadata": modelTypeState("keystore_key", "1234"),
+		"passwords-md-c":           nil,
+		"device_info-dt-d":         []byte("device"),
+	})
+
+	var c ChromiumSyncData
+	require.NoError(t, c.Extract(nil))
+	assert.Equal(t, ChromiumSyncData{
+		{Type: "bookmarks", Entities: 2, Account: "1234"},
+		{Type: "device_info"},
+		{Type: "passwords", Entities: 1, Encrypted: true, Account: "1234"},
+	}, c)
+	assert.NoDirExists(t, types.ChromiumSyncData.TempFilename())
+}
+
+func TestChromiumSyncData_ExtractPassphrase(t *testing.T) {
+	writeSyncData(t, types.ChromiumSyncData, map[string][]byte{
+		"bookmarks-GlobalMetadata": modelTypeState("custom_passphrase_key", "1234"),
+		"passwords-GlobalMetadata": modelTypeState("custom_passphrase_key", "1234"),
+		"history-GlobalMetadata":   {0x1a, 0x10, 'x'},
+	})
+
+	var c ChromiumSyncData
+	require.NoError(t, c.Extract(nil))
+	require.Len(t, c, 3)
+	for _, s := range c {
+		assert.True(t, s.PassphraseProtected, s.Type)
+	}
+	assert.Equal(t, "history", c[1].Type)
+	assert.False(t, c[1].Encrypted, "the truncated metadata is skipped")
+}
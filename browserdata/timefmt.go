@@ -0,0 +1,86 @@
+package browserdata
+
+import (
+	"reflect"
+	"time"
+)
+
+// outputTime is a timestamp of the records written to csv and json, the zero time of a failed
+// or absent timestamp is written as an empty string to csv and null to json instead of
+// 0001-01-01T00:00:00Z, the other times are written as time.Time does.
+type outputTime time.Time
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	outputTimeType = reflect.TypeOf(outputTime{})
+)
+
+// zeroEpochs returns the epochs of the timestamps which are 0, they're written as the zero time
+// too. typeutil.TimeEpoch(0) is 1601-01-01 in the local time zone, which isn't the instant of
+// 1601-01-01 UTC out of UTC, and typeutil.TimeStamp(0) is 1970-01-01 of unix.
+func zeroEpochs() [2]time.Time {
+	return [2]time.Time{
+		time.Date(1601, 1, 1, 0, 0, 0, 0, time.Local),
+		time.Unix(0, 0),
+	}
+}
+
+// isZero reports whether the time is the zero time or the epoch of a 0 timestamp
+func (t outputTime) isZero() bool {
+	if time.Time(t).IsZero() {
+		return true
+	}
+	for _, epoch := range zeroEpochs() {
+		if time.Time(t).Equal(epoch) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t outputTime) MarshalJSON() ([]byte, error) {
+	if t.isZero() {
+		return []byte("null"), nil
+	}
+	return time.Time(t).MarshalJSON()
+}
+
+func (t outputTime) MarshalCSV() (string, error) {
+	if t.isZero() {
+		return "", nil
+	}
+	text, err := time.Time(t).MarshalText()
+	return string(text), err
+}
+
+// formatTimes returns the records with their time.Time fields as outputTime,
+// the records without a time field are returned as is.
+func formatTimes(rows any) any {
+	v := reflect.ValueOf(rows)
+	elemType, ok := recordType(v)
+	if !ok {
+		return rows
+	}
+	var hasTime bool
+	fields := make([]viewField, 0, elemType.NumField())
+	for i := 0; i < elemType.NumField(); i++ {
+		f := elemType.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		field := viewField{StructField: reflect.StructField{Name: f.Name, Type: f.Type, Tag: f.Tag}, value: copyField(i)}
+		if f.Type == timeType {
+			index := i
+			field.Type = outputTimeType
+			field.value = func(record reflect.Value) reflect.Value {
+				return record.Field(index).Convert(outputTimeType)
+			}
+			hasTime = true
+		}
+		fields = append(fields, field)
+	}
+	if !hasTime {
+		return rows
+	}
+	return makeView(v, fields).Interface()
+}
@@ -0,0 +1,68 @@
+package browserdata
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/utils/typeutil"
+)
+
+func TestWrite_ZeroTime(t *testing.T) {
+	c := newTestCookies(t, "parsed", "failed")
+	created := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	// the second cookie's timestamp failed to parse and was left zero
+	reflect.ValueOf(c).Elem().Index(0).FieldByName("CreateDate").Set(reflect.ValueOf(created))
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, c))
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 3)
+	assert.Equal(t, "example.com,,,parsed,false,false,false,false,2024-05-01T12:30:00Z,,,false,,", string(lines[1]))
+	assert.Equal(t, "example.com,,,failed,false,false,false,false,,,,false,,", string(lines[2]))
+
+	buf.Reset()
+	require.NoError(t, WriteJSON(&buf, c))
+	assert.Contains(t, buf.String(), `"CreateDate": "2024-05-01T12:30:00Z"`)
+	assert.Contains(t, buf.String(), `"CreateDate": null`)
+	assert.NotContains(t, buf.String(), "0001-01-01")
+	assert.True(t, (*c)[1].CreateDate.IsZero(), "the records are left untouched")
+}
+
+func TestOutputTime_ZeroEpochs(t *testing.T) {
+	// the local 1601-01-01 of Asia/Shanghai is +0805 LMT, it isn't the instant of 1601-01-01 UTC
+	local := time.Local
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		shanghai = time.FixedZone("CST", 8*60*60)
+	}
+	time.Local = shanghai
+	t.Cleanup(func() { time.Local = local })
+
+	for _, tt := range []struct {
+		time time.Time
+		want string
+	}{
+		{time: time.Time{}, want: ""},
+		{time: typeutil.TimeEpoch(0), want: ""},
+		// typeutil.TimeStamp(0) is the unix epoch in the local time zone
+		{time: time.Unix(0, 0), want: ""},
+		{time: time.Date(1970, 1, 1, 0, 0, 1, 0, time.UTC), want: "1970-01-01T00:00:01Z"},
+		{time: time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC), want: "2024-05-01T12:30:00Z"},
+	} {
+		csv, err := outputTime(tt.time).MarshalCSV()
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, csv, tt.time.String())
+		jsonTime, err := outputTime(tt.time).MarshalJSON()
+		require.NoError(t, err)
+		if tt.want == "" {
+			assert.Equal(t, "null", string(jsonTime))
+		} else {
+			assert.Equal(t, `"`+tt.want+`"`, string(jsonTime))
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package browserdata
+
+import (
+	"reflect"
+)
+
+// viewField is a field of a view of the records, eg: a time field written as outputTime
+type viewField struct {
+	reflect.StructField
+	// value returns the field of the view from the record
+	value func(record reflect.Value) reflect.Value
+}
+
+// copyField returns the value of the view field which is the field of the record at index
+func copyField(index int) func(reflect.Value) reflect.Value {
+	return func(record reflect.Value) reflect.Value {
+		return record.Field(index)
+	}
+}
+
+// recordType returns the struct type of the records, a slice of structs or of pointers to
+// structs, ok is false for the other records.
+func recordType(rows reflect.Value) (t reflect.Type, ok bool) {
+	if !rows.IsValid() || rows.Kind() != reflect.Slice {
+		return nil, false
+	}
+	t = rows.Type().Elem()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t, t.Kind() == reflect.Struct
+}
+
+// makeView returns the records as a slice of structs of the fields, the nil records are skipped.
+// The records are converted without changing them, so they can be written more than once.
+func makeView(rows reflect.Value, fields []viewField) reflect.Value {
+	structFields := make([]reflect.StructField, 0, len(fields))
+	for _, f := range fields {
+		structFields = append(structFields, f.StructField)
+	}
+	view := reflect.StructOf(structFields)
+	out := reflect.MakeSlice(reflect.SliceOf(view), 0, rows.Len())
+	for i := 0; i < rows.Len(); i++ {
+		elem := rows.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				continue
+			}
+			elem = elem.Elem()
+		}
+		r := reflect.New(view).Elem()
+		for j, f := range fields {
+			r.Field(j).Set(f.value(elem))
+		}
+		out = reflect.Append(out, r)
+	}
+	return out
+}
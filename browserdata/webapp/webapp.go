@@ -0,0 +1,118 @@
+package webapp
+
+import (
+	"net/url"
+	"path"
+	"sort"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/types"
+	"github.com/moond4rk/hackbrowserdata/utils/fileutil"
+)
+
+func init() {
+	extractor.RegisterExtractor(types.ChromiumWebApp, extractor.Info{
+		Description: "the installed web apps",
+		Sources:     []string{"Preferences"},
+	}, func() extractor.Extractor {
+		return new(ChromiumWebApp)
+	})
+}
+
+// ChromiumWebApp is the progressive web apps installed in the profile, Preferences keeps the
+// start URL of the installed apps in web_apps and the manifest of the apps installed as
+// bookmark apps in extensions. Firefox doesn't install web apps, so there is no Firefox item.
+type ChromiumWebApp []webApp
+
+type webApp struct {
+	AppID    string
+	Name     string
+	StartURL string
+	Scope    string
+}
+
+// @https://source.chromium.org/chromium/chromium/src/+/main:chrome/browser/web_applications/externally_installed_web_app_prefs.cc
+const (
+	webAppIDsPath    = "web_apps.extension_ids"
+	extensionsPath   = "extensions.settings"
+	webAppLaunchPath = "manifest.app.launch.web_url"
+)
+
+func (c *ChromiumWebApp) Extract(_ *extractor.Session) error {
+	s, err := fileutil.ReadFile(types.ChromiumWebApp.TempFilename())
+	if err != nil {
+		return err
+	}
+	defer types.ChromiumWebApp.RemoveTemp()
+
+	index := make(map[string]int)
+	add := func(app webApp) {
+		if i, ok := index[app.AppID]; ok && app.AppID != "" {
+			// the bookmark app has the name and scope of the installed app
+			if app.Name != "" {
+				(*c)[i].Name = app.Name
+			}
+			if app.Scope != "" {
+				(*c)[i].Scope = app.Scope
+			}
+			return
+		}
+		if extractor.ReachedMaxRows(len(*c)) {
+			return
+		}
+		index[app.AppID] = len(*c)
+		*c = append(*c, app)
+	}
+
+	gjson.Get(s, webAppIDsPath).ForEach(func(startURL, value gjson.Result) bool {
+		add(webApp{AppID: value.Get("extension_id").String(), StartURL: startURL.String()})
+		return true
+	})
+	gjson.Get(s, extensionsPath).ForEach(func(id, value gjson.Result) bool {
+		startURL := value.Get(webAppLaunchPath).String()
+		if startURL == "" {
+			return true
+		}
+		add(webApp{
+			AppID:    id.String(),
+			Name:     value.Get("manifest.name").String(),
+			StartURL: startURL,
+			Scope:    value.Get("manifest.scope").String(),
+		})
+		return true
+	})
+	for i := range *c {
+		if (*c)[i].Scope == "" {
+			(*c)[i].Scope = defaultScope((*c)[i].StartURL)
+		}
+	}
+
+	sort.SliceStable(*c, func(i, j int) bool {
+		return (*c)[i].StartURL < (*c)[j].StartURL
+	})
+	return nil
+}
+
+// defaultScope returns the folder of the start URL, the scope of an app whose manifest has none
+// @https://www.w3.org/TR/appmanifest/#scope-member
+func defaultScope(startURL string) string {
+	u, err := url.Parse(startURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	dir, _ := path.Split(u.Path)
+	if dir == "" {
+		dir = "/"
+	}
+	return u.Scheme + "://" + u.Host + dir
+}
+
+func (c *ChromiumWebApp) Name() string {
+	return "webApps"
+}
+
+func (c *ChromiumWebApp) Len() int {
+	return len(*c)
+}
@@ -0,0 +1,35 @@
+package webapp
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+const preferences = `{
+	"web_apps": {"extension_ids": {
+		"https://app.example.com/inbox/index.html": {"extension_id": "aaaa", "install_source": 1, "is_placeholder": false},
+		"https://other.example.org/": {"extension_id": "bbbb", "install_source": 3}
+	}},
+	"extensions": {"settings": {
+		"aaaa": {"manifest": {"name": "Example Mail", "app": {"launch": {"web_url": "https://app.example.com/inbox/index.html"}}}},
+		"cccc": {"manifest": {"name": "Notes", "scope": "https://notes.example.net/", "app": {"launch": {"web_url": "https://notes.example.net/app/"}}}},
+		"dddd": {"manifest": {"name": "An extension"}}
+	}}
+}`
+
+func TestChromiumWebApp_Extract(t *testing.T) {
+	require.NoError(t, os.WriteFile(types.ChromiumWebApp.TempFilename(), []byte(preferences), 0o600))
+
+	var c ChromiumWebApp
+	require.NoError(t, c.Extract(nil))
+	assert.Equal(t, ChromiumWebApp{
+		{AppID: "aaaa", Name: "Example Mail", StartURL: "https://app.example.com/inbox/index.html", Scope: "https://app.example.com/inbox/"},
+		{AppID: "cccc", Name: "Notes", StartURL: "https://notes.example.net/app/", Scope: "https://notes.example.net/"},
+		{AppID: "bbbb", StartURL: "https://other.example.org/", Scope: "https://other.example.org/"},
+	}, c)
+}
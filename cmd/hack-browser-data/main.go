@@ -1,12 +1,30 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"time"
 
 	"github.com/urfave/cli/v2"
 
 	"github.com/moond4rk/hackbrowserdata/browser"
+	"github.com/moond4rk/hackbrowserdata/browser/chromium"
+	"github.com/moond4rk/hackbrowserdata/browser/firefox"
+	"github.com/moond4rk/hackbrowserdata/browserdata"
+	"github.com/moond4rk/hackbrowserdata/browserdata/bookmark"
+	"github.com/moond4rk/hackbrowserdata/browserdata/cookie"
+	"github.com/moond4rk/hackbrowserdata/browserdata/creditcard"
+	"github.com/moond4rk/hackbrowserdata/browserdata/password"
+	"github.com/moond4rk/hackbrowserdata/crypto"
+	"github.com/moond4rk/hackbrowserdata/extractor"
 	"github.com/moond4rk/hackbrowserdata/log"
+	"github.com/moond4rk/hackbrowserdata/server"
+	"github.com/moond4rk/hackbrowserdata/types"
 	"github.com/moond4rk/hackbrowserdata/utils/fileutil"
 )
 
@@ -15,15 +33,173 @@ var (
 	outputDir    string
 	outputFormat string
 	verbose      bool
+	quiet        bool
 	compress     bool
 	profilePath  string
 	isFullExport bool
+	pseudonymize bool
+	ffProfile    string
+	tempDir      string
+	verifySum    bool
+	serveAddr    string
+	serveToken   string
+	tlsCert      string
+	tlsKey       string
+	manifest     bool
+	maxRows      int
+	invalidUTF8  string
+	outputFields string
+	browserConf  string
+	inPlace      bool
+	profileDirs  bool
+	watch        bool
+	watchEvery   time.Duration
+	base64Fields string
+	writeEmpty   bool
+	selfTest     bool
+	pwdStrength  bool
+	hibp         bool
+	subdomains   bool
+	legacyJSON   bool
+	listJSON     bool
+	onlyDomain   string
+	cardUsage    bool
+	sysProfiles  bool
+	pwdSort      string
+	noCheck      bool
+	outputName   string
+	maxValue     int
+	compressOut  string
+	separators   bool
+	noQueries    bool
+	chromeKey    string
+	helpItems    bool
+	cryptoRetry  int
+	mergeLogins  bool
+	listProfiles bool
+	appendOut    bool
+	mergeJSON    bool
+	dedupe       bool
+	profileGlob  string
+	sampleCheck  bool
 )
 
 func main() {
 	Execute()
 }
 
+// logSummary logs which items of the browser were extracted and which failed
+func logSummary(browserName string, stats browserdata.Stats) {
+	var succeeded, failed []string
+	for _, item := range stats.Items {
+		if item.Error != "" {
+			failed = append(failed, fmt.Sprintf("%s (%s)", item.Name, item.Error))
+			continue
+		}
+		succeeded = append(succeeded, fmt.Sprintf("%s (%d)", item.Name, item.Records))
+	}
+	log.Warnf("%s summary: %d items succeeded, %d failed in %s", browserName, len(succeeded), len(failed), stats.Elapsed.Round(time.Millisecond))
+	if len(succeeded) > 0 {
+		log.Warnf("%s succeeded: %s", browserName, strings.Join(succeeded, ", "))
+	}
+	if len(failed) > 0 {
+		log.Warnf("%s failed: %s", browserName, strings.Join(failed, ", "))
+	}
+	if stats.DecryptFailed > 0 {
+		log.Warnf("%s decrypted %d values, %d failed", browserName, stats.Decrypted, stats.DecryptFailed)
+	}
+	if stats.Duplicates > 0 {
+		log.Warnf("%s dropped %d duplicate records", browserName, stats.Duplicates)
+	}
+}
+
+// runSelfTest checks the decryption round trips of the current platform before touching any browser
+func runSelfTest() error {
+	var failed int
+	for _, r := range crypto.SelfTest() {
+		if r.Err != nil {
+			failed++
+			log.Errorf("self test %s: fail, %v", r.Name, r.Err)
+			continue
+		}
+		log.Warnf("self test %s: pass", r.Name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("self test: %d checks failed", failed)
+	}
+	return nil
+}
+
+var (
+	// runSummary aggregates the exported browsers to the exit code of the run
+	runSummary browserdata.RunSummary
+	// exitCode is the exit code of the run, 0 for the commands which don't export the browsers
+	exitCode int
+)
+
+// exportBrowser extracts the browsing data of the browser and writes it to the output dir
+func exportBrowser(b browser.Browser) {
+	data, err := b.BrowsingData(isFullExport)
+	if err != nil {
+		log.Errorf("get browsing data error %v", err)
+		runSummary.AddFailed()
+		return
+	}
+	name, profile := b.Profile()
+	data.SetProfile(name, profile)
+	if profileDirs {
+		err = data.OutputProfile(outputDir, name, profile, outputFormat)
+	} else {
+		err = data.Output(outputDir, b.Name(), outputFormat)
+	}
+	stats := data.Stats()
+	if err != nil {
+		log.Errorf("output %s error %v", b.Name(), err)
+		runSummary.AddFailed()
+	} else {
+		runSummary.Add(stats)
+	}
+	logSummary(b.Name(), stats)
+}
+
+// writeSamples writes the --sample-decrypt reports as json to stdout, the exit code is
+// partial if a sample failed and failure if none was decrypted.
+func writeSamples(reports []browser.SampleReport) error {
+	var ok, failed int
+	for _, r := range reports {
+		if r.Error != "" {
+			failed++
+		}
+		for _, s := range r.Items {
+			if s.OK {
+				ok++
+			} else {
+				failed++
+			}
+		}
+	}
+	switch {
+	case ok == 0:
+		exitCode = browserdata.ExitFailure
+	case failed > 0:
+		exitCode = browserdata.ExitPartial
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(reports)
+}
+
+// removeSessionDir removes the temp files of the run, also when an item panics
+func removeSessionDir() {
+	r := recover()
+	if err := types.RemoveSessionDir(); err != nil {
+		log.Warnf("remove temp dir error %v", err)
+	}
+	if r != nil {
+		panic(r)
+	}
+}
+
 func Execute() {
 	app := &cli.App{
 		Name:      "hack-browser-data",
@@ -32,31 +208,203 @@ func Execute() {
 		Version:   "0.5.0",
 		Flags: []cli.Flag{
 			&cli.BoolFlag{Name: "verbose", Aliases: []string{"vv"}, Destination: &verbose, Value: false, Usage: "verbose"},
+			&cli.BoolFlag{Name: "quiet", Aliases: []string{"q"}, Destination: &quiet, Value: false, Usage: "only log the errors, the warnings and the export progress are dropped"},
 			&cli.BoolFlag{Name: "compress", Aliases: []string{"zip"}, Destination: &compress, Value: false, Usage: "compress result to zip"},
+			&cli.BoolFlag{Name: "merge-items-into-one-json", Destination: &mergeJSON, Value: false, Usage: "write the json of every browser profile as one object keyed by the item names instead of a file per item"},
+			&cli.BoolFlag{Name: "append", Destination: &appendOut, Value: false, Usage: "append to the existing output files instead of replacing them, the csv header is written once, json must be jsonl, not with --compress"},
+			&cli.StringFlag{Name: "compress-output", Destination: &compressOut, Value: "", Usage: "compress each output file, eg: gzip writes <item>.csv.gz"},
 			&cli.StringFlag{Name: "browser", Aliases: []string{"b"}, Destination: &browserName, Value: "all", Usage: "available browsers: all|" + browser.Names()},
-			&cli.StringFlag{Name: "results-dir", Aliases: []string{"dir"}, Destination: &outputDir, Value: "results", Usage: "export dir"},
-			&cli.StringFlag{Name: "format", Aliases: []string{"f"}, Destination: &outputFormat, Value: "csv", Usage: "output format: csv|json"},
-			&cli.StringFlag{Name: "profile-path", Aliases: []string{"p"}, Destination: &profilePath, Value: "", Usage: "custom profile dir path, get with chrome://version"},
+			&cli.StringFlag{Name: "results-dir", Aliases: []string{"dir"}, Destination: &outputDir, Value: "results", Usage: "export dir, - for stdout"},
+			&cli.StringFlag{Name: "format", Aliases: []string{"f"}, Destination: &outputFormat, Value: "csv", Usage: "output format: csv|json|jsonl|header|editthiscookie|all, comma separated for several, eg: csv,json, all is csv and json, jsonl writes a json record per line, header writes cookies as Set-Cookie lines, editthiscookie as the json of the EditThisCookie extension"},
+			&cli.StringFlag{Name: "output-name", Destination: &outputName, Value: "", Usage: "template of the output filenames, tokens: {browser} {profile} {item} {date} {format}, {item} is required, eg: {browser}-{profile}-{item}-{date}"},
+			&cli.StringFlag{Name: "domain", Destination: &onlyDomain, Value: "", Usage: "only extract and decrypt the cookies and passwords of the domain and its subdomains, eg: github.com"},
+			&cli.BoolFlag{Name: "include-subdomains", Destination: &subdomains, Value: false, Usage: "group the cookies by registrable domain, eg: a.example.com and b.example.com under example.com"},
+			&cli.BoolFlag{Name: "dedupe-cookies", Destination: &dedupe, Value: false, Usage: "keep the newest cookie of the same host, name and path, chromium may have duplicate rows while writing the cookies"},
+			&cli.BoolFlag{Name: "legacy-json", Destination: &legacyJSON, Value: false, Usage: "write json in the field names and layout of 0.3, eg: LoginUrl and cookies grouped by host"},
+			&cli.BoolFlag{Name: "profile-dirs", Destination: &profileDirs, Value: false, Usage: "write every profile to <dir>/<browser>/<profile>/<item>.<ext>"},
+			&cli.StringFlag{Name: "chrome-key", Destination: &chromeKey, Value: "", Usage: "hex or base64 master key of the chromium browser selected with -b, used instead of the keychain or Local State"},
+			&cli.StringFlag{Name: "profile-path", Aliases: []string{"p"}, Destination: &profilePath, Value: "", Usage: "custom profile dir path, get with chrome://version, or a zip, tar or tar.gz archive of it, read as chrome and firefox with -b all"},
 			&cli.BoolFlag{Name: "full-export", Aliases: []string{"full"}, Destination: &isFullExport, Value: true, Usage: "is export full browsing data"},
+			&cli.StringFlag{Name: "profile-glob", Destination: &profileGlob, Value: "", Usage: "only export the profiles whose folder name matches the pattern, eg: \"Profile *\", every firefox profile of profiles.ini is matched unless --firefox-profile is set"},
+			&cli.StringFlag{Name: "firefox-profile", Destination: &ffProfile, Value: "", Usage: "firefox profile name in profiles.ini, default is the default profile, all for all profiles"},
+			&cli.BoolFlag{Name: "include-system", Destination: &sysProfiles, Value: false, Usage: "export the chromium Guest Profile and System Profile too, they are skipped by default"},
+			&cli.BoolFlag{Name: "no-decrypt-check", Destination: &noCheck, Value: false, Usage: "decrypt the firefox passwords even if the password-check of key4.db doesn't match"},
+			&cli.StringFlag{Name: "temp-dir", Destination: &tempDir, Value: "", Usage: "dir to copy browser files to before parsing, default is the system temp dir, a folder of the run is created in it and removed at exit"},
+			&cli.BoolFlag{Name: "in-place", Destination: &inPlace, Value: false, Usage: "read the browser files read-only in place instead of copying them, for offline images, fails if a running browser locks them, a wal database with a -wal file but no -shm file can't be opened on a read-only mount"},
+			&cli.BoolFlag{Name: "include-separators", Destination: &separators, Value: false, Usage: "keep the separators of firefox bookmarks"},
+			&cli.BoolFlag{Name: "exclude-queries", Destination: &noQueries, Value: false, Usage: "skip the place: queries of the firefox smart folders"},
+			&cli.BoolFlag{Name: "verify-checksum", Destination: &verifySum, Value: false, Usage: "verify the checksum of chromium bookmarks, warn if the file was tampered"},
+			&cli.BoolFlag{Name: "watch", Destination: &watch, Value: false, Usage: "keep running and export the browser again when its files change, stop with ctrl+c"},
+			&cli.DurationFlag{Name: "watch-interval", Destination: &watchEvery, Value: 5 * time.Second, Usage: "time the browser files must stay unchanged in watch mode before their items are exported again"},
+			&cli.StringFlag{Name: "serve", Destination: &serveAddr, Value: "", Usage: "serve the extraction as a HTTP service on the address, eg: 127.0.0.1:8080"},
+			&cli.StringFlag{Name: "serve-token", Destination: &serveToken, Value: "", Usage: "shared token required by the HTTP service"},
+			&cli.StringFlag{Name: "tls-cert", Destination: &tlsCert, Value: "", Usage: "tls certificate file of the HTTP service"},
+			&cli.StringFlag{Name: "tls-key", Destination: &tlsKey, Value: "", Usage: "tls key file of the HTTP service"},
+			&cli.BoolFlag{Name: "write-empty", Destination: &writeEmpty, Value: false, Usage: "write the items without records as well, by default they are skipped"},
+			&cli.BoolFlag{Name: "manifest", Destination: &manifest, Value: false, Usage: "write manifest.json with the sha256, size and records of every exported file"},
+			&cli.IntFlag{Name: "max-rows", Destination: &maxRows, Value: 0, Usage: "parse at most N records per item for a quick preview, applied before sorting, 0 is no limit"},
+			&cli.IntFlag{Name: "max-value-size", Destination: &maxValue, Value: extractor.DefaultMaxValueSize, Usage: "truncate the cookie and password values over N bytes, the original length is kept in the marker, 0 is no limit"},
+			&cli.IntFlag{Name: "crypto-retries", Destination: &cryptoRetry, Value: crypto.DefaultRetries, Usage: "retry the transient failures of DPAPI and the keychain N times with backoff, 0 is never"},
+			&cli.StringFlag{Name: "fields", Destination: &outputFields, Value: "", Usage: "comma separated fields to export, eg: host,value, default is all fields, the header and editthiscookie formats keep their own fields"},
+			&cli.StringFlag{Name: "csv-base64", Destination: &base64Fields, Value: "", Usage: "comma separated fields to base64 encode in csv, eg: value,password, the header becomes value_b64"},
+			&cli.StringFlag{Name: "invalid-utf8", Destination: &invalidUTF8, Value: browserdata.InvalidUTF8Replace, Usage: "how to write invalid utf8 in values: replace|hex"},
+			&cli.StringFlag{Name: "browser-config", Destination: &browserConf, Value: "", Usage: "json file of extra chromium or firefox forks, replaces the built-in browsers with the same key"},
+			&cli.BoolFlag{Name: "password-strength", Destination: &pwdStrength, Value: false, Usage: "add the strength of every password to the output"},
+			&cli.BoolFlag{Name: "merge-logins-backup", Destination: &mergeLogins, Value: false, Usage: "add the firefox logins of logins-backup.json which were removed from logins.json"},
+			&cli.StringFlag{Name: "sort", Destination: &pwdSort, Value: password.SortCreated, Usage: "order of the passwords: created|usage|last-used, usage is how often they were autofilled"},
+			&cli.BoolFlag{Name: "card-usage", Destination: &cardUsage, Value: false, Usage: "add how often and when every credit card was used by autofill to the output"},
+			&cli.BoolFlag{Name: "hibp", Destination: &hibp, Value: false, Usage: "look up how often every password was breached with the HaveIBeenPwned range api, only the first 5 chars of the sha1 are sent"},
+			&cli.BoolFlag{Name: "browsers-json", Destination: &listJSON, Value: false, Usage: "write the found browsers, profiles and item files as json to stdout and exit, nothing is copied"},
+			&cli.BoolFlag{Name: "list-profiles", Destination: &listProfiles, Value: false, Usage: "write the found profiles with the time they were last used as json to stdout and exit, the most recent first"},
+			&cli.BoolFlag{Name: "help-items", Destination: &helpItems, Value: false, Usage: "list every item with its output fields and the files it's read from per browser engine and exit"},
+			&cli.BoolFlag{Name: "sample-decrypt", Destination: &sampleCheck, Value: false, Usage: "decrypt the first password and cookie of every profile, write the decryption method, key source and errors as json to stdout and exit, the values aren't written"},
+			&cli.BoolFlag{Name: "self-test", Destination: &selfTest, Value: false, Usage: "check the decryption works on this platform with synthetic data and exit"},
+			&cli.BoolFlag{Name: "pseudonymize", Destination: &pseudonymize, Value: false, Usage: "replace usernames and emails with stable pseudonyms"},
 		},
 		HideHelpCommand: true,
 		Action: func(c *cli.Context) error {
-			if verbose {
+			switch {
+			case verbose && quiet:
+				return errors.New("--verbose and --quiet can't be used together")
+			case appendOut && compress:
+				// the zip removes the output files, the next run would append to nothing and nest the zip
+				return errors.New("--append and --compress can't be used together")
+			case verbose:
 				log.SetVerbose()
+			case quiet:
+				log.SetQuiet()
+			}
+			if selfTest {
+				return runSelfTest()
+			}
+			if helpItems {
+				return browserdata.WriteItemsHelp(os.Stdout, browser.ItemBrowsers)
+			}
+			if err := browserdata.SetPseudonymize(pseudonymize); err != nil {
+				log.Errorf("enable pseudonymize error %v", err)
+				return err
+			}
+			browserdata.SetFields(outputFields)
+			browserdata.SetBase64Fields(base64Fields)
+			browserdata.SetLegacyJSON(legacyJSON)
+			if err := browserdata.SetOutputName(outputName); err != nil {
+				log.Errorf("set output name error %v", err)
+				return err
+			}
+			if err := browserdata.SetCompressOutput(compressOut); err != nil {
+				log.Errorf("set output compression error %v", err)
+				return err
+			}
+			browserdata.SetMergeJSON(mergeJSON)
+			if err := browserdata.SetAppend(appendOut, outputFormat); err != nil {
+				log.Errorf("set append error %v", err)
+				return err
+			}
+			if err := browserdata.SetInvalidUTF8(invalidUTF8); err != nil {
+				log.Errorf("set invalid utf8 mode error %v", err)
+				return err
+			}
+			if err := types.SetTempDir(tempDir); err != nil {
+				log.Errorf("set temp dir error %v", err)
+				return err
+			}
+			if _, err := types.CreateSessionDir(); err != nil {
+				log.Errorf("create temp dir error %v", err)
+				return err
+			}
+			defer removeSessionDir()
+			types.SetInPlace(inPlace)
+			if err := browser.SetProfileGlob(profileGlob); err != nil {
+				log.Errorf("set profile glob error %v", err)
+				return err
+			}
+			if profileGlob != "" && ffProfile == "" {
+				// the glob selects among all the profiles, not only the default one
+				ffProfile = firefox.AllProfiles
+			}
+			firefox.SetProfileName(ffProfile)
+			chromium.SetIncludeSystem(sysProfiles)
+			if err := chromium.SetChromeKey(browserName, chromeKey); err != nil {
+				log.Errorf("set chrome key error %v", err)
+				return err
+			}
+			firefox.SetSkipDecryptCheck(noCheck)
+			bookmark.SetVerifyChecksum(verifySum)
+			bookmark.SetIncludeSeparators(separators)
+			bookmark.SetExcludeQueries(noQueries)
+			password.SetStrength(pwdStrength)
+			password.SetHIBP(hibp)
+			password.SetMergeBackup(mergeLogins)
+			if err := password.SetSort(pwdSort); err != nil {
+				log.Errorf("set password sort error %v", err)
+				return err
+			}
+			creditcard.SetUsage(cardUsage)
+			cookie.SetIncludeSubdomains(subdomains)
+			cookie.SetDedupe(dedupe)
+			extractor.SetMaxRows(maxRows)
+			if sampleCheck {
+				extractor.SetMaxRows(1)
+			}
+			browserdata.SetSampleDecrypt(sampleCheck)
+			extractor.SetMaxValueSize(maxValue)
+			crypto.SetRetries(cryptoRetry)
+			extractor.SetDomain(onlyDomain)
+			browserdata.SetWriteEmpty(writeEmpty)
+			browserdata.SetManifest(manifest && outputDir != "-")
+			if browserConf != "" {
+				if err := browser.LoadBrowserConfig(browserConf); err != nil {
+					log.Errorf("load browser config error %v", err)
+					return err
+				}
+			}
+			if serveAddr != "" {
+				srv, err := server.New(serveToken, browserName, profilePath, isFullExport)
+				if err != nil {
+					return err
+				}
+				return srv.ListenAndServe(serveAddr, tlsCert, tlsKey)
 			}
 			browsers, err := browser.PickBrowsers(browserName, profilePath)
 			if err != nil {
-				log.Errorf("pick browsers %v", err)
 				return err
 			}
+			if listJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(browser.Discover(browsers))
+			}
+			if listProfiles {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(browser.ListProfiles(browsers))
+			}
+			if sampleCheck {
+				return writeSamples(browser.SampleDecrypt(browsers))
+			}
 
 			for _, b := range browsers {
-				data, err := b.BrowsingData(isFullExport)
-				if err != nil {
-					log.Errorf("get browsing data error %v", err)
-					continue
+				exportBrowser(b)
+			}
+			if watch {
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+				log.Warnf("watching %d browsers", len(browsers))
+				err := browser.Watch(ctx, browsers, watchEvery, func(b browser.Browser, items []types.DataType) {
+					log.Warnf("%s changed, export %d items again", b.Name(), len(items))
+					// the merged json holds every item, so the whole browser is exported again
+					if !mergeJSON {
+						b = browser.SelectItems(b, items)
+					}
+					exportBrowser(b)
+				})
+				stop()
+				if err != nil && !errors.Is(err, context.Canceled) {
+					log.Errorf("watch error %v", err)
 				}
-				data.Output(outputDir, b.Name(), outputFormat)
+			}
+
+			if err = browserdata.WriteManifest(outputDir, c.App.Name, c.App.Version); err != nil {
+				log.Errorf("write manifest error %v", err)
 			}
 
 			if compress {
@@ -65,11 +413,17 @@ func Execute() {
 				}
 				log.Debug("compress success")
 			}
+			exitCode = runSummary.ExitCode()
 			return nil
 		},
 	}
 	err := app.Run(os.Args)
+	if errors.Is(err, browser.ErrNotFound) {
+		log.Errorf("pick browsers %v", err)
+		os.Exit(browserdata.ExitNotFound)
+	}
 	if err != nil {
 		log.Fatalf("run app error %v", err)
 	}
+	os.Exit(exitCode)
 }
@@ -1,11 +1,13 @@
 package crypto
 
 import (
+	"crypto/aes"
 	"crypto/hmac"
 	"crypto/sha1"
 	"crypto/sha256"
 	"encoding/asn1"
 	"errors"
+	"fmt"
 )
 
 type ASN1PBE interface {
@@ -34,6 +36,47 @@ func NewASN1PBE(b []byte) (pbe ASN1PBE, err error) {
 
 var ErrDecodeASN1Failed = errors.New("decode ASN1 data failed")
 
+// the object identifiers of the pbe used by key4.db and logins.json
+var (
+	oidPBES2       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACSHA1    = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACSHA256  = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidAES256CBC   = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidSHA1And3DES = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 5, 1, 3}
+	oidDESEDE3CBC  = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+)
+
+const (
+	// PBE3DES is the sha1 and 3des pbe of the legacy key4.db and of logins.json
+	PBE3DES = "3des"
+	// PBEAES256 is the pbes2 with pbkdf2 and aes-256-cbc of key4.db since Firefox 75
+	PBEAES256 = "aes-256-cbc"
+)
+
+// PBEAlgorithm returns the cipher of the pbe from its ASN.1 object identifier,
+// the identifier itself for an unknown cipher.
+func PBEAlgorithm(pbe ASN1PBE) string {
+	var oid asn1.ObjectIdentifier
+	switch p := pbe.(type) {
+	case nssPBE:
+		oid = p.AlgoAttr.ObjectIdentifier
+	case metaPBE:
+		oid = p.AlgoAttr.Data.IVData.ObjectIdentifier
+	case loginPBE:
+		oid = p.Data.ObjectIdentifier
+	default:
+		return ""
+	}
+	switch {
+	case oid.Equal(oidSHA1And3DES), oid.Equal(oidDESEDE3CBC):
+		return PBE3DES
+	case oid.Equal(oidAES256CBC):
+		return PBEAES256
+	}
+	return oid.String()
+}
+
 // nssPBE Struct
 //
 //	SEQUENCE (2 elem)
@@ -137,27 +180,59 @@ type slatAttr struct {
 	}
 }
 
+// maxIterationCount bounds the pbkdf2 iterations of a crafted item, Firefox uses 10000
+const maxIterationCount = 1 << 20
+
+var ErrInvalidPBEParams = errors.New("invalid pbe parameters")
+
 func (m metaPBE) Decrypt(globalSalt []byte) ([]byte, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
 	key, iv := m.deriveKeyAndIV(globalSalt)
 
-	return AES128CBCDecrypt(key, iv, m.Encrypted)
+	return AESCBCDecrypt(key, iv, m.Encrypted)
 }
 
 func (m metaPBE) Encrypt(globalSalt, plaintext []byte) ([]byte, error) {
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
 	key, iv := m.deriveKeyAndIV(globalSalt)
 
-	return AES128CBCEncrypt(key, iv, plaintext)
+	return AESCBCEncrypt(key, iv, plaintext)
 }
 
+// validate checks the key size and iterations before deriving the key, they are read from key4.db
+func (m metaPBE) validate() error {
+	attr := m.AlgoAttr.Data.Data.SlatAttr
+	switch attr.KeySize {
+	case 16, 24, 32:
+	default:
+		return fmt.Errorf("%w: key size %d", ErrInvalidPBEParams, attr.KeySize)
+	}
+	if attr.IterationCount < 1 || attr.IterationCount > maxIterationCount {
+		return fmt.Errorf("%w: iteration count %d", ErrInvalidPBEParams, attr.IterationCount)
+	}
+	return nil
+}
+
+// deriveKeyAndIV derives the key with the pbkdf2 parameters of the pbes2, the prf is hmac-sha256
+// unless it's hmac-sha1. NSS writes the 16 bytes iv as an octet string of 14 bytes, the 2 bytes
+// 04 0e of its der header are the start of the iv.
 func (m metaPBE) deriveKeyAndIV(globalSalt []byte) ([]byte, []byte) {
 	password := sha1.Sum(globalSalt)
 
-	salt := m.AlgoAttr.Data.Data.SlatAttr.EntrySalt
-	iter := m.AlgoAttr.Data.Data.SlatAttr.IterationCount
-	keyLen := m.AlgoAttr.Data.Data.SlatAttr.KeySize
-
-	key := PBKDF2Key(password[:], salt, iter, keyLen, sha256.New)
-	iv := append([]byte{4, 14}, m.AlgoAttr.Data.IVData.IV...)
+	attr := m.AlgoAttr.Data.Data.SlatAttr
+	prf := sha256.New
+	if attr.Algorithm.ObjectIdentifier.Equal(oidHMACSHA1) {
+		prf = sha1.New
+	}
+	key := PBKDF2Key(password[:], attr.EntrySalt, attr.IterationCount, attr.KeySize, prf)
+	iv := m.AlgoAttr.Data.IVData.IV
+	if len(iv) != aes.BlockSize {
+		iv = append([]byte{4, 14}, iv...)
+	}
 	return key, iv
 }
 
@@ -177,16 +252,49 @@ type loginPBE struct {
 	Encrypted []byte
 }
 
+// ErrLoginKeyTooShort is returned when an aes-256-cbc login is decrypted with a 3des master key
+var ErrLoginKeyTooShort = errors.New("master key is too short for aes-256-cbc")
+
+// Decrypt decrypts the login with the master key, the logins are 3des unless their
+// object identifier is aes-256-cbc, as Firefox writes with an aes master key.
 func (l loginPBE) Decrypt(globalSalt []byte) ([]byte, error) {
-	key, iv := l.deriveKeyAndIV(globalSalt)
+	key, iv, err := l.deriveKeyAndIV(globalSalt)
+	if err != nil {
+		return nil, err
+	}
+	if l.isAES() {
+		return AESCBCDecrypt(key, iv, l.Encrypted)
+	}
 	return DES3Decrypt(key, iv, l.Encrypted)
 }
 
 func (l loginPBE) Encrypt(globalSalt, plaintext []byte) ([]byte, error) {
-	key, iv := l.deriveKeyAndIV(globalSalt)
+	key, iv, err := l.deriveKeyAndIV(globalSalt)
+	if err != nil {
+		return nil, err
+	}
+	if l.isAES() {
+		return AESCBCEncrypt(key, iv, plaintext)
+	}
 	return DES3Encrypt(key, iv, plaintext)
 }
 
-func (l loginPBE) deriveKeyAndIV(globalSalt []byte) ([]byte, []byte) {
-	return globalSalt, l.Data.IV
+func (l loginPBE) isAES() bool {
+	return l.Data.ObjectIdentifier.Equal(oidAES256CBC)
+}
+
+// deriveKeyAndIV returns the master key as the key of the cipher, the 3des key is the first
+// 24 bytes of an aes master key.
+func (l loginPBE) deriveKeyAndIV(globalSalt []byte) ([]byte, []byte, error) {
+	size := 24
+	if l.isAES() {
+		size = 32
+	}
+	if len(globalSalt) < size {
+		if l.isAES() {
+			return nil, nil, ErrLoginKeyTooShort
+		}
+		return globalSalt, l.Data.IV, nil
+	}
+	return globalSalt[:size], l.Data.IV, nil
 }
@@ -295,3 +295,70 @@ func TestLoginPBE_Decrypt(t *testing.T) {
 		assert.Equal(t, pbePlaintext, decrypted)
 	}
 }
+
+func TestPBEAlgorithm(t *testing.T) {
+	var nss nssPBE
+	nss.AlgoAttr.ObjectIdentifier = oidSHA1And3DES
+	assert.Equal(t, PBE3DES, PBEAlgorithm(nss))
+
+	var meta metaPBE
+	meta.AlgoAttr.ObjectIdentifier = oidPBES2
+	meta.AlgoAttr.Data.IVData.ObjectIdentifier = oidAES256CBC
+	assert.Equal(t, PBEAES256, PBEAlgorithm(meta))
+
+	var login loginPBE
+	login.Data.ObjectIdentifier = oidDESEDE3CBC
+	assert.Equal(t, PBE3DES, PBEAlgorithm(login))
+	login.Data.ObjectIdentifier = objWithMD5AndDESCBC
+	assert.Equal(t, "1.2.840.113549.1.5.3", PBEAlgorithm(login))
+}
+
+func TestLoginPBE_DecryptLongKey(t *testing.T) {
+	tc := loginPBETestCases[0]
+	var login loginPBE
+	login.Data.IV = tc.IV
+	login.Encrypted = tc.Encrypted
+	// the 3des key is the first 24 bytes of a 32 bytes master key
+	decrypted, err := login.Decrypt(append(append([]byte{}, tc.GlobalSalt...), bytes.Repeat([]byte{1}, 8)...))
+	assert.NoError(t, err)
+	assert.Equal(t, pbePlaintext, decrypted)
+}
+
+// the vectors are encrypted by openssl enc -aes-256-cbc, the pbkdf2 key by python hashlib
+func TestLoginPBE_DecryptAES(t *testing.T) {
+	var login loginPBE
+	login.CipherText = pbeCipherText
+	login.Data.ObjectIdentifier = oidAES256CBC
+	login.Data.IV = []byte("abcdefghijklmnop")
+	login.Encrypted, _ = hex.DecodeString("43ae7e6b8420289a3718485d24bfb7f0")
+	raw, err := asn1.Marshal(login)
+	assert.NoError(t, err)
+
+	pbe, err := NewASN1PBE(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, PBEAES256, PBEAlgorithm(pbe))
+	decrypted, err := pbe.Decrypt([]byte("0123456789abcdef0123456789abcdef"))
+	assert.NoError(t, err)
+	assert.Equal(t, pbePlaintext, decrypted)
+
+	_, err = pbe.Decrypt(bytes.Repeat([]byte(baseKey), 3))
+	assert.ErrorIs(t, err, ErrLoginKeyTooShort)
+}
+
+func TestMetaPBE_DecryptHMACSHA1(t *testing.T) {
+	var meta metaPBE
+	meta.AlgoAttr.ObjectIdentifier = oidPBES2
+	meta.AlgoAttr.Data.Data.ObjectIdentifier = oidPBKDF2
+	meta.AlgoAttr.Data.Data.SlatAttr.EntrySalt = bytes.Repeat([]byte("s"), 32)
+	meta.AlgoAttr.Data.Data.SlatAttr.IterationCount = 1000
+	meta.AlgoAttr.Data.Data.SlatAttr.KeySize = 32
+	meta.AlgoAttr.Data.Data.SlatAttr.Algorithm.ObjectIdentifier = oidHMACSHA1
+	meta.AlgoAttr.Data.IVData.ObjectIdentifier = oidAES256CBC
+	// a standard 16 bytes iv, NSS writes 14 bytes
+	meta.AlgoAttr.Data.IVData.IV = []byte("abcdefghijklmnop")
+	meta.Encrypted, _ = hex.DecodeString("479f99ab069d81004e5922daf2ad5d1d1552c433c4b7c695923804cb13a4219c")
+
+	decrypted, err := meta.Decrypt(bytes.Repeat([]byte(baseKey), 3))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("password-check\x02\x02"), decrypted)
+}
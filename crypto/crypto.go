@@ -9,19 +9,27 @@ import (
 	"fmt"
 )
 
-var ErrCiphertextLengthIsInvalid = errors.New("ciphertext length is invalid")
+var (
+	ErrCiphertextLengthIsInvalid = errors.New("ciphertext length is invalid")
+	ErrIVLengthIsInvalid         = errors.New("iv length must equal the block size")
+)
 
-func AES128CBCDecrypt(key, iv, ciphertext []byte) ([]byte, error) {
+// AESCBCDecrypt decrypts the ciphertext with AES-128, AES-192 or AES-256 in CBC mode, the
+// variant is chosen by the length of the key, eg: 32 bytes for the AES-256 of key4.db.
+func AESCBCDecrypt(key, iv, ciphertext []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
+	if len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("AESCBCDecrypt: %w", ErrIVLengthIsInvalid)
+	}
 	// Check ciphertext length
 	if len(ciphertext) < aes.BlockSize {
-		return nil, errors.New("AES128CBCDecrypt: ciphertext too short")
+		return nil, errors.New("AESCBCDecrypt: ciphertext too short")
 	}
 	if len(ciphertext)%aes.BlockSize != 0 {
-		return nil, errors.New("AES128CBCDecrypt: ciphertext is not a multiple of the block size")
+		return nil, errors.New("AESCBCDecrypt: ciphertext is not a multiple of the block size")
 	}
 
 	decryptedData := make([]byte, len(ciphertext))
@@ -29,22 +37,23 @@ func AES128CBCDecrypt(key, iv, ciphertext []byte) ([]byte, error) {
 	mode.CryptBlocks(decryptedData, ciphertext)
 
 	// unpad the decrypted data and handle potential padding errors
-	decryptedData, err = pkcs5UnPadding(decryptedData)
+	decryptedData, err = pkcs5UnPadding(decryptedData, aes.BlockSize)
 	if err != nil {
-		return nil, fmt.Errorf("AES128CBCDecrypt: %w", err)
+		return nil, fmt.Errorf("AESCBCDecrypt: %w", err)
 	}
 
 	return decryptedData, nil
 }
 
-func AES128CBCEncrypt(key, iv, plaintext []byte) ([]byte, error) {
+// AESCBCEncrypt is the reverse of AESCBCDecrypt
+func AESCBCEncrypt(key, iv, plaintext []byte) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(iv) != aes.BlockSize {
-		return nil, errors.New("AES128CBCEncrypt: iv length is invalid, must equal block size")
+		return nil, errors.New("AESCBCEncrypt: iv length is invalid, must equal block size")
 	}
 
 	plaintext = pkcs5Padding(plaintext, block.BlockSize())
@@ -60,6 +69,9 @@ func DES3Decrypt(key, iv, ciphertext []byte) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	if len(iv) != des.BlockSize {
+		return nil, fmt.Errorf("DES3Decrypt: %w", ErrIVLengthIsInvalid)
+	}
 	if len(ciphertext) < des.BlockSize {
 		return nil, errors.New("DES3Decrypt: ciphertext too short")
 	}
@@ -71,7 +83,7 @@ func DES3Decrypt(key, iv, ciphertext []byte) ([]byte, error) {
 	sq := make([]byte, len(ciphertext))
 	blockMode.CryptBlocks(sq, ciphertext)
 
-	return pkcs5UnPadding(sq)
+	return pkcs5UnPadding(sq, des.BlockSize)
 }
 
 func DES3Encrypt(key, iv, plaintext []byte) ([]byte, error) {
@@ -79,6 +91,9 @@ func DES3Encrypt(key, iv, plaintext []byte) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	if len(iv) != des.BlockSize {
+		return nil, fmt.Errorf("DES3Encrypt: %w", ErrIVLengthIsInvalid)
+	}
 
 	plaintext = pkcs5Padding(plaintext, block.BlockSize())
 	dst := make([]byte, len(plaintext))
@@ -129,15 +144,22 @@ func paddingZero(src []byte, length int) []byte {
 	return append(src, make([]byte, padding)...)
 }
 
-func pkcs5UnPadding(src []byte) ([]byte, error) {
+// pkcs5UnPadding removes the padding of the decrypted src, a wrong key or a corrupt ciphertext
+// decrypts to garbage which rarely has a valid padding, so it's reported as an error.
+func pkcs5UnPadding(src []byte, blockSize int) ([]byte, error) {
 	length := len(src)
 	if length == 0 {
 		return nil, errors.New("pkcs5UnPadding: src should not be empty")
 	}
 	padding := int(src[length-1])
-	if padding < 1 || padding > aes.BlockSize {
+	if padding < 1 || padding > blockSize || padding > length {
 		return nil, errors.New("pkcs5UnPadding: invalid padding size")
 	}
+	for _, b := range src[length-padding:] {
+		if int(b) != padding {
+			return nil, errors.New("pkcs5UnPadding: inconsistent padding bytes")
+		}
+	}
 	return src[:length-padding], nil
 }
 
@@ -7,7 +7,24 @@ func DecryptWithChromium(key, encryptPass []byte) ([]byte, error) {
 		return nil, ErrCiphertextLengthIsInvalid
 	}
 	iv := []byte{32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32}
-	return AES128CBCDecrypt(key, iv, encryptPass[3:])
+	return AESCBCDecrypt(key, iv, encryptPass[3:])
+}
+
+// chromiumCipher is the cipher of the encrypted values, the key is derived from the Safe Storage password
+const chromiumCipher = "AES-CBC"
+
+// chromiumKeySize is the size of the master key derived from the Safe Storage password
+const chromiumKeySize = 16
+
+// encryptWithChromium encrypts the plaintext as Chromium does with the v10 prefix,
+// it's the reverse of DecryptWithChromium for the self test.
+func encryptWithChromium(key, plaintext []byte) ([]byte, error) {
+	iv := []byte{32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32, 32}
+	encrypted, err := AESCBCEncrypt(key, iv, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("v10"), encrypted...), nil
 }
 
 func DecryptWithDPAPI(_ []byte) ([]byte, error) {
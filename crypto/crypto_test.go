@@ -26,16 +26,16 @@ var (
 	aesGCMCiphertext = "6c49dac89992639713edab3a114c450968a08b53556872cea3919e2e9a"
 )
 
-func TestAES128CBCEncrypt(t *testing.T) {
-	encrypted, err := AES128CBCEncrypt(aesKey, aesIV, plainText)
+func TestAESCBCEncrypt(t *testing.T) {
+	encrypted, err := AESCBCEncrypt(aesKey, aesIV, plainText)
 	assert.Equal(t, nil, err)
 	assert.Equal(t, true, len(encrypted) > 0)
 	assert.Equal(t, aes128Ciphertext, fmt.Sprintf("%x", encrypted))
 }
 
-func TestAES128CBCDecrypt(t *testing.T) {
+func TestAESCBCDecrypt(t *testing.T) {
 	ciphertext, _ := hex.DecodeString(aes128Ciphertext)
-	decrypted, err := AES128CBCDecrypt(aesKey, aesIV, ciphertext)
+	decrypted, err := AESCBCDecrypt(aesKey, aesIV, ciphertext)
 	assert.Equal(t, nil, err)
 	assert.Equal(t, true, len(decrypted) > 0)
 	assert.Equal(t, plainText, decrypted)
@@ -70,3 +70,32 @@ func TestAESGCMDecrypt(t *testing.T) {
 	assert.Equal(t, true, len(decrypted) > 0)
 	assert.Equal(t, plainText, decrypted)
 }
+
+func TestPKCS5UnPadding(t *testing.T) {
+	tests := []struct {
+		name      string
+		src       []byte
+		blockSize int
+		want      []byte
+		wantErr   bool
+	}{
+		{"valid", []byte{'a', 'b', 'c', 5, 5, 5, 5, 5}, 8, []byte("abc"), false},
+		{"full block of padding", bytes.Repeat([]byte{8}, 8), 8, []byte{}, false},
+		{"empty", []byte{}, 8, nil, true},
+		{"zero padding", []byte{'a', 0}, 8, nil, true},
+		{"padding larger than block size", bytes.Repeat([]byte{9}, 16), 8, nil, true},
+		{"padding longer than src", []byte{1, 2, 3, 4, 5, 6, 7, 16}, 16, nil, true},
+		{"inconsistent padding bytes", []byte{'a', 'b', 'c', 'd', 'e', 3, 2, 3}, 8, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pkcs5UnPadding(tt.src, tt.blockSize)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -3,6 +3,8 @@
 package crypto
 
 import (
+	"crypto/rand"
+	"errors"
 	"fmt"
 	"syscall"
 	"unsafe"
@@ -25,6 +27,26 @@ func DecryptWithChromium(key, ciphertext []byte) ([]byte, error) {
 	return AESGCMDecrypt(key, nonce, encryptedPassword)
 }
 
+// chromiumCipher is the cipher of the encrypted values since Chrome 80
+const chromiumCipher = "AES-GCM"
+
+// chromiumKeySize is the size of the AES-256 master key kept in Local State
+const chromiumKeySize = 32
+
+// encryptWithChromium encrypts the plaintext as Chromium does with the v10 prefix and
+// the nonce, it's the reverse of DecryptWithChromium for the self test.
+func encryptWithChromium(key, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	encrypted, err := AESGCMEncrypt(key, nonce, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte("v10"), nonce...), encrypted...), nil
+}
+
 // DecryptWithYandex decrypts the password with AES-GCM
 func DecryptWithYandex(key, ciphertext []byte) ([]byte, error) {
 	if len(ciphertext) < minEncryptedDataSize {
@@ -66,6 +88,15 @@ func (b *dataBlob) bytes() []byte {
 // available as a built-in component in Windows 2000 and
 // later versions of Microsoft Windows operating systems
 func DecryptWithDPAPI(ciphertext []byte) ([]byte, error) {
+	var plaintext []byte
+	err := Retry("CryptUnprotectData", func() (err error) {
+		plaintext, err = unprotectData(ciphertext)
+		return err
+	}, isTransientDPAPI)
+	return plaintext, err
+}
+
+func unprotectData(ciphertext []byte) ([]byte, error) {
 	crypt32 := syscall.NewLazyDLL("Crypt32.dll")
 	kernel32 := syscall.NewLazyDLL("Kernel32.dll")
 	unprotectDataProc := crypt32.NewProc("CryptUnprotectData")
@@ -84,3 +115,23 @@ func DecryptWithDPAPI(ciphertext []byte) ([]byte, error) {
 	defer localFreeProc.Call(uintptr(unsafe.Pointer(outBlob.pbData)))
 	return outBlob.bytes(), nil
 }
+
+// dpapiTransientErrors are the errors of CryptUnprotectData when the protected storage service
+// can't be reached or is busy, the others like ERROR_ACCESS_DENIED, ERROR_INVALID_DATA or
+// NTE_BAD_KEY_STATE of another user or a changed password fail the same way every time.
+var dpapiTransientErrors = []syscall.Errno{
+	170,  // ERROR_BUSY
+	1460, // ERROR_TIMEOUT
+	1722, // RPC_S_SERVER_UNAVAILABLE
+	1723, // RPC_S_SERVER_TOO_BUSY
+	1726, // RPC_S_CALL_FAILED
+}
+
+func isTransientDPAPI(err error) bool {
+	for _, errno := range dpapiTransientErrors {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	return false
+}
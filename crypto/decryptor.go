@@ -0,0 +1,92 @@
+package crypto
+
+import (
+	"errors"
+)
+
+// ErrKeyDenied is returned by DeniedDecryptor, the access to the master key was denied
+var ErrKeyDenied = errors.New("access to the master key denied")
+
+// Decryptor decrypts the values of a browser profile, it's built once from the master key of
+// the profile and called for every row, so the items don't pick the scheme themselves. The
+// ciphers are created for every value, so a Decryptor is safe for concurrent use.
+type Decryptor interface {
+	// Decrypt returns the plaintext of the value
+	Decrypt(ciphertext []byte) ([]byte, error)
+	// Method returns the decryption method of the value, eg: AES-GCM-v10
+	Method(ciphertext []byte) string
+}
+
+// NewChromiumDecryptor returns the decryptor of the chromium values, the values are DPAPI blobs
+// if there is no master key, before Chrome 80.
+func NewChromiumDecryptor(key []byte) Decryptor {
+	if len(key) == 0 {
+		return DPAPIDecryptor{}
+	}
+	return ChromiumDecryptor{Key: key}
+}
+
+// DeniedDecryptor is the decryptor of a profile whose master key couldn't be read as the
+// access was denied, eg: the keychain prompt on macOS, every value fails with ErrKeyDenied.
+type DeniedDecryptor struct{}
+
+func (DeniedDecryptor) Decrypt(_ []byte) ([]byte, error) {
+	return nil, ErrKeyDenied
+}
+
+func (DeniedDecryptor) Method(_ []byte) string {
+	return ""
+}
+
+// DPAPIDecryptor decrypts the DPAPI blobs with the credentials of the current windows user
+type DPAPIDecryptor struct{}
+
+func (DPAPIDecryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	return DecryptWithDPAPI(ciphertext)
+}
+
+func (DPAPIDecryptor) Method(_ []byte) string {
+	return MethodDPAPI
+}
+
+// ChromiumDecryptor decrypts the v10 and v11 values with the master key, the DPAPI blobs of
+// the rows migrated from before the master key are decrypted with DPAPI.
+type ChromiumDecryptor struct {
+	Key []byte
+}
+
+func (c ChromiumDecryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	if IsDPAPIBlob(ciphertext) {
+		return DecryptWithDPAPI(ciphertext)
+	}
+	return DecryptWithChromium(c.Key, ciphertext)
+}
+
+func (c ChromiumDecryptor) Method(ciphertext []byte) string {
+	if IsDPAPIBlob(ciphertext) {
+		return MethodDPAPI
+	}
+	return ChromiumMethod(ciphertext)
+}
+
+// NSSDecryptor decrypts the der encoded pbe of firefox with the key of key4.db
+type NSSDecryptor struct {
+	Key []byte
+}
+
+func (n NSSDecryptor) Decrypt(ciphertext []byte) ([]byte, error) {
+	pbe, err := NewASN1PBE(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return pbe.Decrypt(n.Key)
+}
+
+// Method returns NSS-3DES or NSS-AES, empty if the value isn't a pbe
+func (n NSSDecryptor) Method(ciphertext []byte) string {
+	pbe, err := NewASN1PBE(ciphertext)
+	if err != nil {
+		return ""
+	}
+	return NSSMethod(pbe)
+}
@@ -0,0 +1,63 @@
+package crypto
+
+import (
+	"encoding/asn1"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewChromiumDecryptor(t *testing.T) {
+	assert.Equal(t, DPAPIDecryptor{}, NewChromiumDecryptor(nil))
+	assert.Equal(t, ChromiumDecryptor{Key: []byte("key")}, NewChromiumDecryptor([]byte("key")))
+}
+
+func TestDPAPIDecryptor(t *testing.T) {
+	var d Decryptor = DPAPIDecryptor{}
+	assert.Equal(t, MethodDPAPI, d.Method([]byte("v10ciphertext")))
+}
+
+func TestChromiumDecryptor(t *testing.T) {
+	key, err := randomBytes(chromiumKeySize)
+	require.NoError(t, err)
+	encrypted, err := encryptWithChromium(key, plainText)
+	require.NoError(t, err)
+
+	var d Decryptor = ChromiumDecryptor{Key: key}
+	decrypted, err := d.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, plainText, decrypted)
+	assert.Equal(t, chromiumCipher+"-v10", d.Method(encrypted))
+
+	// a row kept from before Chrome 80 is a DPAPI blob in a profile with the master key
+	blob := append(append([]byte{}, dpapiBlobHeader...), 0x01, 0x00, 0x00, 0x00)
+	assert.True(t, IsDPAPIBlob(blob))
+	assert.False(t, IsDPAPIBlob(encrypted))
+	assert.Equal(t, MethodDPAPI, d.Method(blob))
+	// every value is a DPAPI blob without the master key
+	assert.Equal(t, MethodDPAPI, NewChromiumDecryptor(nil).Method(encrypted))
+}
+
+func TestNSSDecryptor(t *testing.T) {
+	var pbe loginPBE
+	pbe.Data.ObjectIdentifier = oidAES256CBC
+	pbe.CipherText = make([]byte, 16)
+	pbe.Data.IV = make([]byte, 16)
+	key, err := randomBytes(32)
+	require.NoError(t, err)
+	pbe.Encrypted, err = pbe.Encrypt(key, plainText)
+	require.NoError(t, err)
+	raw, err := asn1.Marshal(pbe)
+	require.NoError(t, err)
+
+	var d Decryptor = NSSDecryptor{Key: key}
+	decrypted, err := d.Decrypt(raw)
+	require.NoError(t, err)
+	assert.Equal(t, plainText, decrypted)
+	assert.Equal(t, MethodNSSAES, d.Method(raw))
+
+	_, err = d.Decrypt([]byte("not a pbe"))
+	assert.Error(t, err)
+	assert.Equal(t, "", d.Method([]byte("not a pbe")))
+}
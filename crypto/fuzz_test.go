@@ -0,0 +1,68 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// FuzzNewASN1PBE feeds malformed key4.db and logins.json items to the decoders,
+// a corrupt or crafted profile must fail with an error instead of a panic.
+func FuzzNewASN1PBE(f *testing.F) {
+	for _, raw := range []string{nssPBETestCases[0].RawHexPBE, metaPBETestCases[0].RawHexPBE, loginPBETestCases[0].RawHexPBE} {
+		b, err := hex.DecodeString(raw)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b, nssPBETestCases[0].GlobalSalt)
+	}
+	f.Fuzz(func(t *testing.T, data, globalSalt []byte) {
+		pbe, err := NewASN1PBE(data)
+		if err != nil {
+			return
+		}
+		_, _ = pbe.Decrypt(globalSalt)
+		_, _ = pbe.Encrypt(globalSalt, data)
+	})
+}
+
+// FuzzDecryptCBC feeds random keys, ivs and ciphertexts to the block cipher decryption
+func FuzzDecryptCBC(f *testing.F) {
+	f.Add(aesKey, aesIV, []byte(aes128Ciphertext))
+	f.Add(des3Key, des3IV, []byte(des3Ciphertext))
+	f.Fuzz(func(t *testing.T, key, iv, ciphertext []byte) {
+		_, _ = AESCBCDecrypt(key, iv, ciphertext)
+		_, _ = DES3Decrypt(key, iv, ciphertext)
+	})
+}
+
+func TestDecryptMalformedPBE(t *testing.T) {
+	var meta metaPBE
+	meta.AlgoAttr.Data.Data.SlatAttr.KeySize = 32
+	meta.AlgoAttr.Data.Data.SlatAttr.IterationCount = 1
+	meta.AlgoAttr.Data.IVData.IV = []byte("short")
+	meta.Encrypted = make([]byte, 16)
+	negativeKeySize, hugeIterationCount := meta, meta
+	negativeKeySize.AlgoAttr.Data.Data.SlatAttr.KeySize = -1
+	hugeIterationCount.AlgoAttr.Data.Data.SlatAttr.IterationCount = 1 << 30
+
+	var login loginPBE
+	login.Data.IV = []byte("short")
+	login.Encrypted = make([]byte, 8)
+
+	tests := []struct {
+		name string
+		pbe  ASN1PBE
+	}{
+		{"meta short iv", meta},
+		{"meta negative key size", negativeKeySize},
+		{"meta huge iteration count", hugeIterationCount},
+		{"login short iv", login},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.pbe.Decrypt(des3Key); err == nil {
+				t.Error("Decrypt() of a malformed pbe returned no error")
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package crypto
+
+import "bytes"
+
+// The decryption methods of the records, they tell which scheme a value was encrypted with.
+const (
+	MethodDPAPI     = "DPAPI"
+	MethodNSS3DES   = "NSS-3DES"
+	MethodNSSAES    = "NSS-AES"
+	MethodPlaintext = "plaintext"
+)
+
+// ChromiumMethod returns the decryption method of the chromium ciphertext, the cipher of
+// the platform followed by the version prefix of the ciphertext, eg: AES-GCM-v10.
+func ChromiumMethod(ciphertext []byte) string {
+	if len(ciphertext) < 3 || ciphertext[0] != 'v' {
+		return chromiumCipher
+	}
+	return chromiumCipher + "-" + string(ciphertext[:3])
+}
+
+// HasVersionPrefix reports whether the chromium ciphertext starts with a version, v and two
+// digits, eg: v10, v11 or v20 of the app-bound encryption, the unknown versions included.
+func HasVersionPrefix(ciphertext []byte) bool {
+	return len(ciphertext) >= 3 && ciphertext[0] == 'v' && isDigit(ciphertext[1]) && isDigit(ciphertext[2])
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+// NSSMethod returns the decryption method of the firefox pbe, NSS-3DES or NSS-AES.
+func NSSMethod(pbe ASN1PBE) string {
+	switch alg := PBEAlgorithm(pbe); alg {
+	case PBE3DES:
+		return MethodNSS3DES
+	case PBEAES256:
+		return MethodNSSAES
+	default:
+		return "NSS-" + alg
+	}
+}
+
+// dpapiBlobHeader is the version and the provider guid every DPAPI blob starts with
+var dpapiBlobHeader = []byte{0x01, 0x00, 0x00, 0x00, 0xd0, 0x8c, 0x9d, 0xdf, 0x01, 0x15, 0xd1, 0x11, 0x8c, 0x7a, 0x00, 0xc0, 0x4f, 0xc2, 0x97, 0xeb}
+
+// IsDPAPIBlob reports whether the value is a DPAPI blob, not a value with the v10 or v11 prefix
+func IsDPAPIBlob(value []byte) bool {
+	return bytes.HasPrefix(value, dpapiBlobHeader)
+}
@@ -0,0 +1,37 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChromiumMethod(t *testing.T) {
+	assert.Equal(t, chromiumCipher+"-v10", ChromiumMethod([]byte("v10ciphertext")))
+	assert.Equal(t, chromiumCipher+"-v11", ChromiumMethod([]byte("v11ciphertext")))
+	assert.Equal(t, chromiumCipher, ChromiumMethod([]byte("v1")))
+}
+
+func TestHasVersionPrefix(t *testing.T) {
+	assert.True(t, HasVersionPrefix([]byte("v10ciphertext")))
+	assert.True(t, HasVersionPrefix([]byte("v11ciphertext")))
+	assert.True(t, HasVersionPrefix([]byte("v20ciphertext")))
+	assert.True(t, HasVersionPrefix([]byte("v99")))
+	assert.False(t, HasVersionPrefix([]byte("value")))
+	assert.False(t, HasVersionPrefix([]byte("v2x")))
+	assert.False(t, HasVersionPrefix([]byte("v1")))
+}
+
+func TestNSSMethod(t *testing.T) {
+	var nss nssPBE
+	nss.AlgoAttr.ObjectIdentifier = oidSHA1And3DES
+	assert.Equal(t, MethodNSS3DES, NSSMethod(nss))
+
+	var meta metaPBE
+	meta.AlgoAttr.Data.IVData.ObjectIdentifier = oidAES256CBC
+	assert.Equal(t, MethodNSSAES, NSSMethod(meta))
+
+	var login loginPBE
+	login.Data.ObjectIdentifier = oidAES256CBC
+	assert.Equal(t, MethodNSSAES, NSSMethod(login))
+}
@@ -0,0 +1,40 @@
+package crypto
+
+import (
+	"time"
+
+	"github.com/moond4rk/hackbrowserdata/log"
+)
+
+// DefaultRetries is how often a transient failure of DPAPI or the keychain is retried
+const DefaultRetries = 2
+
+var (
+	retries = DefaultRetries
+	// retryDelay is the delay before the first retry, it doubles for every next one
+	retryDelay = 100 * time.Millisecond
+)
+
+// SetRetries sets how often the transient failures of the os crypto calls are retried, 0 is never
+func SetRetries(n int) {
+	if n < 0 {
+		n = 0
+	}
+	retries = n
+}
+
+// Retry calls fn until it succeeds or fails with an error which isn't transient, the transient
+// failures are retried with backoff at most the configured retries, eg: the credential service
+// is busy. The definitive failures like access denied or the wrong user are returned at once.
+func Retry(name string, fn func() error, transient func(error) bool) error {
+	delay := retryDelay
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= retries || !transient(err) {
+			return err
+		}
+		log.Debugf("%s failed with a transient error, retry in %s: %v", name, delay, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
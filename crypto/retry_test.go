@@ -0,0 +1,45 @@
+package crypto
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	errBusy   = errors.New("busy")
+	errDenied = errors.New("denied")
+)
+
+func TestRetry(t *testing.T) {
+	defer func(d time.Duration, n int) { retryDelay, retries = d, n }(retryDelay, retries)
+	retryDelay = 0
+	isBusy := func(err error) bool { return errors.Is(err, errBusy) }
+
+	calls := 0
+	err := Retry("test", func() error {
+		if calls++; calls < 3 {
+			return errBusy
+		}
+		return nil
+	}, isBusy)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = Retry("test", func() error { calls++; return errDenied }, isBusy)
+	assert.ErrorIs(t, err, errDenied)
+	assert.Equal(t, 1, calls, "a definitive failure isn't retried")
+
+	calls = 0
+	err = Retry("test", func() error { calls++; return errBusy }, isBusy)
+	assert.ErrorIs(t, err, errBusy)
+	assert.Equal(t, DefaultRetries+1, calls)
+
+	SetRetries(-1)
+	calls = 0
+	_ = Retry("test", func() error { calls++; return errBusy }, isBusy)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,204 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/asn1"
+	"fmt"
+)
+
+var selfTestMessage = []byte("hack-browser-data self test")
+
+// SelfTestResult is the result of the round trip of an algorithm
+type SelfTestResult struct {
+	Name string
+	Err  error
+}
+
+// SelfTest encrypts a known plaintext with random keys and decrypts it with every algorithm
+// used to decrypt the browser data on the current platform, DPAPI and the keychain aren't
+// tested as they need the credentials of the user.
+func SelfTest() []SelfTestResult {
+	tests := []struct {
+		name string
+		fn   func() error
+	}{
+		{"chromium", selfTestChromium},
+		{"aes-128-cbc", selfTestAESCBC},
+		{"3des-cbc", selfTestDES3},
+		{"firefox nss 3des", selfTestNSS},
+		{"firefox nss aes", selfTestMeta},
+		{"firefox login 3des", selfTestLogin},
+		{"firefox login aes", selfTestLoginAES},
+	}
+	results := make([]SelfTestResult, 0, len(tests))
+	for _, t := range tests {
+		results = append(results, SelfTestResult{Name: t.name, Err: t.fn()})
+	}
+	return results
+}
+
+func selfTestChromium() error {
+	key, err := randomBytes(chromiumKeySize)
+	if err != nil {
+		return err
+	}
+	encrypted, err := encryptWithChromium(key, selfTestMessage)
+	if err != nil {
+		return err
+	}
+	decrypted, err := DecryptWithChromium(key, encrypted)
+	return checkRoundTrip(decrypted, err)
+}
+
+func selfTestAESCBC() error {
+	key, err := randomBytes(16)
+	if err != nil {
+		return err
+	}
+	iv, err := randomBytes(16)
+	if err != nil {
+		return err
+	}
+	encrypted, err := AESCBCEncrypt(key, iv, selfTestMessage)
+	if err != nil {
+		return err
+	}
+	decrypted, err := AESCBCDecrypt(key, iv, encrypted)
+	return checkRoundTrip(decrypted, err)
+}
+
+func selfTestDES3() error {
+	key, err := randomBytes(24)
+	if err != nil {
+		return err
+	}
+	iv, err := randomBytes(8)
+	if err != nil {
+		return err
+	}
+	encrypted, err := DES3Encrypt(key, iv, selfTestMessage)
+	if err != nil {
+		return err
+	}
+	decrypted, err := DES3Decrypt(key, iv, encrypted)
+	return checkRoundTrip(decrypted, err)
+}
+
+func selfTestNSS() error {
+	var pbe nssPBE
+	pbe.AlgoAttr.ObjectIdentifier = oidSHA1And3DES
+	pbe.AlgoAttr.SaltAttr.Len = 1
+	salt, err := randomBytes(20)
+	if err != nil {
+		return err
+	}
+	pbe.AlgoAttr.SaltAttr.EntrySalt = salt
+	return selfTestPBE(24, func(globalSalt []byte) (interface{}, error) {
+		pbe.Encrypted, err = pbe.Encrypt(globalSalt, selfTestMessage)
+		return pbe, err
+	})
+}
+
+func selfTestMeta() error {
+	var pbe metaPBE
+	pbe.AlgoAttr.ObjectIdentifier = oidPBES2
+	pbe.AlgoAttr.Data.Data.ObjectIdentifier = oidPBKDF2
+	pbe.AlgoAttr.Data.Data.SlatAttr.IterationCount = 1
+	pbe.AlgoAttr.Data.Data.SlatAttr.KeySize = 32
+	pbe.AlgoAttr.Data.Data.SlatAttr.Algorithm.ObjectIdentifier = oidHMACSHA256
+	pbe.AlgoAttr.Data.IVData.ObjectIdentifier = oidAES256CBC
+	salt, err := randomBytes(32)
+	if err != nil {
+		return err
+	}
+	pbe.AlgoAttr.Data.Data.SlatAttr.EntrySalt = salt
+	// the first 2 bytes of the 16 bytes iv are the der header of the 14 bytes octet string
+	if pbe.AlgoAttr.Data.IVData.IV, err = randomBytes(14); err != nil {
+		return err
+	}
+	return selfTestPBE(24, func(globalSalt []byte) (interface{}, error) {
+		pbe.Encrypted, err = pbe.Encrypt(globalSalt, selfTestMessage)
+		return pbe, err
+	})
+}
+
+func selfTestLogin() error {
+	var pbe loginPBE
+	pbe.Data.ObjectIdentifier = oidDESEDE3CBC
+	keyID, err := randomBytes(16)
+	if err != nil {
+		return err
+	}
+	pbe.CipherText = keyID
+	if pbe.Data.IV, err = randomBytes(8); err != nil {
+		return err
+	}
+	return selfTestPBE(24, func(key []byte) (interface{}, error) {
+		pbe.Encrypted, err = pbe.Encrypt(key, selfTestMessage)
+		return pbe, err
+	})
+}
+
+func selfTestLoginAES() error {
+	var pbe loginPBE
+	pbe.Data.ObjectIdentifier = oidAES256CBC
+	keyID, err := randomBytes(16)
+	if err != nil {
+		return err
+	}
+	pbe.CipherText = keyID
+	if pbe.Data.IV, err = randomBytes(16); err != nil {
+		return err
+	}
+	// the logins are aes-256-cbc with the 32 bytes aes master key
+	return selfTestPBE(32, func(key []byte) (interface{}, error) {
+		pbe.Encrypted, err = pbe.Encrypt(key, selfTestMessage)
+		return pbe, err
+	})
+}
+
+// selfTestPBE encodes the pbe built by encrypt as key4.db and logins.json keep it,
+// and decrypts it the way the firefox items do.
+func selfTestPBE(keySize int, encrypt func(globalSalt []byte) (interface{}, error)) error {
+	// the global salt is the key of the login pbe, it's the size of the master key
+	globalSalt, err := randomBytes(keySize)
+	if err != nil {
+		return err
+	}
+	pbe, err := encrypt(globalSalt)
+	if err != nil {
+		return err
+	}
+	raw, err := asn1.Marshal(pbe)
+	if err != nil {
+		return err
+	}
+	decoded, err := NewASN1PBE(raw)
+	if err != nil {
+		return err
+	}
+	if fmt.Sprintf("%T", decoded) != fmt.Sprintf("%T", pbe) {
+		return fmt.Errorf("decoded as %T, want %T", decoded, pbe)
+	}
+	decrypted, err := decoded.Decrypt(globalSalt)
+	return checkRoundTrip(decrypted, err)
+}
+
+func checkRoundTrip(decrypted []byte, err error) error {
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(decrypted, selfTestMessage) {
+		return fmt.Errorf("decrypted %q, want %q", decrypted, selfTestMessage)
+	}
+	return nil
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
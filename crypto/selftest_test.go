@@ -0,0 +1,15 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelfTest(t *testing.T) {
+	results := SelfTest()
+	assert.NotEmpty(t, results)
+	for _, r := range results {
+		assert.NoError(t, r.Err, r.Name)
+	}
+}
@@ -0,0 +1,83 @@
+package extractor
+
+import (
+	"strings"
+)
+
+// domain limits the cookies and passwords to a site, empty means every site
+var domain string
+
+// likeEscaper escapes the metacharacters of a LIKE pattern with the escape character \
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SetDomain limits the cookies and passwords to the domain, eg: github.com, the rows are
+// filtered by the sql query, so only the matching values are decrypted.
+func SetDomain(d string) {
+	domain = strings.ToLower(strings.Trim(strings.TrimSpace(d), "."))
+}
+
+// Domain returns the domain set by SetDomain, empty means every site
+func Domain() string {
+	return domain
+}
+
+// hostPrefixes and hostSuffixes are the boundaries of the host of a url, the domain is matched
+// after :// or the . of a subdomain, and before the path, the port or the end of the url.
+var (
+	hostPrefixes = []string{"://", "."}
+	hostSuffixes = []string{"/", ":", ""}
+)
+
+// FilterHost appends a WHERE clause matching the host column to the domain and its
+// subdomains, eg: .github.com and api.github.com for github.com. It returns the query
+// and its args, the query is returned as is if no domain is set.
+func FilterHost(query, column string) (string, []any) {
+	if domain == "" {
+		return query, nil
+	}
+	return query + " WHERE (" + column + ` = ? OR ` + column + ` LIKE ? ESCAPE '\')`,
+		[]any{domain, "%." + likeEscaper.Replace(domain)}
+}
+
+// FilterURL appends a WHERE clause matching the urls of the column whose host is the domain
+// or its subdomain, eg: https://github.com/login and https://api.github.com for github.com
+// but not https://notgithub.com, the query is returned as is if no domain is set.
+func FilterURL(query, column string) (string, []any) {
+	if domain == "" {
+		return query, nil
+	}
+	conditions, args := []string{column + " = ?"}, []any{domain}
+	escaped := likeEscaper.Replace(domain)
+	for _, prefix := range hostPrefixes {
+		for _, suffix := range hostSuffixes {
+			pattern := "%" + prefix + escaped + suffix
+			if suffix != "" {
+				pattern += "%"
+			}
+			conditions = append(conditions, column+` LIKE ? ESCAPE '\'`)
+			args = append(args, pattern)
+		}
+	}
+	return query + " WHERE (" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// MatchURL reports whether the host of the url is the domain or its subdomain, the same as
+// FilterURL for the items which aren't read with sql query.
+func MatchURL(url string) bool {
+	if domain == "" {
+		return true
+	}
+	url = strings.ToLower(url)
+	if url == domain {
+		return true
+	}
+	for _, prefix := range hostPrefixes {
+		for _, suffix := range hostSuffixes {
+			if suffix == "" && strings.HasSuffix(url, prefix+domain) ||
+				suffix != "" && strings.Contains(url, prefix+domain+suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package extractor
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func queryColumn(t *testing.T, db *sql.DB, query string, args []any) []string {
+	t.Helper()
+	rows, err := db.Query(query, args...)
+	require.NoError(t, err)
+	defer rows.Close()
+	var values []string
+	for rows.Next() {
+		var v string
+		require.NoError(t, rows.Scan(&v))
+		values = append(values, v)
+	}
+	require.NoError(t, rows.Err())
+	return values
+}
+
+func TestFilterDomain(t *testing.T) {
+	defer SetDomain("")
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec(`CREATE TABLE t (v TEXT)`)
+	require.NoError(t, err)
+	for _, v := range []string{
+		"my_site.com", ".my_site.com", "api.my_site.com", "myxsite.com", "notmy_site.com",
+		"https://my_site.com/login", "https://myxsite.com/login", "https://notmy_site.com/login",
+		"https://my_site.com.evil.example/", "https://api.my_site.com:8443", "android://my_site.com.app/",
+	} {
+		_, err = db.Exec(`INSERT INTO t VALUES (?)`, v)
+		require.NoError(t, err)
+	}
+
+	const query = `SELECT v FROM t`
+	q, args := FilterHost(query, "v")
+	assert.Equal(t, query, q)
+	assert.Nil(t, args)
+	assert.True(t, MatchURL("https://example.com"))
+
+	SetDomain(" .My_Site.com ")
+	assert.Equal(t, "my_site.com", Domain())
+	q, args = FilterHost(query, "v")
+	assert.Equal(t, []string{"my_site.com", ".my_site.com", "api.my_site.com"}, queryColumn(t, db, q, args))
+	assert.Equal(t, query+` WHERE (v = ? OR v LIKE ? ESCAPE '\')`, q)
+	q, args = FilterURL(query, "v")
+	assert.True(t, strings.HasPrefix(q, query+" WHERE (") && strings.HasSuffix(q, ")"))
+	matched := queryColumn(t, db, q, args)
+	assert.Equal(t, []string{"my_site.com", ".my_site.com", "api.my_site.com", "https://my_site.com/login", "https://api.my_site.com:8443"}, matched)
+	isMatched := make(map[string]bool)
+	for _, v := range matched {
+		isMatched[v] = true
+	}
+	for _, v := range queryColumn(t, db, query, nil) {
+		assert.Equal(t, isMatched[v], MatchURL(v), v)
+	}
+
+	SetDomain(`100%\`)
+	q, args = FilterURL(query, "v")
+	assert.Empty(t, queryColumn(t, db, q, args))
+	assert.False(t, MatchURL("https://my_site.com/login"))
+}
@@ -2,7 +2,9 @@ package extractor
 
 // Extractor is an interface for extracting data from browser data files
 type Extractor interface {
-	Extract(masterKey []byte) error
+	// Extract extracts the item, the encrypted values are decrypted by the decryptor of the
+	// session and counted in it.
+	Extract(session *Session) error
 
 	Name() string
 
@@ -0,0 +1,5 @@
+package extractor
+
+// KeychainDenied is the value of the passwords which can't be decrypted as the access
+// to the master key in the keychain was denied, their decryptor is crypto.DeniedDecryptor.
+const KeychainDenied = "(keychain access denied)"
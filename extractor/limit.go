@@ -0,0 +1,37 @@
+package extractor
+
+import (
+	"strconv"
+)
+
+// maxRows caps the number of records parsed per item, 0 means no limit
+var maxRows int
+
+// SetMaxRows limits every item to the first n records, n <= 0 disables the limit.
+// The limit applies before sorting, so the records kept are the first ones read
+// from the browser files, not the newest ones.
+func SetMaxRows(n int) {
+	if n < 0 {
+		n = 0
+	}
+	maxRows = n
+}
+
+// MaxRows returns the max rows set by SetMaxRows, 0 means no limit
+func MaxRows() int {
+	return maxRows
+}
+
+// LimitQuery appends a LIMIT clause to the sql query if max rows is set
+func LimitQuery(query string) string {
+	if maxRows == 0 {
+		return query
+	}
+	return query + " LIMIT " + strconv.Itoa(maxRows)
+}
+
+// ReachedMaxRows reports whether n records reach the max rows,
+// it's used by items which aren't read with sql query.
+func ReachedMaxRows(n int) bool {
+	return maxRows > 0 && n >= maxRows
+}
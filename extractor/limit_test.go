@@ -0,0 +1,25 @@
+package extractor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetMaxRows(t *testing.T) {
+	defer SetMaxRows(0)
+
+	const query = `SELECT url FROM urls`
+	assert.Equal(t, query, LimitQuery(query))
+	assert.False(t, ReachedMaxRows(100))
+
+	SetMaxRows(10)
+	assert.Equal(t, 10, MaxRows())
+	assert.Equal(t, query+" LIMIT 10", LimitQuery(query))
+	assert.False(t, ReachedMaxRows(9))
+	assert.True(t, ReachedMaxRows(10))
+
+	SetMaxRows(-1)
+	assert.Equal(t, 0, MaxRows())
+	assert.Equal(t, query, LimitQuery(query))
+}
@@ -0,0 +1,51 @@
+package extractor
+
+import (
+	"runtime"
+	"sync"
+)
+
+// decryptWorkers is the number of goroutines decrypting the values of an item, the
+// decryptor and the counters they share are those of the Session of the item.
+var decryptWorkers = runtime.NumCPU()
+
+// SetDecryptWorkers sets the number of goroutines decrypting the values of an item,
+// n <= 1 decrypts them one by one.
+func SetDecryptWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+	decryptWorkers = n
+}
+
+// DecryptEach calls decrypt for the index of every value in [0, n) with a bounded pool of
+// goroutines, decrypt writes the result of the index, so the order of the records is kept.
+// It returns once every value is decrypted.
+func DecryptEach(n int, decrypt func(i int)) {
+	workers := decryptWorkers
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			decrypt(i)
+		}
+		return
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				decrypt(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
@@ -0,0 +1,63 @@
+package extractor
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/crypto"
+)
+
+func TestDecryptEach(t *testing.T) {
+	defer func(n int) { decryptWorkers = n }(decryptWorkers)
+
+	for _, workers := range []int{0, 1, 4, 64} {
+		SetDecryptWorkers(workers)
+		values := make([]string, 50)
+		DecryptEach(len(values), func(i int) {
+			values[i] = strconv.Itoa(i)
+		})
+		for i, v := range values {
+			assert.Equal(t, strconv.Itoa(i), v, "workers %d", workers)
+		}
+	}
+	DecryptEach(0, func(int) { t.Fatal("no value to decrypt") })
+}
+
+func TestDecryptEach_CountDecrypt(t *testing.T) {
+	defer func(n int) { decryptWorkers = n }(decryptWorkers)
+	SetDecryptWorkers(8)
+	session := NewSession(nil)
+	DecryptEach(1000, func(int) {
+		session.CountDecrypt(nil)
+	})
+	ok, failed := session.DecryptStats()
+	assert.Equal(t, 1000, ok)
+	assert.Zero(t, failed)
+}
+
+// BenchmarkDecryptEach decrypts 10000 values of 1 KiB one by one and with the worker pool
+func BenchmarkDecryptEach(b *testing.B) {
+	defer func(n int) { decryptWorkers = n }(decryptWorkers)
+	key, iv := bytes.Repeat([]byte{1}, 16), bytes.Repeat([]byte{2}, 16)
+	encrypted, err := crypto.AESCBCEncrypt(key, iv, bytes.Repeat([]byte("v"), 1024))
+	require.NoError(b, err)
+	values := make([][]byte, 10000)
+
+	for _, bench := range []struct {
+		name    string
+		workers int
+	}{{"serial", 1}, {"parallel", decryptWorkers}} {
+		b.Run(bench.name, func(b *testing.B) {
+			SetDecryptWorkers(bench.workers)
+			for n := 0; n < b.N; n++ {
+				DecryptEach(len(values), func(i int) {
+					values[i], _ = crypto.AESCBCDecrypt(key, iv, encrypted)
+				})
+			}
+		})
+	}
+}
@@ -4,17 +4,44 @@ import (
 	"github.com/moond4rk/hackbrowserdata/types"
 )
 
-var extractorRegistry = make(map[types.DataType]func() Extractor)
+// Info describes a registered data source for the help of the items
+type Info struct {
+	// Description is what the item holds, eg: the saved logins
+	Description string
+	// Sources are the files the item is read from relative to the profile folder, the moved
+	// ones in the order they are tried and the companion files it also reads, empty is an
+	// item without a file to read yet.
+	Sources []string
+}
+
+type registration struct {
+	info        Info
+	factoryFunc func() Extractor
+}
+
+var extractorRegistry = make(map[types.DataType]registration)
 
 // RegisterExtractor is used to register the data source
-func RegisterExtractor(dataType types.DataType, factoryFunc func() Extractor) {
-	extractorRegistry[dataType] = factoryFunc
+func RegisterExtractor(dataType types.DataType, info Info, factoryFunc func() Extractor) {
+	extractorRegistry[dataType] = registration{info: info, factoryFunc: factoryFunc}
 }
 
 // CreateExtractor is used to create the data source
 func CreateExtractor(dataType types.DataType) Extractor {
-	if factoryFunc, ok := extractorRegistry[dataType]; ok {
-		return factoryFunc()
+	if r, ok := extractorRegistry[dataType]; ok {
+		return r.factoryFunc()
 	}
 	return nil
 }
+
+// ExtractorInfo returns the description of the registered data source, ok is false for the
+// data types without an extractor.
+func ExtractorInfo(dataType types.DataType) (info Info, ok bool) {
+	r, ok := extractorRegistry[dataType]
+	return r.info, ok
+}
+
+// RegisteredTypes returns the data types which have an extractor, in ascending order
+func RegisteredTypes() []types.DataType {
+	return types.SortedKeys(extractorRegistry)
+}
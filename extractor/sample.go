@@ -0,0 +1,27 @@
+package extractor
+
+import (
+	"strings"
+)
+
+// sampleEncrypted limits the rows to the ones with an encrypted value, see SetSampleEncrypted
+var sampleEncrypted bool
+
+// SetSampleEncrypted limits the cookies and passwords read with sql to the rows with an
+// encrypted value, so the first row sampled by --sample-decrypt has a value to decrypt.
+func SetSampleEncrypted(b bool) {
+	sampleEncrypted = b
+}
+
+// FilterEncrypted adds the condition of a non-empty encrypted value of the column to the query
+// of FilterHost or FilterURL, the query is returned as is if the sample isn't set.
+func FilterEncrypted(query, column string) string {
+	if !sampleEncrypted {
+		return query
+	}
+	condition := "length(" + column + ") > 0"
+	if strings.Contains(query, " WHERE (") {
+		return query + " AND " + condition
+	}
+	return query + " WHERE " + condition
+}
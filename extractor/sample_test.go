@@ -0,0 +1,32 @@
+package extractor
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterEncrypted(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec(`CREATE TABLE t (host TEXT, value BLOB)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO t VALUES ('a.example.com', x''), ('b.example.com', x'763130'), ('other.com', x'763130'), ('c.example.com', NULL)`)
+	require.NoError(t, err)
+
+	const query = `SELECT host FROM t`
+	assert.Equal(t, query, FilterEncrypted(query, "value"))
+
+	SetSampleEncrypted(true)
+	defer SetSampleEncrypted(false)
+	assert.Equal(t, []string{"b.example.com", "other.com"}, queryColumn(t, db, FilterEncrypted(query, "value"), nil))
+
+	SetDomain("example.com")
+	defer SetDomain("")
+	q, args := FilterHost(query, "host")
+	assert.Equal(t, []string{"b.example.com"}, queryColumn(t, db, FilterEncrypted(q, "value"), args))
+}
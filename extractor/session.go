@@ -0,0 +1,92 @@
+package extractor
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/moond4rk/hackbrowserdata/crypto"
+)
+
+// Session is the state of extracting an item of a browser profile, the decryptor of the
+// master key of the profile and the counters of the item. Every item gets its own, so the
+// browsers can be extracted at the same time, and the counters are safe for the decrypt
+// workers. The methods of a nil Session are no-op, eg: in the items without encrypted values.
+type Session struct {
+	decryptor crypto.Decryptor
+
+	mu         sync.Mutex
+	decrypted  int
+	failed     int
+	duplicates int
+}
+
+// ErrNoDecryptor is returned for the encrypted values of a session without a decryptor
+var ErrNoDecryptor = errors.New("no decryptor for the encrypted values")
+
+// NewSession returns the session of an item whose values are decrypted by decryptor
+func NewSession(decryptor crypto.Decryptor) *Session {
+	return &Session{decryptor: decryptor}
+}
+
+// Decryptor returns the decryptor of the master key of the profile, every value fails with
+// ErrNoDecryptor if the session has none.
+func (s *Session) Decryptor() crypto.Decryptor {
+	if s == nil || s.decryptor == nil {
+		return noDecryptor{}
+	}
+	return s.decryptor
+}
+
+type noDecryptor struct{}
+
+func (noDecryptor) Decrypt(_ []byte) ([]byte, error) {
+	return nil, ErrNoDecryptor
+}
+
+func (noDecryptor) Method(_ []byte) string {
+	return ""
+}
+
+// CountDecrypt counts the result of decrypting a value, it's called by the items with encrypted values
+func (s *Session) CountDecrypt(err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.failed++
+		return
+	}
+	s.decrypted++
+}
+
+// CountDuplicates counts the records the item dropped as duplicates of another record
+func (s *Session) CountDuplicates(n int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.duplicates += n
+}
+
+// Duplicates returns the number of records dropped as duplicates in the session
+func (s *Session) Duplicates() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.duplicates
+}
+
+// DecryptStats returns the number of values decrypted and failed in the session
+func (s *Session) DecryptStats() (ok, failed int) {
+	if s == nil {
+		return 0, 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.decrypted, s.failed
+}
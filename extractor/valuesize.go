@@ -0,0 +1,102 @@
+package extractor
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultMaxValueSize is the default max size of a cookie or password value, a browser never
+// stores a cookie over 4 KiB, a bigger value is most likely crafted.
+const DefaultMaxValueSize = 64 << 10
+
+// encryptedOverhead is more than the prefix, nonce, tag and padding an encrypted value adds to
+// its plaintext, the DPAPI blobs included.
+const encryptedOverhead = 1 << 10
+
+// maxValueSize caps the size of a cookie or password value, 0 means no limit
+var maxValueSize = DefaultMaxValueSize
+
+// SetMaxValueSize sets the max size in bytes of a cookie or password value, n <= 0 disables the limit
+func SetMaxValueSize(n int) {
+	if n < 0 {
+		n = 0
+	}
+	maxValueSize = n
+}
+
+// ValueColumn returns the sql expression of the plaintext value column cut to the max value
+// size, so a big value is never read in full, its size is selected with SizeColumn.
+func ValueColumn(column string) string {
+	if maxValueSize == 0 {
+		return column
+	}
+	return "substr(CAST(" + column + " AS BLOB), 1, " + strconv.Itoa(maxValueSize) + ")"
+}
+
+// EncryptedColumn returns the sql expression of the encrypted value column, an encrypted value
+// too big to be under the max value size once decrypted is selected empty, it can't be
+// decrypted in part.
+func EncryptedColumn(column string) string {
+	if maxValueSize == 0 {
+		return column
+	}
+	return "CASE WHEN length(CAST(" + column + " AS BLOB)) > " + strconv.Itoa(maxValueSize+encryptedOverhead) + " THEN x'' ELSE " + column + " END"
+}
+
+// SizeColumn returns the sql expression of the size in bytes of the value column, 0 if it's NULL
+func SizeColumn(column string) string {
+	return "COALESCE(length(CAST(" + column + " AS BLOB)), 0)"
+}
+
+// TruncateValue cuts the value to the max value size, size is the size of the whole value,
+// which is bigger than v if it was cut in sql already. The size is recorded in the marker
+// appended to the truncated value, eg: abc (truncated, 5242880 bytes), and the value doesn't
+// end with a part of a multi-byte character.
+func TruncateValue(v string, size int) string {
+	if size < len(v) {
+		size = len(v)
+	}
+	if maxValueSize == 0 || size <= maxValueSize {
+		return v
+	}
+	if len(v) > maxValueSize {
+		v = v[:maxValueSize]
+	}
+	v = trimPartialRune(v)
+	marker := truncatedPrefix + strconv.Itoa(size) + truncatedSuffix
+	if v == "" {
+		return marker
+	}
+	return v + " " + marker
+}
+
+// truncatedPrefix and truncatedSuffix enclose the size in the marker of a truncated value
+const (
+	truncatedPrefix = "(truncated, "
+	truncatedSuffix = " bytes)"
+)
+
+// IsTruncated reports whether the value was cut by TruncateValue, it ends with the marker
+func IsTruncated(v string) bool {
+	i := strings.LastIndex(v, truncatedPrefix)
+	if i < 0 || i > 0 && v[i-1] != ' ' || !strings.HasSuffix(v, truncatedSuffix) {
+		return false
+	}
+	size := strings.TrimSuffix(v[i+len(truncatedPrefix):], truncatedSuffix)
+	_, err := strconv.Atoi(size)
+	return err == nil
+}
+
+// trimPartialRune drops the bytes of the last character if it was cut in the middle
+func trimPartialRune(v string) string {
+	for i := len(v) - 1; i >= 0 && i >= len(v)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(v[i]) {
+			if !utf8.FullRuneInString(v[i:]) {
+				return v[:i]
+			}
+			break
+		}
+	}
+	return v
+}
@@ -0,0 +1,65 @@
+package extractor
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	// import sqlite3 driver
+	_ "modernc.org/sqlite"
+)
+
+func TestTruncateValue(t *testing.T) {
+	defer SetMaxValueSize(DefaultMaxValueSize)
+	SetMaxValueSize(4)
+	assert.Equal(t, "abcd", TruncateValue("abcd", 4))
+	assert.Equal(t, "abcd (truncated, 6 bytes)", TruncateValue("abcdef", 6))
+	// the value was cut in sql already
+	assert.Equal(t, "abcd (truncated, 1024 bytes)", TruncateValue("abcd", 1024))
+	// the encrypted value too big to be decrypted
+	assert.Equal(t, "(truncated, 4096 bytes)", TruncateValue("", 4096))
+	// a multi-byte character isn't cut in the middle
+	assert.Equal(t, "ab (truncated, 8 bytes)", TruncateValue("ab€def", 8))
+	assert.Equal(t, "a€ (truncated, 6 bytes)", TruncateValue("a€de", 6))
+
+	assert.True(t, IsTruncated(TruncateValue("abcdef", 6)))
+	assert.True(t, IsTruncated(TruncateValue("", 4096)))
+	assert.False(t, IsTruncated("abcd"))
+	assert.False(t, IsTruncated("my(truncated, 6 bytes)"))
+	assert.False(t, IsTruncated("(truncated, many bytes)"))
+
+	SetMaxValueSize(0)
+	long := strings.Repeat("x", DefaultMaxValueSize+1)
+	assert.Equal(t, long, TruncateValue(long, len(long)))
+}
+
+func TestValueColumn(t *testing.T) {
+	defer SetMaxValueSize(DefaultMaxValueSize)
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE cookies (value TEXT, encrypted_value BLOB)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO cookies VALUES (?, ?)`, strings.Repeat("€", 1000), make([]byte, 100<<10))
+	require.NoError(t, err)
+
+	SetMaxValueSize(10)
+	var (
+		value, encrypted []byte
+		size, encSize    int
+	)
+	query := "SELECT " + ValueColumn("value") + ", " + SizeColumn("value") + ", " + EncryptedColumn("encrypted_value") + ", " + SizeColumn("encrypted_value") + " FROM cookies"
+	require.NoError(t, db.QueryRow(query).Scan(&value, &size, &encrypted, &encSize))
+	assert.Len(t, value, 10)
+	assert.Equal(t, 3000, size)
+	assert.Empty(t, encrypted)
+	assert.Equal(t, 100<<10, encSize)
+	assert.Equal(t, "€€€ (truncated, 3000 bytes)", TruncateValue(string(value), size))
+
+	SetMaxValueSize(0)
+	require.NoError(t, db.QueryRow("SELECT "+ValueColumn("value")+", "+EncryptedColumn("encrypted_value")+" FROM cookies").Scan(&value, &encrypted))
+	assert.Len(t, value, 3000)
+	assert.Len(t, encrypted, 100<<10)
+}
@@ -13,6 +13,16 @@ func SetVerbose() {
 	defaultLogger.SetLevel(level.DebugLevel)
 }
 
+// SetQuiet only logs the errors, the warnings and the progress of the export are dropped
+func SetQuiet() {
+	defaultLogger.SetLevel(level.ErrorLevel)
+}
+
+// SetLevel sets the minimum level of the package-level functions, the default is WarnLevel
+func SetLevel(v level.Level) {
+	defaultLogger.SetLevel(v)
+}
+
 func Debug(args ...any) {
 	defaultLogger.Debug(args...)
 }
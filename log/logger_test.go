@@ -260,3 +260,22 @@ func TestLoggerWithLowerLevels(t *testing.T) {
 		}
 	}
 }
+
+func TestSetQuiet(t *testing.T) {
+	old := defaultLogger
+	defer func() { defaultLogger = old }()
+	var buf bytes.Buffer
+	defaultLogger = NewLogger(newBase(&buf))
+
+	SetQuiet()
+	Debug("debug")
+	Warnf("export success: %s", "cookie.csv")
+	assert.Empty(t, buf.String())
+	Errorf("decrypt error: %v", "bad key")
+	assert.Contains(t, buf.String(), "ERROR: decrypt error: bad key")
+
+	buf.Reset()
+	SetLevel(level2.DebugLevel)
+	Debug("debug")
+	assert.Contains(t, buf.String(), "DEBUG: debug")
+}
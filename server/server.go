@@ -0,0 +1,194 @@
+// Package server exposes the browser data extraction as a HTTP service with JSON responses,
+// it's used for orchestrating the extraction across many machines.
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/moond4rk/hackbrowserdata/browser"
+	"github.com/moond4rk/hackbrowserdata/browserdata"
+	"github.com/moond4rk/hackbrowserdata/extractor"
+	"github.com/moond4rk/hackbrowserdata/log"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+var ErrEmptyToken = errors.New("server token should not be empty")
+
+// Server serves the endpoints below, every request must carry the shared token
+// with the header "Authorization: Bearer <token>".
+//
+//	GET /browsers                             list the found browsers and their items
+//	GET /extract?browser=<name>&item=<item>   extract the item of the browser as JSON
+type Server struct {
+	token        string
+	isFullExport bool
+	// browserName and profilePath are the -b and -p selection of the browsers
+	browserName string
+	profilePath string
+	pick        func(name, profile string) ([]browser.Browser, error)
+
+	// mu serializes the extractions, items are copied to the same temp files
+	mu sync.Mutex
+}
+
+// New creates a server authenticated by the shared token, it serves the browsers selected by
+// browserName and profilePath, the same as the -b and -p flags.
+func New(token, browserName, profilePath string, isFullExport bool) (*Server, error) {
+	if token == "" {
+		return nil, ErrEmptyToken
+	}
+	return &Server{
+		token:        token,
+		isFullExport: isFullExport,
+		browserName:  browserName,
+		profilePath:  profilePath,
+		pick:         browser.PickBrowsers,
+	}, nil
+}
+
+// Handler returns the http.Handler of the server
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/browsers", s.handleBrowsers)
+	mux.HandleFunc("/extract", s.handleExtract)
+	return s.auth(mux)
+}
+
+// ListenAndServe listens on addr, serves with TLS when both certFile and keyFile are set.
+func (s *Server) ListenAndServe(addr, certFile, keyFile string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if certFile != "" && keyFile != "" {
+		log.Warnf("serve with tls on %s", addr)
+		return srv.ListenAndServeTLS(certFile, keyFile)
+	}
+	log.Warnf("serve without tls on %s, the token and data are sent in plain text", addr)
+	return srv.ListenAndServe()
+}
+
+func (s *Server) auth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("invalid token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// browserInfo is a found browser profile, Items are the names of the items found in it
+type browserInfo struct {
+	Name    string   `json:"name"`
+	Browser string   `json:"browser"`
+	Profile string   `json:"profile"`
+	Items   []string `json:"items"`
+}
+
+func (s *Server) handleBrowsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the browsers are listed from the found files only, nothing is copied or decrypted
+	browsers, err := s.pick(s.browserName, s.profilePath)
+	if errors.Is(err, browser.ErrNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	infos := make([]browserInfo, 0, len(browsers))
+	for _, b := range browsers {
+		name, profile := b.Profile()
+		infos = append(infos, browserInfo{Name: b.Name(), Browser: name, Profile: profile, Items: s.itemNames(b)})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(infos); err != nil {
+		log.Errorf("write browsers response error %v", err)
+	}
+}
+
+func (s *Server) handleExtract(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	name, item := r.URL.Query().Get("browser"), r.URL.Query().Get("item")
+	if name == "" || item == "" {
+		writeError(w, http.StatusBadRequest, errors.New("browser and item are required"))
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	browsers, err := s.pick(s.browserName, s.profilePath)
+	if errors.Is(err, browser.ErrNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	for _, b := range browsers {
+		if b.Name() != name {
+			continue
+		}
+		data, err := b.BrowsingData(s.isFullExport)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := data.WriteItem(w, item, "json"); err != nil {
+			if errors.Is(err, browserdata.ErrItemNotFound) {
+				writeError(w, http.StatusNotFound, err)
+				return
+			}
+			log.Errorf("write extract response error %v", err)
+		}
+		return
+	}
+	writeError(w, http.StatusNotFound, errors.New("browser not found: "+name))
+}
+
+// itemNames returns the names of the items of the browser which can be extracted, only the
+// sensitive ones without the full export, the same as BrowsingData.
+func (s *Server) itemNames(b browser.Browser) []string {
+	items := types.SortedKeys(b.ItemPaths())
+	if !s.isFullExport {
+		items = types.FilterSensitiveItems(items)
+	}
+	names := []string{}
+	seen := make(map[string]bool)
+	for _, item := range items {
+		source := extractor.CreateExtractor(item)
+		if source == nil || seen[source.Name()] {
+			continue
+		}
+		seen[source.Name()] = true
+		names = append(names, source.Name())
+	}
+	return names
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/moond4rk/hackbrowserdata/browser"
+	"github.com/moond4rk/hackbrowserdata/browserdata"
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+type fakeBrowser struct {
+	name string
+	// extracted is set when BrowsingData is called
+	extracted *bool
+}
+
+func (f fakeBrowser) Name() string {
+	return f.name
+}
+
+func (f fakeBrowser) Profile() (string, string) {
+	return "chrome", "Default"
+}
+
+func (f fakeBrowser) ItemPaths() map[types.DataType]string {
+	return map[types.DataType]string{
+		types.ChromiumKey:      "Local State",
+		types.ChromiumPassword: "Login Data",
+		types.ChromiumHistory:  "History",
+	}
+}
+
+func (f fakeBrowser) ProfilePath() string {
+	return ""
+}
+
+func (f fakeBrowser) BrowsingData(_ bool) (*browserdata.BrowserData, error) {
+	if f.extracted != nil {
+		*f.extracted = true
+	}
+	return browserdata.New(nil), nil
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return newServer(t, true, fakeBrowser{name: "chrome_default"})
+}
+
+func newServer(t *testing.T, isFullExport bool, b fakeBrowser) *httptest.Server {
+	t.Helper()
+	s, err := New("secret", "chrome", "Default", isFullExport)
+	require.NoError(t, err)
+	s.pick = func(name, profile string) ([]browser.Browser, error) {
+		assert.Equal(t, "chrome", name)
+		assert.Equal(t, "Default", profile)
+		return []browser.Browser{b}, nil
+	}
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func get(t *testing.T, url, token string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, http.NoBody)
+	require.NoError(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func TestNewEmptyToken(t *testing.T) {
+	_, err := New("", "all", "", true)
+	assert.ErrorIs(t, err, ErrEmptyToken)
+}
+
+func TestServerAuth(t *testing.T) {
+	ts := newTestServer(t)
+	assert.Equal(t, http.StatusUnauthorized, get(t, ts.URL+"/browsers", "").StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, get(t, ts.URL+"/browsers", "wrong").StatusCode)
+
+	// the token without the Bearer scheme is rejected
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/browsers", http.NoBody)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "secret")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestServerBrowsers(t *testing.T) {
+	for _, tc := range []struct {
+		isFullExport bool
+		items        []string
+	}{
+		{isFullExport: true, items: []string{"password", "history"}},
+		{isFullExport: false, items: []string{"password"}},
+	} {
+		var extracted bool
+		ts := newServer(t, tc.isFullExport, fakeBrowser{name: "chrome_default", extracted: &extracted})
+		resp := get(t, ts.URL+"/browsers", "secret")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var infos []browserInfo
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&infos))
+		require.Len(t, infos, 1)
+		assert.Equal(t, "chrome_default", infos[0].Name)
+		assert.Equal(t, "chrome", infos[0].Browser)
+		assert.Equal(t, "Default", infos[0].Profile)
+		assert.Equal(t, tc.items, infos[0].Items)
+		// the browsers are listed without extracting them
+		assert.False(t, extracted)
+	}
+}
+
+func TestServerExtract(t *testing.T) {
+	ts := newTestServer(t)
+	assert.Equal(t, http.StatusBadRequest, get(t, ts.URL+"/extract?browser=chrome_default", "secret").StatusCode)
+	assert.Equal(t, http.StatusNotFound, get(t, ts.URL+"/extract?browser=edge_default&item=password", "secret").StatusCode)
+	assert.Equal(t, http.StatusNotFound, get(t, ts.URL+"/extract?browser=chrome_default&item=password", "secret").StatusCode)
+}
+
+func TestServerBrowsersNotFound(t *testing.T) {
+	s, err := New("secret", "chrome", "", true)
+	require.NoError(t, err)
+	s.pick = func(name, profile string) ([]browser.Browser, error) {
+		return nil, browser.ErrNotFound
+	}
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	assert.Equal(t, http.StatusNotFound, get(t, ts.URL+"/browsers", "secret").StatusCode)
+}
@@ -0,0 +1,98 @@
+package types
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// FileFormat is the format of the item file, it's used to verify the copied file
+type FileFormat int
+
+const (
+	FormatUnknown FileFormat = iota
+	FormatSQLite
+	FormatJSON
+)
+
+var (
+	ErrNotSQLite       = errors.New("file is not a sqlite database")
+	ErrTruncatedSQLite = errors.New("sqlite database is truncated")
+	ErrInvalidJSON     = errors.New("file is not valid json")
+)
+
+var itemFormats = map[DataType]FileFormat{
+	ChromiumKey:               FormatJSON,
+	ChromiumPassword:          FormatSQLite,
+	ChromiumCookie:            FormatSQLite,
+	ChromiumBookmark:          FormatJSON,
+	ChromiumHistory:           FormatSQLite,
+	ChromiumDownload:          FormatSQLite,
+	ChromiumCreditCard:        FormatSQLite,
+	ChromiumExtension:         FormatJSON,
+	ChromiumSiteEngagement:    FormatJSON,
+	ChromiumStorageQuota:      FormatSQLite,
+	ChromiumMostVisited:       FormatSQLite,
+	ChromiumPrivacySandbox:    FormatSQLite,
+	ChromiumWebApp:            FormatJSON,
+	ChromiumExtensionCookie:   FormatSQLite,
+	ChromiumNetworkState:      FormatJSON,
+	ChromiumTransportSecurity: FormatJSON,
+	ChromiumArchivedHistory:   FormatSQLite,
+	ChromiumSafeBrowsing:      FormatSQLite,
+	ChromiumSecurePreferences: FormatJSON,
+	ChromiumSettings:          FormatJSON,
+	ChromiumAffiliation:       FormatSQLite,
+	YandexPassword:            FormatSQLite,
+	YandexCreditCard:          FormatSQLite,
+	BraveRewards:              FormatJSON,
+	FirefoxKey4:               FormatSQLite,
+	FirefoxPassword:           FormatJSON,
+	FirefoxPasswordBackup:     FormatJSON,
+	FirefoxContainer:          FormatJSON,
+	FirefoxInputHistory:       FormatSQLite,
+	FirefoxCookieContainer:    FormatJSON,
+	FirefoxCookie:             FormatSQLite,
+	FirefoxBookmark:           FormatSQLite,
+	FirefoxHistory:            FormatSQLite,
+	FirefoxDownload:           FormatSQLite,
+	FirefoxLocalStorage:       FormatSQLite,
+	FirefoxExtension:          FormatJSON,
+}
+
+// Format returns the format of the item file, FormatUnknown for folders and other files
+func (i DataType) Format() FileFormat {
+	return itemFormats[i]
+}
+
+// sqliteHeader is the first 16 bytes of every sqlite database
+// @https://www.sqlite.org/fileformat.html#the_database_header
+const sqliteHeader = "SQLite format 3\x00"
+
+// Verify checks the data is a complete file of the format, a file copied
+// while the browser is writing it may be truncated or half written.
+func (f FileFormat) Verify(data []byte) error {
+	switch f {
+	case FormatSQLite:
+		// an empty file is an empty database for sqlite
+		if len(data) == 0 {
+			return nil
+		}
+		if len(data) < 100 || string(data[:len(sqliteHeader)]) != sqliteHeader {
+			return ErrNotSQLite
+		}
+		pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+		if pageSize == 1 {
+			pageSize = 65536
+		}
+		if pageSize < 512 || len(data)%pageSize != 0 {
+			return fmt.Errorf("%w: size %d, page size %d", ErrTruncatedSQLite, len(data), pageSize)
+		}
+	case FormatJSON:
+		if !json.Valid(data) {
+			return ErrInvalidJSON
+		}
+	}
+	return nil
+}
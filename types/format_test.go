@@ -0,0 +1,35 @@
+package types
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sqliteFile(pageSize, pages int) []byte {
+	data := make([]byte, pageSize*pages)
+	copy(data, sqliteHeader)
+	binary.BigEndian.PutUint16(data[16:], uint16(pageSize))
+	return data
+}
+
+func TestFileFormat_Verify(t *testing.T) {
+	db := sqliteFile(4096, 3)
+	assert.NoError(t, FormatSQLite.Verify(db))
+	assert.NoError(t, FormatSQLite.Verify(nil))
+	assert.ErrorIs(t, FormatSQLite.Verify(db[:4096*2+100]), ErrTruncatedSQLite)
+	assert.ErrorIs(t, FormatSQLite.Verify([]byte(`{"roots":{}}`)), ErrNotSQLite)
+
+	assert.NoError(t, FormatJSON.Verify([]byte(`{"roots":{}}`)))
+	assert.ErrorIs(t, FormatJSON.Verify([]byte(`{"roots":`)), ErrInvalidJSON)
+
+	assert.NoError(t, FormatUnknown.Verify([]byte("anything")))
+}
+
+func TestDataType_Format(t *testing.T) {
+	assert.Equal(t, FormatSQLite, ChromiumCookie.Format())
+	assert.Equal(t, FormatJSON, ChromiumBookmark.Format())
+	assert.Equal(t, FormatJSON, FirefoxPassword.Format())
+	assert.Equal(t, FormatUnknown, ChromiumLocalStorage.Format())
+}
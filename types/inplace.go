@@ -0,0 +1,85 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// inPlace reads the items from the browser files directly instead of their copies in the temp dir,
+// it's made for read-only forensic images, the files must not be locked by a running browser.
+var inPlace bool
+
+// sourcePaths are the browser files of the items read in place
+var sourcePaths = make(map[DataType]string)
+
+// SetInPlace enables reading the items in place, the files are opened read-only and
+// never copied or removed.
+func SetInPlace(b bool) {
+	inPlace = b
+}
+
+// InPlace returns whether the items are read in place
+func InPlace() bool {
+	return inPlace
+}
+
+// SetSourcePaths sets the browser files read in place, it replaces the paths of the previous browser.
+func SetSourcePaths(paths map[DataType]string) {
+	sourcePaths = make(map[DataType]string, len(paths))
+	for i, p := range paths {
+		sourcePaths[i] = p
+	}
+}
+
+// sourcePath returns the browser file of the item when it's read in place
+func (i DataType) sourcePath() (string, bool) {
+	if !inPlace {
+		return "", false
+	}
+	p, ok := sourcePaths[i]
+	return p, ok
+}
+
+// dirWritable reports whether the folder of a browser file is writable, it's replaced by the tests
+var dirWritable = isDirWritable
+
+// DSN returns the sqlite data source name of the item, the browser file is opened read-only in place.
+// A wal database opened read-only needs its -shm file, which can't be created on a read-only
+// mount, so the file is opened immutable there when it has no -wal file to replay.
+func (i DataType) DSN() string {
+	p, ok := i.sourcePath()
+	if !ok {
+		return i.TempFilename()
+	}
+	// only %, ? and # are special in the path of a sqlite uri
+	escape := strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23")
+	dsn := "file:" + escape.Replace(filepath.ToSlash(p)) + "?mode=ro"
+	if _, err := os.Stat(p + WALSuffix); os.IsNotExist(err) && !dirWritable(filepath.Dir(p)) {
+		dsn += "&immutable=1"
+	}
+	return dsn
+}
+
+// Exists reports whether the file of the item was copied, or is read in place, the optional
+// files of an item are skipped when they don't exist.
+func (i DataType) Exists() bool {
+	p, ok := i.sourcePath()
+	if !ok {
+		p = i.TempFilename()
+	}
+	_, err := os.Stat(p)
+	return err == nil
+}
+
+// RemoveTemp removes the temp file or folder of the item, its backup file and the sqlite wal files,
+// nothing is removed when the item is read in place.
+func (i DataType) RemoveTemp() {
+	if _, ok := i.sourcePath(); ok {
+		return
+	}
+	_ = os.RemoveAll(i.TempFilename())
+	_ = os.RemoveAll(i.TempBackupFilename())
+	_ = os.RemoveAll(i.TempWALFilename())
+	_ = os.RemoveAll(i.TempFilename() + "-shm")
+}
@@ -0,0 +1,55 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInPlace(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "Cookies")
+	require.NoError(t, os.WriteFile(source, []byte("data"), 0o600))
+	t.Cleanup(func() {
+		SetInPlace(false)
+		SetSourcePaths(nil)
+	})
+
+	SetSourcePaths(map[DataType]string{ChromiumCookie: source})
+	assert.NotEqual(t, source, ChromiumCookie.TempFilename(), "source paths are ignored unless in place")
+
+	SetInPlace(true)
+	assert.Equal(t, source, ChromiumCookie.TempFilename())
+	assert.Equal(t, "file:"+filepath.ToSlash(source)+"?mode=ro", ChromiumCookie.DSN())
+	ChromiumCookie.RemoveTemp()
+	assert.FileExists(t, source)
+
+	// the items of the browser without a source path still use the temp files
+	assert.Equal(t, ChromiumHistory.TempFilename(), ChromiumHistory.DSN())
+
+	SetSourcePaths(map[DataType]string{ChromiumCookie: filepath.Join(dir, "a?b#c%d")})
+	assert.Equal(t, "file:"+filepath.ToSlash(dir)+"/a%3fb%23c%25d?mode=ro", ChromiumCookie.DSN())
+}
+
+func TestInPlace_DSNReadOnlyMount(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "Cookies")
+	require.NoError(t, os.WriteFile(source, []byte("data"), 0o600))
+	SetInPlace(true)
+	SetSourcePaths(map[DataType]string{ChromiumCookie: source})
+	dirWritable = func(string) bool { return false }
+	t.Cleanup(func() {
+		SetInPlace(false)
+		SetSourcePaths(nil)
+		dirWritable = isDirWritable
+	})
+
+	assert.Equal(t, "file:"+filepath.ToSlash(source)+"?mode=ro&immutable=1", ChromiumCookie.DSN())
+
+	// the -wal file would be ignored by an immutable database
+	require.NoError(t, os.WriteFile(source+WALSuffix, nil, 0o600))
+	assert.Equal(t, "file:"+filepath.ToSlash(source)+"?mode=ro", ChromiumCookie.DSN())
+}
@@ -0,0 +1,43 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// movedProfileFiles are the files of the items relative to the profile folder which moved or
+// have a fallback, the first existing one is used, eg: Chrome 96 moved Cookies to
+// Network/Cookies, Firefox 114 moved SiteSecurityServiceState.txt to SiteSecurityServiceState.bin,
+// and the firefox passwords are read from logins-backup.json when logins.json is missing.
+var movedProfileFiles = map[DataType][]string{
+	ChromiumCookie:            {"Network/Cookies", fileChromiumCookie},
+	ChromiumNetworkState:      {"Network/Network Persistent State", fileChromiumNetworkState},
+	ChromiumTransportSecurity: {"Network/TransportSecurity", fileChromiumTransportSecurity},
+	FirefoxNetworkState:       {fileFirefoxNetworkState, "SiteSecurityServiceState.txt"},
+	FirefoxPassword:           {fileFirefoxPassword, fileFirefoxPasswordBackup},
+}
+
+// ProfileFiles returns the files of the item relative to the profile folder with slashes, in
+// the order they're looked up, eg: Network/Cookies then Cookies.
+func (i DataType) ProfileFiles() []string {
+	if files, ok := movedProfileFiles[i]; ok {
+		return append([]string(nil), files...)
+	}
+	return []string{i.Filename()}
+}
+
+// ResolveItemPaths returns the absolute path of every item in the profile folder, the first
+// existing file or folder of ProfileFiles is used, the items without one are not returned.
+func ResolveItemPaths(profileDir string, items []DataType) map[DataType]string {
+	paths := make(map[DataType]string)
+	for _, item := range items {
+		for _, name := range item.ProfileFiles() {
+			p := filepath.Join(profileDir, filepath.FromSlash(name))
+			if _, err := os.Stat(p); err == nil {
+				paths[item] = p
+				break
+			}
+		}
+	}
+	return paths
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 )
 
 type DataType int
@@ -33,31 +36,84 @@ const (
 	FirefoxLocalStorage
 	FirefoxSessionStorage
 	FirefoxExtension
+
+	// the data types below are appended in the order they were added, so the values of the
+	// existing ones don't change
+	ChromiumSessions
+	ChromiumSiteEngagement
+	ChromiumPushSubscription
+	ChromiumStorageQuota
+	ChromiumMostVisited
+	ChromiumPrivacySandbox
+	FirefoxContainer
+	ChromiumWebApp
+	FirefoxWebApp
+	BraveRewards
+	ChromiumSyncData
+	FirefoxSyncData
+	ChromiumExtensionCookie
+	ChromiumReadingList
+	ChromiumNetworkState
+	ChromiumTransportSecurity
+	FirefoxNetworkState
+	ChromiumArchivedHistory
+	FirefoxPasswordBackup
+	ChromiumSafeBrowsing
+	ChromiumSecurePreferences
+	ChromiumSettings
+	FirefoxInputHistory
+	ChromiumAffiliation
+	FirefoxCookieContainer
 )
 
 var itemFileNames = map[DataType]string{
-	ChromiumKey:            fileChromiumKey,
-	ChromiumPassword:       fileChromiumPassword,
-	ChromiumCookie:         fileChromiumCookie,
-	ChromiumBookmark:       fileChromiumBookmark,
-	ChromiumDownload:       fileChromiumDownload,
-	ChromiumLocalStorage:   fileChromiumLocalStorage,
-	ChromiumSessionStorage: fileChromiumSessionStorage,
-	ChromiumCreditCard:     fileChromiumCredit,
-	ChromiumExtension:      fileChromiumExtension,
-	ChromiumHistory:        fileChromiumHistory,
-	YandexPassword:         fileYandexPassword,
-	YandexCreditCard:       fileYandexCredit,
-	FirefoxKey4:            fileFirefoxKey4,
-	FirefoxPassword:        fileFirefoxPassword,
-	FirefoxCookie:          fileFirefoxCookie,
-	FirefoxBookmark:        fileFirefoxData,
-	FirefoxDownload:        fileFirefoxData,
-	FirefoxLocalStorage:    fileFirefoxLocalStorage,
-	FirefoxHistory:         fileFirefoxData,
-	FirefoxExtension:       fileFirefoxExtension,
-	FirefoxSessionStorage:  UnsupportedItem,
-	FirefoxCreditCard:      UnsupportedItem,
+	ChromiumKey:               fileChromiumKey,
+	ChromiumPassword:          fileChromiumPassword,
+	ChromiumCookie:            fileChromiumCookie,
+	ChromiumBookmark:          fileChromiumBookmark,
+	ChromiumDownload:          fileChromiumDownload,
+	ChromiumLocalStorage:      fileChromiumLocalStorage,
+	ChromiumSessionStorage:    fileChromiumSessionStorage,
+	ChromiumCreditCard:        fileChromiumCredit,
+	ChromiumExtension:         fileChromiumExtension,
+	ChromiumHistory:           fileChromiumHistory,
+	ChromiumSessions:          fileChromiumSessions,
+	ChromiumSiteEngagement:    fileChromiumPreferences,
+	ChromiumPushSubscription:  fileChromiumGCMStore,
+	ChromiumStorageQuota:      fileChromiumQuotaManager,
+	ChromiumMostVisited:       fileChromiumHistory,
+	ChromiumPrivacySandbox:    fileChromiumConversions,
+	ChromiumWebApp:            fileChromiumPreferences,
+	ChromiumSyncData:          fileChromiumSyncData,
+	ChromiumExtensionCookie:   fileChromiumExtensionCookie,
+	ChromiumReadingList:       fileChromiumSyncData,
+	ChromiumNetworkState:      fileChromiumNetworkState,
+	ChromiumTransportSecurity: fileChromiumTransportSecurity,
+	ChromiumArchivedHistory:   fileChromiumArchivedHistory,
+	ChromiumSafeBrowsing:      fileChromiumHistory,
+	ChromiumSecurePreferences: fileChromiumSecurePreferences,
+	ChromiumSettings:          fileChromiumPreferences,
+	ChromiumAffiliation:       fileChromiumAffiliation,
+	YandexPassword:            fileYandexPassword,
+	YandexCreditCard:          fileYandexCredit,
+	BraveRewards:              fileChromiumPreferences,
+	FirefoxKey4:               fileFirefoxKey4,
+	FirefoxPassword:           fileFirefoxPassword,
+	FirefoxCookie:             fileFirefoxCookie,
+	FirefoxBookmark:           fileFirefoxData,
+	FirefoxDownload:           fileFirefoxData,
+	FirefoxLocalStorage:       fileFirefoxLocalStorage,
+	FirefoxHistory:            fileFirefoxData,
+	FirefoxExtension:          fileFirefoxExtension,
+	FirefoxSessionStorage:     UnsupportedItem,
+	FirefoxCreditCard:         UnsupportedItem,
+	FirefoxContainer:          fileFirefoxContainers,
+	FirefoxWebApp:             UnsupportedItem,
+	FirefoxSyncData:           UnsupportedItem,
+	FirefoxNetworkState:       fileFirefoxNetworkState,
+	FirefoxPasswordBackup:     fileFirefoxPasswordBackup,
+	FirefoxInputHistory:       fileFirefoxData,
+	FirefoxCookieContainer:    fileFirefoxContainers,
 }
 
 func (i DataType) String() string {
@@ -82,10 +138,46 @@ func (i DataType) String() string {
 		return "ChromiumSessionStorage"
 	case ChromiumExtension:
 		return "ChromiumExtension"
+	case ChromiumSessions:
+		return "ChromiumSessions"
+	case ChromiumSiteEngagement:
+		return "ChromiumSiteEngagement"
+	case ChromiumPushSubscription:
+		return "ChromiumPushSubscription"
+	case ChromiumStorageQuota:
+		return "ChromiumStorageQuota"
+	case ChromiumMostVisited:
+		return "ChromiumMostVisited"
+	case ChromiumPrivacySandbox:
+		return "ChromiumPrivacySandbox"
+	case ChromiumWebApp:
+		return "ChromiumWebApp"
+	case ChromiumSyncData:
+		return "ChromiumSyncData"
+	case ChromiumExtensionCookie:
+		return "ChromiumExtensionCookie"
+	case ChromiumReadingList:
+		return "ChromiumReadingList"
+	case ChromiumNetworkState:
+		return "ChromiumNetworkState"
+	case ChromiumTransportSecurity:
+		return "ChromiumTransportSecurity"
+	case ChromiumArchivedHistory:
+		return "ChromiumArchivedHistory"
+	case ChromiumSafeBrowsing:
+		return "ChromiumSafeBrowsing"
+	case ChromiumSecurePreferences:
+		return "ChromiumSecurePreferences"
+	case ChromiumSettings:
+		return "ChromiumSettings"
+	case ChromiumAffiliation:
+		return "ChromiumAffiliation"
 	case YandexPassword:
 		return "YandexPassword"
 	case YandexCreditCard:
 		return "YandexCreditCard"
+	case BraveRewards:
+		return "BraveRewards"
 	case FirefoxKey4:
 		return "FirefoxKey4"
 	case FirefoxPassword:
@@ -106,6 +198,20 @@ func (i DataType) String() string {
 		return "FirefoxSessionStorage"
 	case FirefoxExtension:
 		return "FirefoxExtension"
+	case FirefoxContainer:
+		return "FirefoxContainer"
+	case FirefoxWebApp:
+		return "FirefoxWebApp"
+	case FirefoxSyncData:
+		return "FirefoxSyncData"
+	case FirefoxNetworkState:
+		return "FirefoxNetworkState"
+	case FirefoxPasswordBackup:
+		return "FirefoxPasswordBackup"
+	case FirefoxInputHistory:
+		return "FirefoxInputHistory"
+	case FirefoxCookieContainer:
+		return "FirefoxCookieContainer"
 	default:
 		return "UnsupportedItem"
 	}
@@ -120,20 +226,129 @@ func (i DataType) Filename() string {
 	return UnsupportedItem
 }
 
-// TempFilename returns the temp filename for the item with suffix
-// eg: chromiumKey_0.temp
+// tempDir is the folder where items are copied to before parsing
+var tempDir = os.TempDir()
+
+// SetTempDir sets the folder where items are copied to, it's created if not exists.
+// The default is os.TempDir(), so the source and the current dir can be read-only.
+func SetTempDir(dir string) error {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	tempDir = dir
+	return nil
+}
+
+// TempDir returns the folder where items are copied to
+func TempDir() string {
+	return tempDir
+}
+
+// sessionDir is the folder of the run created in the temp dir, empty if not created
+var sessionDir string
+
+// CreateSessionDir creates a folder of the run in the temp dir and copies the items to it,
+// so concurrent runs don't share the temp files and nothing is left behind by a failed item.
+func CreateSessionDir() (string, error) {
+	if sessionDir != "" {
+		return sessionDir, nil
+	}
+	dir, err := os.MkdirTemp(tempDir, "hack-browser-data-")
+	if err != nil {
+		return "", err
+	}
+	sessionDir, tempDir = dir, dir
+	return dir, nil
+}
+
+// RemoveSessionDir removes the folder of the run with every temp file left in it,
+// the items are copied to the temp dir again.
+func RemoveSessionDir() error {
+	if sessionDir == "" {
+		return nil
+	}
+	err := os.RemoveAll(sessionDir)
+	tempDir = filepath.Dir(sessionDir)
+	sessionDir = ""
+	return err
+}
+
+// profileDir is the folder of the profile being extracted in the temp dir, see CreateProfileDir
+var (
+	profileDir string
+	profileMu  sync.Mutex
+)
+
+// CreateProfileDir creates a folder with a unique name in the temp dir for the items of the
+// browser profile, they're copied to it until RemoveProfileDir, so the profiles and the runs
+// sharing the temp dir don't overwrite each other's files. The profiles of the process are
+// extracted one by one, the next CreateProfileDir waits for RemoveProfileDir.
+func CreateProfileDir(browser, profile string) (string, error) {
+	profileMu.Lock()
+	name := strings.NewReplacer("/", "_", "\\", "_").Replace(browser + "-" + profile + "-")
+	dir, err := os.MkdirTemp(tempDir, name)
+	if err != nil {
+		profileMu.Unlock()
+		return "", err
+	}
+	profileDir = dir
+	return dir, nil
+}
+
+// RemoveProfileDir removes the folder of the profile created by CreateProfileDir with every
+// temp file left in it.
+func RemoveProfileDir() error {
+	if profileDir == "" {
+		return nil
+	}
+	defer profileMu.Unlock()
+	err := os.RemoveAll(profileDir)
+	profileDir = ""
+	return err
+}
+
+// TempFilename returns the temp filename for the item with suffix, in the folder of the
+// profile being extracted if any, eg: chromiumKey_0.temp, it's the browser file when the item
+// is read in place.
 func (i DataType) TempFilename() string {
+	if p, ok := i.sourcePath(); ok {
+		return p
+	}
 	const tempSuffix = "temp"
 	tempFile := fmt.Sprintf("%s_%d.%s", i.Filename(), i, tempSuffix)
-	return filepath.Join(os.TempDir(), tempFile)
+	if profileDir != "" {
+		return filepath.Join(profileDir, tempFile)
+	}
+	return filepath.Join(tempDir, tempFile)
+}
+
+// BackupSuffix is the suffix of the backup file kept by the browser, eg: Bookmarks.bak
+const BackupSuffix = ".bak"
+
+// TempBackupFilename returns the temp filename for the backup file of the item
+func (i DataType) TempBackupFilename() string {
+	return i.TempFilename() + BackupSuffix
+}
+
+// WALSuffix is the suffix of the write-ahead log of a sqlite database, the rows written
+// since the last checkpoint are only in it while the browser is running.
+const WALSuffix = "-wal"
+
+// TempWALFilename returns the temp filename for the write-ahead log of the item,
+// sqlite reads it next to the temp file of the database.
+func (i DataType) TempWALFilename() string {
+	return i.TempFilename() + WALSuffix
 }
 
 // IsSensitive returns whether the item is sensitive data
 // password, cookie, credit card, master key is unlimited
 func (i DataType) IsSensitive() bool {
 	switch i {
-	case ChromiumKey, ChromiumCookie, ChromiumPassword, ChromiumCreditCard,
-		FirefoxKey4, FirefoxPassword, FirefoxCookie, FirefoxCreditCard,
+	case ChromiumKey, ChromiumCookie, ChromiumPassword, ChromiumCreditCard, ChromiumExtensionCookie,
+		FirefoxKey4, FirefoxPassword, FirefoxPasswordBackup, FirefoxCookie, FirefoxCreditCard,
 		YandexPassword, YandexCreditCard:
 		return true
 	default:
@@ -141,6 +356,18 @@ func (i DataType) IsSensitive() bool {
 	}
 }
 
+// SortedKeys returns the data types of the map in ascending order
+func SortedKeys[V any](m map[DataType]V) []DataType {
+	keys := make([]DataType, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
 // FilterSensitiveItems returns the sensitive items
 func FilterSensitiveItems(items []DataType) []DataType {
 	var filtered []DataType
@@ -164,6 +391,13 @@ var DefaultFirefoxTypes = []DataType{
 	FirefoxLocalStorage,
 	FirefoxSessionStorage,
 	FirefoxExtension,
+	FirefoxContainer,
+	FirefoxWebApp,
+	FirefoxSyncData,
+	FirefoxNetworkState,
+	FirefoxPasswordBackup,
+	FirefoxInputHistory,
+	FirefoxCookieContainer,
 }
 
 // DefaultYandexTypes returns the default items for the yandex browser
@@ -178,6 +412,23 @@ var DefaultYandexTypes = []DataType{
 	ChromiumLocalStorage,
 	ChromiumSessionStorage,
 	YandexCreditCard,
+	ChromiumSessions,
+	ChromiumSiteEngagement,
+	ChromiumPushSubscription,
+	ChromiumStorageQuota,
+	ChromiumMostVisited,
+	ChromiumPrivacySandbox,
+	ChromiumWebApp,
+	ChromiumSyncData,
+	ChromiumExtensionCookie,
+	ChromiumReadingList,
+	ChromiumNetworkState,
+	ChromiumTransportSecurity,
+	ChromiumArchivedHistory,
+	ChromiumSafeBrowsing,
+	ChromiumSecurePreferences,
+	ChromiumSettings,
+	ChromiumAffiliation,
 }
 
 // DefaultChromiumTypes returns the default items for the chromium browser
@@ -192,30 +443,65 @@ var DefaultChromiumTypes = []DataType{
 	ChromiumLocalStorage,
 	ChromiumSessionStorage,
 	ChromiumExtension,
+	ChromiumSessions,
+	ChromiumSiteEngagement,
+	ChromiumPushSubscription,
+	ChromiumStorageQuota,
+	ChromiumMostVisited,
+	ChromiumPrivacySandbox,
+	ChromiumWebApp,
+	ChromiumSyncData,
+	ChromiumExtensionCookie,
+	ChromiumReadingList,
+	ChromiumNetworkState,
+	ChromiumTransportSecurity,
+	ChromiumArchivedHistory,
+	ChromiumSafeBrowsing,
+	ChromiumSecurePreferences,
+	ChromiumSettings,
+	ChromiumAffiliation,
 }
 
+// DefaultBraveTypes returns the default items for the brave browser, the chromium items and the rewards
+var DefaultBraveTypes = append(append([]DataType{}, DefaultChromiumTypes...), BraveRewards)
+
 // item's default filename
 const (
-	fileChromiumKey            = "Local State"
-	fileChromiumCredit         = "Web Data"
-	fileChromiumPassword       = "Login Data"
-	fileChromiumHistory        = "History"
-	fileChromiumDownload       = "History"
-	fileChromiumCookie         = "Cookies"
-	fileChromiumBookmark       = "Bookmarks"
-	fileChromiumLocalStorage   = "Local Storage/leveldb"
-	fileChromiumSessionStorage = "Session Storage"
-	fileChromiumExtension      = "Secure Preferences" // TODO: add more extension files and folders, eg: Preferences
+	fileChromiumKey               = "Local State"
+	fileChromiumCredit            = "Web Data"
+	fileChromiumPassword          = "Login Data"
+	fileChromiumHistory           = "History"
+	fileChromiumDownload          = "History"
+	fileChromiumCookie            = "Cookies"
+	fileChromiumBookmark          = "Bookmarks"
+	fileChromiumLocalStorage      = "Local Storage/leveldb"
+	fileChromiumSessionStorage    = "Session Storage"
+	fileChromiumExtension         = "Secure Preferences" // TODO: add more extension files and folders, eg: Preferences
+	fileChromiumSessions          = "Sessions"
+	fileChromiumPreferences       = "Preferences"
+	fileChromiumGCMStore          = "GCM Store"
+	fileChromiumQuotaManager      = "QuotaManager"
+	fileChromiumConversions       = "Conversions"
+	fileChromiumSyncData          = "Sync Data"
+	fileChromiumExtensionCookie   = "Extension Cookies"
+	fileChromiumNetworkState      = "Network Persistent State"
+	fileChromiumTransportSecurity = "TransportSecurity"
+	fileChromiumArchivedHistory   = "Archived History"
+	fileChromiumSecurePreferences = "Secure Preferences"
+	fileChromiumAffiliation       = "Affiliation Database"
 
 	fileYandexPassword = "Ya Passman Data"
 	fileYandexCredit   = "Ya Credit Cards"
 
-	fileFirefoxKey4         = "key4.db"
-	fileFirefoxCookie       = "cookies.sqlite"
-	fileFirefoxPassword     = "logins.json"
-	fileFirefoxData         = "places.sqlite"
-	fileFirefoxLocalStorage = "webappsstore.sqlite"
-	fileFirefoxExtension    = "extensions.json"
+	fileFirefoxKey4           = "key4.db"
+	fileFirefoxCookie         = "cookies.sqlite"
+	fileFirefoxPassword       = "logins.json"
+	fileFirefoxData           = "places.sqlite"
+	fileFirefoxLocalStorage   = "webappsstore.sqlite"
+	fileFirefoxExtension      = "extensions.json"
+	fileFirefoxContainers     = "containers.json"
+	fileFirefoxNetworkState   = "SiteSecurityServiceState.bin"
+	fileFirefoxPasswordBackup = "logins-backup.json"
 
 	UnsupportedItem = "unsupported item"
 )
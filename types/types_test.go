@@ -3,10 +3,13 @@ package types
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDataType_FileName(t *testing.T) {
@@ -19,6 +22,9 @@ func TestDataType_FileName(t *testing.T) {
 	for _, item := range DefaultYandexTypes {
 		assert.Equal(t, item.Filename(), item.filename())
 	}
+	for _, item := range DefaultBraveTypes {
+		assert.Equal(t, item.Filename(), item.filename())
+	}
 }
 
 func TestDataType_TempFilename(t *testing.T) {
@@ -45,6 +51,64 @@ func TestDataType_TempFilename(t *testing.T) {
 	}
 }
 
+func TestSetTempDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "copies")
+	assert.NoError(t, SetTempDir(dir))
+	defer func() { _ = SetTempDir("") }()
+
+	assert.Equal(t, dir, TempDir())
+	assert.DirExists(t, dir)
+	assert.Equal(t, filepath.Join(dir, "Login Data_1.temp"), ChromiumPassword.TempFilename())
+}
+
+func TestSessionDir(t *testing.T) {
+	base := t.TempDir()
+	assert.NoError(t, SetTempDir(base))
+	defer func() { _ = SetTempDir("") }()
+
+	dir, err := CreateSessionDir()
+	require.NoError(t, err)
+	assert.Equal(t, base, filepath.Dir(dir))
+	assert.Equal(t, dir, TempDir())
+	again, err := CreateSessionDir()
+	require.NoError(t, err)
+	assert.Equal(t, dir, again)
+
+	// a temp file whose item was never removed
+	require.NoError(t, os.WriteFile(ChromiumCookie.TempFilename(), []byte("cookies"), 0o600))
+	assert.NoError(t, RemoveSessionDir())
+	assert.NoDirExists(t, dir)
+	assert.Equal(t, base, TempDir())
+	assert.NoError(t, RemoveSessionDir())
+}
+
+func TestProfileDir(t *testing.T) {
+	base := t.TempDir()
+	assert.NoError(t, SetTempDir(base))
+	defer func() { _ = SetTempDir("") }()
+
+	var temps []string
+	for _, profile := range []string{"Default", "Profile 1"} {
+		dir, err := CreateProfileDir("chrome", profile)
+		require.NoError(t, err)
+		assert.Equal(t, base, filepath.Dir(dir))
+		assert.True(t, strings.HasPrefix(filepath.Base(dir), "chrome-"+profile+"-"))
+		temps = append(temps, ChromiumCookie.TempFilename())
+		require.NoError(t, os.WriteFile(ChromiumCookie.TempFilename(), []byte(profile), 0o600))
+		assert.NoError(t, RemoveProfileDir())
+		assert.NoDirExists(t, dir)
+	}
+	// the profiles don't share the temp file of the item
+	assert.NotEqual(t, temps[0], temps[1])
+	assert.Equal(t, filepath.Join(base, "Cookies_2.temp"), ChromiumCookie.TempFilename())
+	assert.NoError(t, RemoveProfileDir())
+
+	dir, err := CreateProfileDir("firefox", "abc/def")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(filepath.Base(dir), "firefox-abc_def-"))
+	assert.NoError(t, RemoveProfileDir())
+}
+
 func TestDataType_IsSensitive(t *testing.T) {
 	asserts := assert.New(t)
 	testCases := []struct {
@@ -101,10 +165,58 @@ func (i DataType) filename() string {
 		return fileChromiumExtension
 	case ChromiumHistory:
 		return fileChromiumHistory
+	case ChromiumSessions:
+		return fileChromiumSessions
+	case ChromiumSiteEngagement:
+		return fileChromiumPreferences
+	case ChromiumPushSubscription:
+		return fileChromiumGCMStore
+	case ChromiumStorageQuota:
+		return fileChromiumQuotaManager
+	case ChromiumMostVisited:
+		return fileChromiumHistory
+	case ChromiumPrivacySandbox:
+		return fileChromiumConversions
+	case ChromiumWebApp:
+		return fileChromiumPreferences
+	case ChromiumSyncData:
+		return fileChromiumSyncData
+	case ChromiumExtensionCookie:
+		return fileChromiumExtensionCookie
+	case ChromiumReadingList:
+		return fileChromiumSyncData
+	case ChromiumNetworkState:
+		return fileChromiumNetworkState
+	case ChromiumTransportSecurity:
+		return fileChromiumTransportSecurity
+	case ChromiumArchivedHistory:
+		return fileChromiumArchivedHistory
+	case ChromiumSafeBrowsing:
+		return fileChromiumHistory
+	case ChromiumSecurePreferences:
+		return fileChromiumSecurePreferences
+	case ChromiumSettings:
+		return fileChromiumPreferences
+	case ChromiumAffiliation:
+		return fileChromiumAffiliation
 	case YandexPassword:
 		return fileYandexPassword
 	case YandexCreditCard:
 		return fileYandexCredit
+	case BraveRewards:
+		return fileChromiumPreferences
+	case FirefoxContainer, FirefoxCookieContainer:
+		return fileFirefoxContainers
+	case FirefoxWebApp:
+		return UnsupportedItem
+	case FirefoxSyncData:
+		return UnsupportedItem
+	case FirefoxNetworkState:
+		return fileFirefoxNetworkState
+	case FirefoxPasswordBackup:
+		return fileFirefoxPasswordBackup
+	case FirefoxInputHistory:
+		return fileFirefoxData
 	case FirefoxKey4:
 		return fileFirefoxKey4
 	case FirefoxPassword:
@@ -127,3 +239,13 @@ func (i DataType) filename() string {
 		return UnsupportedItem
 	}
 }
+
+func TestSortedKeys(t *testing.T) {
+	m := map[DataType]string{
+		FirefoxCookie:    "",
+		ChromiumPassword: "",
+		ChromiumKey:      "",
+		YandexPassword:   "",
+	}
+	assert.Equal(t, []DataType{ChromiumKey, ChromiumPassword, YandexPassword, FirefoxCookie}, SortedKeys(m))
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package types
+
+import "syscall"
+
+// isDirWritable reports whether files can be created in dir, it's false on a read-only mount
+func isDirWritable(dir string) bool {
+	const wOK = 0x2
+	return syscall.Access(dir, wOK) == nil
+}
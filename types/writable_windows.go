@@ -0,0 +1,12 @@
+//go:build windows
+
+package types
+
+import "os"
+
+// isDirWritable reports whether files can be created in dir, the read-only attribute of the
+// folder is ignored by windows, only a missing folder isn't writable.
+func isDirWritable(dir string) bool {
+	info, err := os.Stat(dir)
+	return err == nil && info.IsDir()
+}
@@ -5,8 +5,10 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	cp "github.com/otiai10/copy"
 )
@@ -63,12 +65,27 @@ func CopyFile(src, dst string) error {
 	return nil
 }
 
+// CopyWAL copies the write-ahead log of a sqlite database, nothing is copied when the
+// database has no wal, eg: it's not in wal mode or the browser checkpointed it on exit.
+func CopyWAL(src, dst string) error {
+	if !IsFileExists(src) {
+		return nil
+	}
+	return CopyFile(src, dst)
+}
+
 // Filename returns the filename from the provided path
 func Filename(browser, dataType, ext string) string {
 	replace := strings.NewReplacer(" ", "_", ".", "_", "-", "_")
 	return strings.ToLower(fmt.Sprintf("%s_%s.%s", replace.Replace(browser), dataType, ext))
 }
 
+// ProfileDir returns the output folder of the browser profile, eg: results/chrome/profile_1
+func ProfileDir(dir, browser, profile string) string {
+	replace := strings.NewReplacer(" ", "_", ".", "_", "-", "_")
+	return filepath.Join(dir, strings.ToLower(replace.Replace(browser)), strings.ToLower(replace.Replace(profile)))
+}
+
 func BrowserName(browser, user string) string {
 	replace := strings.NewReplacer(" ", "_", ".", "_", "-", "_", "Profile", "user")
 	return strings.ToLower(fmt.Sprintf("%s_%s", replace.Replace(browser), replace.Replace(user)))
@@ -107,7 +124,7 @@ func CompressDir(dir string) error {
 	}()
 
 	for _, file := range files {
-		if err := addFileToZip(zipWriter, filepath.Join(dir, file.Name())); err != nil {
+		if err := addToZip(zipWriter, filepath.Join(dir, file.Name()), file.Name()); err != nil {
 			return fmt.Errorf("failed to add file to zip: %w", err)
 		}
 	}
@@ -120,13 +137,35 @@ func CompressDir(dir string) error {
 	return writeFile(buffer, zipFilename)
 }
 
-func addFileToZip(zw *zip.Writer, filename string) error {
+// addToZip adds the file or the files of the folder to the zip with the entry name,
+// the folders are kept as the path of the entries, eg: chrome/profile_1/cookie.csv
+func addToZip(zw *zip.Writer, filename, name string) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return fmt.Errorf("error reading file %s: %w", filename, err)
+	}
+	if !info.IsDir() {
+		return addFileToZip(zw, filename, name)
+	}
+	files, err := os.ReadDir(filename)
+	if err != nil {
+		return fmt.Errorf("error reading dir %s: %w", filename, err)
+	}
+	for _, file := range files {
+		if err := addToZip(zw, filepath.Join(filename, file.Name()), path.Join(name, file.Name())); err != nil {
+			return err
+		}
+	}
+	return os.Remove(filename)
+}
+
+func addFileToZip(zw *zip.Writer, filename, name string) error {
 	content, err := os.ReadFile(filename)
 	if err != nil {
 		return fmt.Errorf("error reading file %s: %w", filename, err)
 	}
 
-	fw, err := zw.Create(filepath.Base(filename))
+	fw, err := zw.Create(name)
 	if err != nil {
 		return fmt.Errorf("error creating zip entry for %s: %w", filename, err)
 	}
@@ -157,3 +196,48 @@ func writeFile(buffer *bytes.Buffer, filename string) error {
 
 	return nil
 }
+
+// copyRetries is the number of times a copy is retried when verify fails
+const copyRetries = 3
+
+var copyRetryDelay = 100 * time.Millisecond
+
+// CopyFileVerified copies the file from the source to the destination and checks the copy,
+// it's retried when the source is changed during the copy or verify fails on the content,
+// eg: the browser is writing the database being copied.
+func CopyFileVerified(src, dst string, verify func(data []byte) error) error {
+	var err error
+	for i := 0; i < copyRetries; i++ {
+		if i > 0 {
+			time.Sleep(copyRetryDelay)
+		}
+		if err = copyFileVerified(src, dst, verify); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("verify copy of %s failed after %d attempts: %w", src, copyRetries, err)
+}
+
+func copyFileVerified(src, dst string, verify func(data []byte) error) error {
+	before, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	after, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) != before.Size() || after.Size() != before.Size() || !after.ModTime().Equal(before.ModTime()) {
+		return fmt.Errorf("source changed during copy, copied %d of %d bytes", len(data), after.Size())
+	}
+	if verify != nil {
+		if err := verify(data); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(dst, data, 0o600)
+}
@@ -1,9 +1,12 @@
 package fileutil
 
 import (
+	"archive/zip"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -36,6 +39,26 @@ func TestCompressDir(t *testing.T) {
 		assert.FileExists(t, zipFile, "zip file should be created")
 	})
 
+	t.Run("Profile Folders", func(t *testing.T) {
+		tempDir := t.TempDir()
+		for _, profile := range []string{"profile_1", "profile_2"} {
+			dir := filepath.Join(tempDir, "chrome", profile)
+			require.NoError(t, os.MkdirAll(dir, 0o750))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "cookie.csv"), []byte(profile), 0o600))
+		}
+
+		require.NoError(t, CompressDir(tempDir))
+		zr, err := zip.OpenReader(filepath.Join(tempDir, filepath.Base(tempDir)+".zip"))
+		require.NoError(t, err)
+		defer zr.Close()
+		var names []string
+		for _, f := range zr.File {
+			names = append(names, f.Name)
+		}
+		assert.Equal(t, []string{"chrome/profile_1/cookie.csv", "chrome/profile_2/cookie.csv"}, names)
+		assert.NoDirExists(t, filepath.Join(tempDir, "chrome"))
+	})
+
 	t.Run("Directory Does Not Exist", func(t *testing.T) {
 		err := CompressDir("/path/to/nonexistent/directory")
 		assert.Error(t, err, "should return an error for non-existent directory")
@@ -50,3 +73,32 @@ func TestCompressDir(t *testing.T) {
 		assert.Error(t, err, "should return an error for an empty directory")
 	})
 }
+
+func TestCopyFileVerified(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "Bookmarks")
+	dst := filepath.Join(dir, "Bookmarks.temp")
+	require.NoError(t, os.WriteFile(src, []byte(`{"roots":{}}`), 0o600))
+
+	defer func(d time.Duration) { copyRetryDelay = d }(copyRetryDelay)
+	copyRetryDelay = 0
+	attempts := 0
+	err := CopyFileVerified(src, dst, func(data []byte) error {
+		attempts++
+		if attempts == 1 {
+			return errors.New("being written")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.FileExists(t, dst)
+
+	errCorrupted := errors.New("corrupted")
+	err = CopyFileVerified(src, filepath.Join(dir, "other.temp"), func([]byte) error { return errCorrupted })
+	assert.ErrorIs(t, err, errCorrupted)
+	assert.NoFileExists(t, filepath.Join(dir, "other.temp"))
+
+	err = CopyFileVerified(filepath.Join(dir, "missing"), dst, nil)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,30 @@
+package sqliteutil
+
+import (
+	"database/sql"
+)
+
+const (
+	queryColumnExists = `SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`
+	queryTableExists  = `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`
+)
+
+// ColumnExists reports whether the table has the column, it's used to support
+// columns which are only present in newer schemas of the browser databases.
+func ColumnExists(db *sql.DB, table, column string) (bool, error) {
+	var count int
+	if err := db.QueryRow(queryColumnExists, table, column).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// TableExists reports whether the database has the table, it's used to support
+// databases whose tables were renamed between versions of the browser.
+func TableExists(db *sql.DB, table string) (bool, error) {
+	var count int
+	if err := db.QueryRow(queryTableExists, table).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
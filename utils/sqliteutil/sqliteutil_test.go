@@ -0,0 +1,46 @@
+package sqliteutil
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite" // import sqlite3 driver
+)
+
+func TestColumnExists(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE cookies (name TEXT, top_frame_site_key TEXT)`)
+	require.NoError(t, err)
+
+	ok, err := ColumnExists(db, "cookies", "top_frame_site_key")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = ColumnExists(db, "cookies", "source_scheme")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = ColumnExists(db, "missing", "name")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestTableExists(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE buckets (id INTEGER)`)
+	require.NoError(t, err)
+
+	ok, err := TableExists(db, "buckets")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = TableExists(db, "OriginInfoTable")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,30 @@
+// Package testutil is the helpers shared by the tests of the extractors
+package testutil
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	// import sqlite3 driver
+	_ "modernc.org/sqlite"
+
+	"github.com/moond4rk/hackbrowserdata/types"
+)
+
+// CreateSQLite creates the temp file of the sqlite item from the statements, the temp dir is
+// pointed at t.TempDir() for the test, so the tests don't share the files of os.TempDir().
+func CreateSQLite(t *testing.T, item types.DataType, stmts ...string) {
+	t.Helper()
+	old := types.TempDir()
+	require.NoError(t, types.SetTempDir(t.TempDir()))
+	t.Cleanup(func() { _ = types.SetTempDir(old) })
+
+	db, err := sql.Open("sqlite", item.TempFilename())
+	require.NoError(t, err)
+	defer db.Close()
+	for _, stmt := range stmts {
+		_, err = db.Exec(stmt)
+		require.NoError(t, err)
+	}
+}
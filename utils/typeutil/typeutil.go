@@ -56,3 +56,8 @@ func TimeEpoch(epoch int64) time.Time {
 	}
 	return t
 }
+
+// EpochFromTime returns the microseconds since 1601-01-01 of t, it's the reverse of TimeEpoch
+func EpochFromTime(t time.Time) int64 {
+	return t.UnixMicro() - time.Date(1601, 1, 1, 0, 0, 0, 0, time.Local).UnixMicro()
+}
@@ -22,3 +22,12 @@ func TestReverse(t *testing.T) {
 		}
 	}
 }
+
+func TestEpochFromTime(t *testing.T) {
+	t.Parallel()
+
+	const epoch = int64(13300000000000000)
+	if got := EpochFromTime(TimeEpoch(epoch)); got != epoch {
+		t.Errorf("epoch from time failed %d != %d", got, epoch)
+	}
+}